@@ -1,14 +1,40 @@
 package main
 
 import (
+	"Discrepancies/internal/apperr"
+	"Discrepancies/internal/bindiff"
 	"Discrepancies/internal/compare"
 	"Discrepancies/internal/config"
+	"Discrepancies/internal/difftool"
+	"Discrepancies/internal/encrypt"
 	"Discrepancies/internal/models"
+	"Discrepancies/internal/platform"
+	"Discrepancies/internal/remote"
+	"Discrepancies/internal/report"
+	"Discrepancies/internal/review"
+	"Discrepancies/internal/signing"
+	"Discrepancies/internal/store"
+	"Discrepancies/internal/tempfile"
+	"Discrepancies/internal/tmpl"
+	"Discrepancies/internal/tracker"
+	"Discrepancies/internal/vcs"
+	"archive/zip"
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/wailsapp/wails/v2/pkg/logger"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
@@ -16,16 +42,35 @@ import (
 type App struct {
 	ctx       context.Context
 	configMgr *config.Manager
+
+	mu           sync.Mutex
+	zipEncodings map[string]string // 按 ZIP 路径记录的文件名编码覆盖（仅本次运行）
+	results      *store.Results
+	launch       models.OpenRequest // 启动时通过命令行传入、尚未被前端获取的路径
+	lastResult   *models.CompareResult
+	lastMeta     report.Meta
+	lastBaseline string             // 最近一次比较的基准 ZIP 或快照文件路径，git 基准时为空
+	tickets      []models.TicketRef // 关联到比较的工单，重新比较后保留，直到再次修改
+	diffCache    *compare.DiffCache // 本次运行中计算过的差异预览
+	temp         *tempfile.Manager  // 预览、导出等操作创建的临时文件
+	reviews      review.Set         // 最近一次比较的审阅记录
+	reviewPath   string             // 审阅记录文件路径，为空时不保存
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	return &App{
+		zipEncodings: make(map[string]string),
+		results:      store.NewResults(store.DefaultCapacity),
+		diffCache:    compare.NewDiffCache(compare.DefaultDiffCacheSize),
+	}
 }
 
 // startup is called when the app starts
 func (a *App) startup(ctx context.Context) {
+	a.mu.Lock()
 	a.ctx = ctx
+	a.mu.Unlock()
 
 	// 初始化配置管理器
 	var err error
@@ -33,15 +78,101 @@ func (a *App) startup(ctx context.Context) {
 	if err != nil {
 		runtime.LogError(ctx, fmt.Sprintf("Failed to initialize config manager: %v", err))
 	}
+	// 配置文件可能开启只读模式，创建配置管理器之后再判断
+	a.results.SetSpill(!config.ReadOnly())
+	a.applyLogLevel()
+
+	if dir, err := config.TempDir(); err == nil {
+		a.temp = tempfile.NewManager(dir)
+		a.results.SetTempManager(a.temp)
+		if !config.ReadOnly() {
+			// 清理上次异常退出时遗留的临时文件
+			if _, err := a.temp.Cleanup(); err != nil {
+				runtime.LogWarning(ctx, fmt.Sprintf("clean up temp files failed: %v", err))
+			}
+		}
+	}
+
+	if a.configMgr != nil {
+		go a.watchSharedRules(ctx)
+		go a.watchUpdates(ctx)
+	}
+}
+
+// watchSharedRules 按配置的间隔刷新团队共享规则，直到应用退出
+func (a *App) watchSharedRules(ctx context.Context) {
+	for {
+		if err := a.configMgr.RefreshSharedRules(ctx); err != nil {
+			runtime.LogWarning(ctx, fmt.Sprintf("refresh shared rules failed: %v", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.configMgr.SharedRulesInterval()):
+		}
+	}
+}
+
+// applyLogLevel 按配置设置日志级别，开启调试跟踪时使用 trace 级别
+func (a *App) applyLogLevel() {
+	level := parseLogLevel("")
+	if a.configMgr != nil {
+		cfg := a.configMgr.Get()
+		level = parseLogLevel(cfg.LogLevel)
+		if cfg.DebugTrace {
+			level = logger.TRACE
+		}
+	}
+	runtime.LogSetLogLevel(a.ctx, level)
+}
+
+// GetRecentPaths 返回最近使用的 ZIP 文件、工作目录和输出目录（各最多 10 个，最近的在前）
+func (a *App) GetRecentPaths() models.RecentPaths {
+	if a.configMgr == nil {
+		return models.RecentPaths{ZipPaths: []string{}, WorkDirs: []string{}, OutputDirs: []string{}}
+	}
+	return a.configMgr.RecentPaths()
+}
+
+// GetLogFilePath 返回日志文件路径，便于用户在反馈问题时附上日志
+func (a *App) GetLogFilePath() (string, error) {
+	return config.LogFilePath()
+}
+
+// IsReadOnly 是否处于只读模式（命令行 --read-only 或配置文件中的 readOnly），前端据此禁用导出等写入操作
+func (a *App) IsReadOnly() bool {
+	return config.ReadOnly()
+}
+
+// shutdown is called when the app is closing
+func (a *App) shutdown(ctx context.Context) {
+	a.results.Close()
+	if err := a.temp.Close(); err != nil {
+		runtime.LogWarning(ctx, fmt.Sprintf("clean up temp files failed: %v", err))
+	}
+}
+
+// GetTempUsage 返回临时文件目录中的文件数、总大小和使用中的临时文件数
+func (a *App) GetTempUsage() (models.TempUsage, error) {
+	usage, err := a.temp.Usage()
+	return usage, appError(err)
+}
+
+// CleanupTemp 删除不在使用中的临时文件（如异常退出时遗留的），返回删除的文件数和大小
+func (a *App) CleanupTemp() (models.TempUsage, error) {
+	if config.ReadOnly() {
+		return models.TempUsage{}, apperr.ErrReadOnly
+	}
+	freed, err := a.temp.Cleanup()
+	return freed, appError(err)
 }
 
 // SelectZipFile 打开文件选择对话框选择 ZIP 文件
 func (a *App) SelectZipFile() (string, error) {
 	defaultDir := ""
 	if a.configMgr != nil {
-		cfg := a.configMgr.Get()
-		if cfg.LastZipPath != "" {
-			defaultDir = filepath.Dir(cfg.LastZipPath)
+		if recent := a.configMgr.RecentPaths().ZipPaths; len(recent) > 0 {
+			defaultDir = filepath.Dir(recent[0])
 		}
 	}
 
@@ -53,6 +184,10 @@ func (a *App) SelectZipFile() (string, error) {
 				DisplayName: "ZIP 文件 (*.zip)",
 				Pattern:     "*.zip",
 			},
+			{
+				DisplayName: "应用包 (*.apk, *.ipa, *.vsix)",
+				Pattern:     "*.apk;*.ipa;*.vsix",
+			},
 		},
 	})
 
@@ -61,7 +196,7 @@ func (a *App) SelectZipFile() (string, error) {
 	}
 
 	if path != "" && a.configMgr != nil {
-		a.configMgr.SetLastZipPath(path)
+		a.configMgr.AddRecentZipPath(path)
 	}
 
 	return path, nil
@@ -71,9 +206,8 @@ func (a *App) SelectZipFile() (string, error) {
 func (a *App) SelectWorkDir() (string, error) {
 	defaultDir := ""
 	if a.configMgr != nil {
-		cfg := a.configMgr.Get()
-		if cfg.LastWorkDir != "" {
-			defaultDir = cfg.LastWorkDir
+		if recent := a.configMgr.RecentPaths().WorkDirs; len(recent) > 0 {
+			defaultDir = recent[0]
 		}
 	}
 
@@ -87,7 +221,7 @@ func (a *App) SelectWorkDir() (string, error) {
 	}
 
 	if path != "" && a.configMgr != nil {
-		a.configMgr.SetLastWorkDir(path)
+		a.configMgr.AddRecentWorkDir(path)
 	}
 
 	return path, nil
@@ -110,177 +244,1547 @@ func (a *App) SelectOutputDir() (string, error) {
 	}
 
 	if path != "" && a.configMgr != nil {
-		a.configMgr.SetLastOutputDir(path)
+		a.configMgr.AddRecentOutputDir(path)
 	}
 
 	return path, nil
 }
 
 // Compare 比较 ZIP 文件和工作目录
-func (a *App) Compare(zipPath, workDir string) (*models.CompareResult, error) {
+// sessionRules 为仅本次比较生效的临时规则，追加在已保存规则之后（优先级更高），不会写入配置
+// nameEncoding 为该 ZIP 的文件名编码（auto/shift-jis/gbk/cp437/utf-8），为空时使用配置；
+// 设置后同一 ZIP 的预览等后续操作也使用该编码
+// subPath 不为空时只比较该子目录（相对于 ZIP 根目录和工作目录），结果中的路径仍相对于根目录
+// 保留供旧版前端使用，新增选项请使用 CompareWithOptions
+func (a *App) Compare(zipPath, workDir string, sessionRules []models.ExcludeRule, nameEncoding, subPath string) (*models.CompareResult, error) {
+	return a.CompareWithOptions(models.CompareOptions{
+		ZipPath:      zipPath,
+		WorkDir:      workDir,
+		SessionRules: sessionRules,
+		NameEncoding: nameEncoding,
+		SubPath:      subPath,
+	})
+}
+
+// CompareWithOptions 按选项比较 ZIP 文件和工作目录，各选项的含义见 models.CompareOptions
+func (a *App) CompareWithOptions(opts models.CompareOptions) (*models.CompareResult, error) {
+	zipPath, workDir := opts.ZipPath, opts.WorkDir
 	if zipPath == "" {
-		return nil, fmt.Errorf("请选择 ZIP 文件")
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择 ZIP 文件")
 	}
 	if workDir == "" {
-		return nil, fmt.Errorf("请选择工作目录")
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择工作目录")
 	}
 
 	// 检查文件和目录是否存在
 	if _, err := os.Stat(zipPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("ZIP 文件不存在: %s", zipPath)
+		return nil, apperr.ErrZipNotFound.WithDetail(zipPath)
 	}
 	if _, err := os.Stat(workDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("工作目录不存在: %s", workDir)
+		return nil, apperr.ErrWorkDirMissing.WithDetail(workDir)
+	}
+	scope, err := compare.CleanSubPath(opts.SubPath)
+	if err != nil {
+		return nil, apperr.ErrInvalidArgument.WithMessage("子目录必须是工作目录下的相对路径").WithDetail(opts.SubPath)
 	}
+	if scope != "" {
+		scopeDir := filepath.Join(workDir, filepath.FromSlash(scope))
+		if info, err := os.Stat(scopeDir); err != nil || !info.IsDir() {
+			return nil, apperr.ErrWorkDirMissing.WithDetail(scopeDir)
+		}
+	}
+	a.setZipEncoding(zipPath, opts.NameEncoding)
 
-	comparer := compare.NewComparer(zipPath, workDir)
+	start := time.Now()
+	comparer := a.newComparer(zipPath, workDir, opts.SessionRules)
+	if opts.ExcludeRules != nil {
+		comparer.SetExcludeRules(append(append([]models.ExcludeRule{}, opts.ExcludeRules...), opts.SessionRules...))
+	}
+	if err := comparer.SetHashAlgorithm(opts.HashAlgorithm); err != nil {
+		return nil, appError(err)
+	}
+	comparer.SetFastMode(opts.FastMode)
+	comparer.SetConcurrency(opts.Concurrency)
+	comparer.SetCaseInsensitive(opts.CaseInsensitive)
+	comparer.SetSubPath(scope)
+	if checkpointPath, err := config.CheckpointFilePath(); err == nil && !config.ReadOnly() {
+		comparer.SetCheckpoint(checkpointPath, compare.DefaultCheckpointInterval)
+	}
+	result, err := comparer.Compare()
+	if err != nil {
+		return nil, appError(err)
+	}
+	a.recordThroughput(comparer.Stats(), start)
+
+	// 标记 SVN 中未纳入版本控制的文件（失败时不影响比较结果）
+	if a.configMgr != nil && a.configMgr.Get().EnableSvnStatus && vcs.IsSvnWorkingCopy(workDir) {
+		if unversioned, err := vcs.SvnUnversioned(workDir); err == nil {
+			compare.MarkUnversioned(result, unversioned.Contains)
+		} else {
+			runtime.LogWarning(a.ctx, fmt.Sprintf("svn status failed: %v", err))
+		}
+	}
 
-	// 设置排除规则
 	if a.configMgr != nil {
-		comparer.SetExcludeRules(a.configMgr.GetExcludeRules())
+		a.configMgr.AddRecentZipPath(zipPath)
+		a.configMgr.AddRecentWorkDir(workDir)
 	}
 
-	// 设置进度回调
-	comparer.OnProgress = func(current, total int, message string) {
-		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
-			Current: current,
-			Total:   total,
-			Message: message,
-		})
+	a.loadReviews(zipPath, workDir, result)
+	meta := report.Meta{Baseline: filepath.Base(zipPath), WorkDir: workDir}
+	a.setLastResult(result, meta, zipPath)
+	a.emitCompareComplete("zip", meta, result, comparer.Stats(), start)
+	return result, nil
+}
+
+// EstimateCompare 比较前快速统计两侧参与比较的文件数和总大小（遵循排除规则，不读取文件内容），并按历史吞吐量预计比较耗时
+func (a *App) EstimateCompare(zipPath, workDir string) (*models.CompareEstimate, error) {
+	if zipPath == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择 ZIP 文件")
+	}
+	if workDir == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择工作目录")
+	}
+	if _, err := os.Stat(zipPath); os.IsNotExist(err) {
+		return nil, apperr.ErrZipNotFound.WithDetail(zipPath)
+	}
+	if _, err := os.Stat(workDir); os.IsNotExist(err) {
+		return nil, apperr.ErrWorkDirMissing.WithDetail(workDir)
 	}
 
-	result, err := comparer.Compare()
+	estimate, err := a.newComparer(zipPath, workDir, nil).Estimate()
 	if err != nil {
-		return nil, err
+		return nil, appError(err)
 	}
+	samples := config.LoadThroughput()
+	estimate.EstimatedMs = compare.PredictDuration(estimate, samples).Milliseconds()
+	estimate.Samples = len(samples)
+	return &estimate, nil
+}
 
-	return result, nil
+// recordThroughput 记录一次完整比较（未从检查点恢复）的吞吐量，供 EstimateCompare 预计耗时
+func (a *App) recordThroughput(stats models.CompareStats, start time.Time) {
+	if stats.ResumedFiles > 0 || stats.FilesScanned == 0 {
+		return
+	}
+	sample := models.ThroughputSample{
+		Files:      stats.FilesScanned,
+		Bytes:      stats.BytesHashed,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err := config.RecordThroughput(sample); err != nil {
+		runtime.LogWarning(a.ctx, fmt.Sprintf("record throughput failed: %v", err))
+	}
 }
 
-// GetTextDiff 获取文件的文本差异
-func (a *App) GetTextDiff(zipPath, workDir, relPath string) (*models.TextDiff, error) {
-	// 检查是否是文本文件
-	if !compare.IsTextFile(relPath) {
-		return nil, fmt.Errorf("不支持预览非文本文件")
+// FindBaselineTag 在工作目录的 git 仓库中查找与 ZIP 基准对应的标签，找不到时返回空字符串
+func (a *App) FindBaselineTag(zipPath, workDir string) (string, error) {
+	if !vcs.IsGitRepo(workDir) {
+		return "", nil
 	}
 
-	// 打开 ZIP 文件
-	zipReader, err := compare.NewZipReader(zipPath)
+	names := []string{strings.TrimSuffix(filepath.Base(zipPath), filepath.Ext(zipPath))}
+	if zipReader, err := a.openZip(zipPath); err == nil {
+		if root := zipReader.GetRootFolder(); root != "" {
+			names = append([]string{root}, names...)
+		}
+		zipReader.Close()
+	}
+
+	for _, name := range names {
+		tag, err := vcs.FindTag(workDir, name)
+		if err != nil {
+			return "", err
+		}
+		if tag != "" {
+			return tag, nil
+		}
+	}
+	return "", nil
+}
+
+// CompareGit 以 git 引用（标签、分支或提交）为基准比较工作目录，不需要 ZIP 文件
+func (a *App) CompareGit(workDir, ref string, sessionRules []models.ExcludeRule) (*models.CompareResult, error) {
+	if workDir == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择工作目录")
+	}
+	if ref == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("请指定基准标签")
+	}
+	if !vcs.IsGitRepo(workDir) {
+		return nil, apperr.ErrNotGitRepo.WithDetail(workDir)
+	}
+
+	start := time.Now()
+	comparer := a.newComparer("", workDir, sessionRules)
+	result, err := comparer.CompareGit(ref)
 	if err != nil {
-		return nil, err
+		return nil, apperr.ErrVcsFailed.Wrap(err)
 	}
-	defer zipReader.Close()
 
-	// 获取工作目录中的文件路径
-	workFilePath := filepath.Join(workDir, relPath)
+	if a.configMgr != nil {
+		a.configMgr.AddRecentWorkDir(workDir)
+	}
 
-	// 比较文件
-	differ := compare.NewTextDiffer()
-	return differ.CompareFiles(zipReader, relPath, workFilePath)
+	a.loadReviews("git:"+ref, workDir, result)
+	meta := report.Meta{Baseline: ref, WorkDir: workDir}
+	a.setLastResult(result, meta, "")
+	a.emitCompareComplete("git", meta, result, comparer.Stats(), start)
+	return result, nil
 }
 
-// ExportDiffs 导出差异文件
-func (a *App) ExportDiffs(items []models.DiffItem, outputDir string) error {
-	if outputDir == "" {
-		return fmt.Errorf("请选择输出目录")
+// emitCompareComplete 发送比较完成事件（backend:compareComplete）
+func (a *App) emitCompareComplete(engine string, meta report.Meta, result *models.CompareResult, stats models.CompareStats, start time.Time) {
+	elapsed := time.Since(start)
+	event := models.CompareCompleteEvent{
+		Engine:     engine,
+		Baseline:   meta.Baseline,
+		WorkDir:    meta.WorkDir,
+		StartedAt:  start.Format(time.RFC3339),
+		DurationMs: elapsed.Milliseconds(),
+		Total:      result.TotalFiles,
+		Added:      result.Added,
+		Modified:   result.Modified,
+		Deleted:    result.Deleted,
+		Renamed:    result.Renamed,
+		Stats:      stats,
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		event.FilesPerSecond = float64(stats.FilesScanned) / seconds
+		event.BytesPerSecond = float64(stats.BytesHashed) / seconds
 	}
 
-	return compare.ExportDiffs(items, outputDir, func(current, total int, message string) {
-		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
-			Current: current,
-			Total:   total,
-			Message: message,
-		})
-	})
+	runtime.EventsEmit(a.ctx, "backend:compareComplete", event)
 }
 
-// ExportToZip 直接将选中的差异文件导出为 ZIP
-func (a *App) ExportToZip(items []models.DiffItem, outputDir, baseName string) (string, error) {
+// ExportPrintReport 生成可打印的 HTML 变更记录（列出选中的差异项），返回报告文件路径
+func (a *App) ExportPrintReport(items []models.DiffItem, outputDir, baseName string) (string, error) {
+	if config.ReadOnly() {
+		return "", apperr.ErrReadOnly
+	}
 	if outputDir == "" {
-		return "", fmt.Errorf("请选择输出目录")
+		return "", apperr.ErrInvalidArgument.WithMessage("请选择输出目录")
 	}
 
-	zipName := compare.GenerateZipName(baseName)
-	zipPath := filepath.Join(outputDir, zipName)
+	a.mu.Lock()
+	meta := a.lastMeta
+	a.mu.Unlock()
+	if meta.Baseline == "" {
+		meta.Baseline = baseName
+	}
 
-	err := compare.ExportDiffsToZip(items, zipPath, func(current, total int, message string) {
-		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
-			Current: current,
-			Total:   total,
-			Message: message,
-		})
-	})
+	var cfg models.Config
+	if a.configMgr != nil {
+		cfg = a.configMgr.Get()
+	}
+	title, err := renderTitle(cfg.ReportTitleTemplate, meta)
+	if err != nil {
+		return "", err
+	}
+	meta.Title = title
+	_, vars, err := a.exportNaming(outputDir, baseName)
+	if err != nil {
+		return "", err
+	}
+	name, err := compare.RenderName(cmp.Or(strings.TrimSpace(cfg.ReportNameTemplate), report.DefaultPrintNameTemplate), vars)
+	if err != nil {
+		return "", appError(err)
+	}
 
+	html, err := report.RenderPrintHTML(items, meta)
 	if err != nil {
 		return "", err
 	}
 
-	return zipPath, nil
+	reportPath := filepath.Join(outputDir, name+".html")
+	if err := os.WriteFile(reportPath, html, 0644); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return reportPath, nil
 }
 
-// GetConfig 获取配置
-func (a *App) GetConfig() models.Config {
-	if a.configMgr == nil {
-		return models.Config{}
+// renderTitle 渲染报告标题模板，模板为空时返回空字符串（使用报告的默认标题）
+func renderTitle(text string, meta report.Meta) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", nil
 	}
-	return a.configMgr.Get()
+	title, err := tmpl.Render(text, tmpl.Context{Vars: map[string]string{
+		"baseline": meta.Baseline,
+		"workDir":  meta.WorkDir,
+		"tickets":  strings.Join(ticketIDs(meta.Tickets), ", "),
+	}})
+	if err != nil {
+		return "", apperr.ErrInvalidArgument.WithMessage("报告标题模板无效").Wrap(err)
+	}
+	return title, nil
 }
 
-// SaveConfig 保存配置
-func (a *App) SaveConfig(cfg models.Config) error {
-	if a.configMgr == nil {
-		return fmt.Errorf("配置管理器未初始化")
+// setLastResult 记录最近一次比较结果，供摘要、报告、分页获取等功能使用；baselinePath 为基准 ZIP 或快照文件的路径，git 基准时为空
+func (a *App) setLastResult(result *models.CompareResult, meta report.Meta, baselinePath string) {
+	if a.configMgr != nil {
+		a.results.SetMemoryLimit(a.configMgr.Get().ResultMemoryLimit)
 	}
-	return a.configMgr.Set(cfg)
+	a.results.Put(result)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	meta.Tickets = a.tickets
+	a.lastResult = result
+	a.lastMeta = meta
+	a.lastBaseline = baselinePath
 }
 
-// GetZipRootFolder 获取 ZIP 文件的根目录名称
-func (a *App) GetZipRootFolder(zipPath string) (string, error) {
-	zipReader, err := compare.NewZipReader(zipPath)
+// SetTickets 将工单号（如 PROJ-123、#4567，可用逗号分隔）关联到比较，用于报告、变更日志和导出文件名中的 {ticket}
+// 开启查询工单标题时通过问题跟踪系统的 REST API 查询，查询失败时仍关联工单号，只记录警告
+func (a *App) SetTickets(ids []string) ([]models.TicketRef, error) {
+	var cfg models.IssueTrackerConfig
+	if a.configMgr != nil {
+		cfg = a.configMgr.Get().IssueTracker
+	}
+	tickets, err := tracker.Resolve(a.ctx, cfg, ids)
 	if err != nil {
-		return "", err
+		runtime.LogWarning(a.ctx, fmt.Sprintf("resolve tickets: %v", err))
 	}
-	defer zipReader.Close()
 
-	return zipReader.GetRootFolder(), nil
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tickets = tickets
+	a.lastMeta.Tickets = tickets
+	return tickets, nil
 }
 
-// GetExcludeRules 获取排除规则
-func (a *App) GetExcludeRules() []models.ExcludeRule {
-	if a.configMgr == nil {
-		return []models.ExcludeRule{}
+// ticketIDs 返回关联工单的编号
+func ticketIDs(tickets []models.TicketRef) []string {
+	ids := make([]string, 0, len(tickets))
+	for _, t := range tickets {
+		ids = append(ids, t.ID)
 	}
-	return a.configMgr.GetExcludeRules()
+	return ids
 }
 
-// SetExcludeRules 设置排除规则
-func (a *App) SetExcludeRules(rules []models.ExcludeRule) error {
-	if a.configMgr == nil {
-		return fmt.Errorf("配置管理器未初始化")
+// GetResultPage 分页获取比较结果，供前端虚拟列表按需加载
+// filter 按差异类型和路径筛选；sortBy 为 path、type、lines，前缀 - 表示降序
+func (a *App) GetResultPage(resultID string, offset, limit int, filter models.ResultFilter, sortBy string) (models.ResultPage, error) {
+	if _, ok := a.results.Get(resultID); !ok {
+		return models.ResultPage{}, apperr.ErrNoResult.WithDetail(resultID)
+	}
+
+	page, err := a.results.Page(resultID, offset, limit, filter, sortBy)
+	if err != nil {
+		return models.ResultPage{}, apperr.ErrInvalidArgument.Wrap(err)
 	}
-	return a.configMgr.SetExcludeRules(rules)
+	a.applyReviews(resultID, page.Items)
+	return page, nil
 }
 
-// AddExcludeRule 添加排除规则
-func (a *App) AddExcludeRule(rule models.ExcludeRule) error {
-	if a.configMgr == nil {
-		return fmt.Errorf("配置管理器未初始化")
+// loadReviews 读取一组比较（基准和工作目录）保存的审阅记录并恢复到比较结果中，读取失败时只记录警告
+func (a *App) loadReviews(baseline, workDir string, result *models.CompareResult) {
+	reviews := review.Set{}
+	path, err := config.ReviewFilePath(baseline, workDir)
+	if err == nil {
+		if reviews, err = review.Load(path); err != nil {
+			reviews = review.Set{}
+		}
+	}
+	if err != nil {
+		runtime.LogWarning(a.ctx, fmt.Sprintf("load reviews failed: %v", err))
+		path = ""
 	}
-	return a.configMgr.AddExcludeRule(rule)
+	reviews.Apply(result.Items)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.reviews = reviews
+	a.reviewPath = path
 }
 
-// RemoveExcludeRule 删除排除规则
-func (a *App) RemoveExcludeRule(index int) error {
-	if a.configMgr == nil {
-		return fmt.Errorf("配置管理器未初始化")
+// applyReviews 将审阅记录恢复到最近一次比较结果的差异项中（写入临时文件的结果不会随审阅更新）
+func (a *App) applyReviews(resultID string, items []models.DiffItem) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.lastResult != nil && a.lastResult.ResultID == resultID {
+		a.reviews.Apply(items)
 	}
-	return a.configMgr.RemoveExcludeRule(index)
 }
 
-// ResetExcludeRules 重置为默认排除规则
-func (a *App) ResetExcludeRules() error {
+// SetReview 设置最近一次比较结果中差异项的审阅状态（pending、approved、rejected，空表示未审阅）和说明
+// 审阅记录按基准和工作目录保存，重新比较后恢复；审阅后文件又有变化的恢复为待审阅
+// 只记录到审阅记录中，不修改比较结果的差异项，读取结果时由 applyReviews 恢复
+func (a *App) SetReview(relPath, status, note string) error {
+	if config.ReadOnly() {
+		return apperr.ErrReadOnly.WithMessage("只读模式下不能保存审阅记录")
+	}
+	if err := review.ValidateStatus(status); err != nil {
+		return appError(err)
+	}
+
+	a.mu.Lock()
+	result := a.lastResult
+	a.mu.Unlock()
+	if result == nil {
+		return apperr.ErrNoResult
+	}
+	items := result.Items
+	if result.Spilled {
+		var err error
+		if items, err = a.results.Items(result.ResultID); err != nil {
+			return err
+		}
+	}
+	item, ok := findItem(items, relPath)
+	if !ok {
+		return apperr.ErrInvalidArgument.WithMessage("差异项不在最近一次比较结果中").WithDetail(relPath)
+	}
+
+	a.mu.Lock()
+	if a.lastResult != result {
+		a.mu.Unlock()
+		return apperr.ErrNoResult.WithDetail(relPath)
+	}
+	a.reviews.Record(*item, status, note)
+	reviews, path := maps.Clone(a.reviews), a.reviewPath
+	a.mu.Unlock()
+
+	if path == "" {
+		return nil
+	}
+	return reviews.Save(path)
+}
+
+// findItem 按相对路径查找差异项
+func findItem(items []models.DiffItem, relPath string) (*models.DiffItem, bool) {
+	for i := range items {
+		if items[i].RelPath == relPath {
+			return &items[i], true
+		}
+	}
+	return nil, false
+}
+
+// CopySummaryToClipboard 将最近一次比较结果的摘要复制到剪贴板
+// format 为 "text"（纯文本）或 "markdown"
+func (a *App) CopySummaryToClipboard(format string) error {
+	a.mu.Lock()
+	result, meta := a.lastResult, a.lastMeta
+	a.mu.Unlock()
+
+	if result == nil {
+		return apperr.ErrNoResult
+	}
+	result, err := a.fullResult(result)
+	if err != nil {
+		return err
+	}
+
+	if a.configMgr != nil {
+		title, err := renderTitle(a.configMgr.Get().SummaryTitleTemplate, meta)
+		if err != nil {
+			return err
+		}
+		meta.Title = title
+	}
+
+	text, err := report.RenderSummary(result, meta, format)
+	if err != nil {
+		return apperr.ErrInvalidArgument.Wrap(err)
+	}
+	return runtime.ClipboardSetText(a.ctx, text)
+}
+
+// fullResult 返回含全部差异项的比较结果：结果超过内存上限写入临时文件时从临时文件读取，差异项为副本并恢复审阅记录
+func (a *App) fullResult(result *models.CompareResult) (*models.CompareResult, error) {
+	items := slices.Clone(result.Items)
+	if result.Spilled {
+		var err error
+		if items, err = a.results.Items(result.ResultID); err != nil {
+			return nil, err
+		}
+	}
+	a.applyReviews(result.ResultID, items)
+	full := *result
+	full.Items = items
+	return &full, nil
+}
+
+// normalizer 按配置的规范化规则和内容忽略规则创建规范化器，比较和预览使用相同的规则
+func (a *App) normalizer() *compare.Normalizer {
 	if a.configMgr == nil {
-		return fmt.Errorf("配置管理器未初始化")
+		return nil
+	}
+	cfg := a.configMgr.Get()
+	rules := cfg.NormalizeRules
+	if cfg.IgnoreBOM {
+		rules = append([]models.NormalizeRule{compare.BOMNormalizeRule()}, rules...)
+	}
+	if cfg.IgnoreEncoding {
+		rules = append([]models.NormalizeRule{compare.EncodingNormalizeRule()}, rules...)
+	}
+	return compare.NewNormalizer(rules, cfg.ContentIgnoreRules)
+}
+
+// diffCacheOptions 返回影响差异预览结果的配置（规范化规则、内容忽略规则、忽略 BOM 和编码）的摘要
+func (a *App) diffCacheOptions() string {
+	cfg := a.configMgr.Get()
+	data, _ := json.Marshal([]any{cfg.NormalizeRules, cfg.ContentIgnoreRules, cfg.IgnoreBOM, cfg.IgnoreEncoding})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// newComparer 创建比较器并应用排除规则、.gitignore 设置和进度回调
+func (a *App) newComparer(zipPath, workDir string, sessionRules []models.ExcludeRule) *compare.Comparer {
+	comparer := compare.NewComparer(zipPath, workDir)
+
+	// 设置排除规则：本次会话的规则追加在配置的规则之后，没有配置时追加在默认规则之后
+	rules := config.DefaultExcludeRules()
+	if a.configMgr != nil {
+		rules = append([]models.ExcludeRule{}, a.configMgr.EffectiveExcludeRules()...)
+	}
+	comparer.SetExcludeRules(append(rules, sessionRules...))
+	if a.configMgr != nil {
+		comparer.SetRespectGitignore(a.configMgr.Get().RespectGitignore)
+		comparer.SetCompareStreams(a.configMgr.Get().CompareStreams)
+		comparer.SetNormalizer(a.normalizer())
+		keywordRules := a.configMgr.Get().KeywordRules
+		if err := compare.ValidateKeywordRules(keywordRules); err != nil {
+			runtime.LogWarning(a.ctx, fmt.Sprintf("skip keyword rule: %v", err))
+		}
+		if err := compare.ValidateContentIgnoreRules(a.configMgr.Get().ContentIgnoreRules); err != nil {
+			runtime.LogWarning(a.ctx, fmt.Sprintf("skip content ignore rule: %v", err))
+		}
+		if err := compare.ValidateNormalizeRules(a.configMgr.Get().NormalizeRules); err != nil {
+			runtime.LogWarning(a.ctx, fmt.Sprintf("skip normalize rule: %v", err))
+		}
+		comparer.SetKeywordScanner(compare.NewKeywordScanner(keywordRules))
+		comparer.SetLargestCount(a.configMgr.Get().LargestChanges)
+		comparer.SetReadRetry(a.configMgr.Get().ReadRetries, time.Duration(a.configMgr.Get().ReadRetryDelay)*time.Millisecond)
+		comparer.SetMaxDepth(a.configMgr.Get().MaxDepth)
+		comparer.SetListUnchanged(a.configMgr.Get().ListUnchanged)
+		comparer.SetIgnoreBOM(a.configMgr.Get().IgnoreBOM)
+		comparer.SetIgnoreEncoding(a.configMgr.Get().IgnoreEncoding)
+		comparer.SetDetectCommentsOnly(a.configMgr.Get().DetectCommentsOnly)
+	}
+	if zipPath != "" {
+		comparer.SetFilenameEncoding(a.zipEncoding(zipPath))
+	}
+	if a.configMgr != nil && a.configMgr.Get().DebugTrace {
+		comparer.OnTrace = func(message string) {
+			runtime.LogTrace(a.ctx, message)
+		}
+	}
+
+	// 设置进度回调
+	comparer.OnProgress = func(current, total int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Phase:   compare.PhaseCompare,
+			Current: current,
+			Total:   total,
+			Message: message,
+		})
+	}
+	comparer.OnHeartbeat = func(phase string, count int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Phase:   phase,
+			Current: count,
+			Message: message,
+		})
+	}
+
+	return comparer
+}
+
+// openZip 按该 ZIP 的文件名编码打开 ZIP 文件
+func (a *App) openZip(zipPath string) (*compare.ZipReader, error) {
+	zipReader, err := compare.NewZipReaderWithEncoding(zipPath, a.zipEncoding(zipPath))
+	if err != nil {
+		return nil, appError(err)
+	}
+	return zipReader, nil
+}
+
+// zipEncoding 返回 ZIP 的文件名编码：优先使用本次运行中的覆盖设置，其次是配置，默认自动识别
+func (a *App) zipEncoding(zipPath string) string {
+	a.mu.Lock()
+	enc, ok := a.zipEncodings[filepath.Clean(zipPath)]
+	a.mu.Unlock()
+	if ok {
+		return enc
+	}
+
+	if a.configMgr != nil && a.configMgr.Get().ZipNameEncoding != "" {
+		return a.configMgr.Get().ZipNameEncoding
+	}
+	return compare.FilenameEncodingAuto
+}
+
+// setZipEncoding 记录 ZIP 的文件名编码覆盖，空字符串表示清除覆盖
+func (a *App) setZipEncoding(zipPath, encoding string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := filepath.Clean(zipPath)
+	if encoding == "" {
+		delete(a.zipEncodings, key)
+	} else {
+		a.zipEncodings[key] = encoding
+	}
+}
+
+// GetZipNameEncodings 返回可选的 ZIP 文件名编码
+func (a *App) GetZipNameEncodings() []string {
+	return compare.FilenameEncodings()
+}
+
+// DetectZipNameEncoding 返回打开 ZIP 时实际使用的文件名编码（自动识别时为识别结果）
+func (a *App) DetectZipNameEncoding(zipPath string) (string, error) {
+	zipReader, err := a.openZip(zipPath)
+	if err != nil {
+		return "", err
+	}
+	defer zipReader.Close()
+
+	return zipReader.FilenameEncoding(), nil
+}
+
+// GetTextDiff 获取文件的文本差异
+// force 为 true 时跳过文件类型检查，按文本强制预览（由前端在用户确认后传入）
+func (a *App) GetTextDiff(zipPath, workDir, relPath string, force bool) (*models.TextDiff, error) {
+	// 打开 ZIP 文件
+	zipReader, err := a.openZip(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	// 获取工作目录中的文件路径
+	workFilePath := filepath.Join(workDir, relPath)
+
+	// 检查是否是文本文件
+	if !force {
+		fileType, err := compare.DetectFile(zipReader, relPath, workFilePath)
+		if err != nil {
+			return nil, err
+		}
+		if fileType.Category != compare.FileCategoryText {
+			return nil, apperr.ErrUnsupportedFile
+		}
+	}
+
+	// 比较文件
+	differ := compare.NewTextDiffer()
+	if a.configMgr != nil {
+		differ.SetMaxPreviewSize(a.configMgr.Get().MaxPreviewSize)
+		differ.SetNormalizer(a.normalizer())
+		differ.SetCache(a.diffCache, a.diffCacheOptions())
+	}
+	diff, err := differ.CompareFiles(zipReader, relPath, workFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 标注修改行的提交信息（失败时不影响预览）
+	if a.configMgr != nil && a.configMgr.Get().EnableGitBlame && vcs.IsGitRepo(workDir) {
+		if blame, err := vcs.Blame(workDir, relPath); err == nil {
+			compare.AnnotateBlame(diff, blame)
+		} else {
+			runtime.LogWarning(a.ctx, fmt.Sprintf("git blame failed for %s: %v", relPath, err))
+		}
+	}
+
+	return diff, nil
+}
+
+// OpenExternalDiff 用配置的外部比较工具（externalDiffTool）打开最近一次比较结果中文件的差异
+// 基准一侧的文件解出到临时目录，工作目录一侧直接使用原文件，在外部工具中修改后保存即可生效；新增和删除的文件另一侧为空文件
+// 外部工具可能在后台继续读取文件（如 code --diff 启动后立即返回），临时文件在程序退出时删除
+func (a *App) OpenExternalDiff(relPath string) error {
+	if config.ReadOnly() {
+		return apperr.ErrReadOnly.WithMessage("只读模式下不能将基准文件解出到临时目录")
+	}
+	var template string
+	if a.configMgr != nil {
+		template = a.configMgr.Get().ExternalDiffTool
+	}
+	if strings.TrimSpace(template) == "" {
+		return appError(difftool.ErrNotConfigured)
+	}
+
+	a.mu.Lock()
+	result, meta, baseline := a.lastResult, a.lastMeta, a.lastBaseline
+	a.mu.Unlock()
+	if result == nil {
+		return apperr.ErrNoResult
+	}
+	full, err := a.fullResult(result)
+	if err != nil {
+		return err
+	}
+	item, ok := findItem(full.Items, relPath)
+	if !ok {
+		return apperr.ErrInvalidArgument.WithMessage("差异项不在最近一次比较结果中").WithDetail(relPath)
+	}
+
+	dir, err := a.temp.MkdirTemp("diff-*")
+	if err != nil {
+		return appError(err)
+	}
+	name := filepath.Base(item.RelPath)
+	oldPath := filepath.Join(dir, "baseline", name)
+	newPath := filepath.Join(meta.WorkDir, item.RelPath)
+	if err := a.extractBaseline(*item, baseline, meta, oldPath); err != nil {
+		a.temp.Release(dir)
+		return appError(err)
+	}
+	if item.Type == "deleted" {
+		newPath = filepath.Join(dir, "work", name)
+		if err := writeEmptyFile(newPath); err != nil {
+			a.temp.Release(dir)
+			return appError(err)
+		}
+	}
+
+	cmd, err := difftool.Command(template, oldPath, newPath, filepath.ToSlash(item.RelPath))
+	if err != nil {
+		a.temp.Release(dir)
+		return appError(err)
+	}
+	if err := cmd.Start(); err != nil {
+		a.temp.Release(dir)
+		return apperr.ErrExternalTool.WithDetail(cmd.Path).Wrap(err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// extractBaseline 将差异项在基准中的内容写入 destPath（重命名的文件为旧路径的内容），新增的文件写入空文件
+// baselinePath 为空时基准为 git 引用（meta.Baseline）；快照基准没有文件内容，只能处理新增的文件
+func (a *App) extractBaseline(item models.DiffItem, baselinePath string, meta report.Meta, destPath string) error {
+	if item.Type == "added" {
+		return writeEmptyFile(destPath)
+	}
+	relPath := item.RelPath
+	if item.OldPath != "" {
+		relPath = item.OldPath
+	}
+
+	if compare.IsSnapshot(baselinePath) {
+		return apperr.ErrUnsupportedFile.WithMessage("快照只记录文件的哈希，没有基准一侧的内容")
+	}
+	if baselinePath == "" {
+		content, err := vcs.ShowFile(meta.WorkDir, meta.Baseline, relPath)
+		if err != nil {
+			return apperr.ErrVcsFailed.Wrap(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(destPath, content, 0644)
+	}
+
+	zipReader, err := a.openZip(baselinePath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+	return zipReader.ExtractFile(filepath.ToSlash(relPath), destPath)
+}
+
+// writeEmptyFile 创建空文件，上级目录不存在时创建
+func writeEmptyFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, nil, 0644)
+}
+
+// DetectFileType 检测文件的 MIME 类型和类别（text/binary/image），供前端选择图标和预览方式
+func (a *App) DetectFileType(zipPath, workDir, relPath string) (models.FileType, error) {
+	var zipReader *compare.ZipReader
+	if zipPath != "" {
+		reader, err := a.openZip(zipPath)
+		if err != nil {
+			return models.FileType{}, err
+		}
+		defer reader.Close()
+		zipReader = reader
+	}
+
+	return compare.DetectFile(zipReader, relPath, filepath.Join(workDir, relPath))
+}
+
+// AuditTextFiles 检查最近一次比较结果中文本文件在基准和工作目录中的换行符和编码，zipPath 为空时只检查工作目录一侧
+func (a *App) AuditTextFiles(zipPath string) (*models.TextAuditReport, error) {
+	a.mu.Lock()
+	result := a.lastResult
+	a.mu.Unlock()
+
+	if result == nil {
+		return nil, apperr.ErrNoResult
+	}
+	items := result.Items
+	if result.Spilled {
+		var err error
+		if items, err = a.results.Items(result.ResultID); err != nil {
+			return nil, err
+		}
+	}
+
+	var zipReader *compare.ZipReader
+	if zipPath != "" {
+		reader, err := a.openZip(zipPath)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		zipReader = reader
+	}
+	var allowed []string
+	if a.configMgr != nil {
+		allowed = a.configMgr.Get().AllowedEncodings
+	}
+	return compare.AuditTextFiles(zipReader, items, allowed), nil
+}
+
+// ExportDiffs 导出差异文件
+func (a *App) ExportDiffs(items []models.DiffItem, outputDir, baseName string) error {
+	if config.ReadOnly() {
+		return apperr.ErrReadOnly
+	}
+	if outputDir == "" {
+		return apperr.ErrInvalidArgument.WithMessage("请选择输出目录")
+	}
+
+	outputDir, _, err := a.exportNaming(outputDir, baseName)
+	if err != nil {
+		return err
+	}
+	if remote.IsRemote(outputDir) {
+		if err := a.exportRemote(items, outputDir, "", a.exportOptions(items, baseName)); err != nil {
+			return err
+		}
+		return a.commitExported(items, baseName)
+	}
+	if err := checkLocks(exportTargets(items, outputDir)); err != nil {
+		return err
+	}
+	if issues := platform.CheckWritable(exportTargets(items, outputDir)); len(issues) > 0 {
+		return apperr.ErrPermissionDenied.WithDetail(issues[0].Path)
+	}
+
+	err = compare.ExportDiffs(items, outputDir, a.exportOptions(items, baseName), func(current, total int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Current: current,
+			Total:   total,
+			Message: message,
+		})
+	})
+	if err != nil {
+		return appError(err)
+	}
+
+	return a.commitExported(items, baseName)
+}
+
+// exportOptions 按配置返回导出选项，开启变更日志时按选中的差异项生成 CHANGELOG.md 的内容
+func (a *App) exportOptions(items []models.DiffItem, baseName string) compare.ExportOptions {
+	if a.configMgr == nil {
+		return compare.ExportOptions{}
+	}
+	cfg := a.configMgr.Get()
+	opts := compare.ExportOptions{Checksums: cfg.ExportChecksums, MD5Sums: cfg.ExportMD5Sums, Recipients: cfg.EncryptRecipients, EOL: cfg.ExportEOL, Verify: cfg.VerifyExports}
+	if cfg.SignExports {
+		opts.SigningKey, _ = config.SigningKeyPath()
+	}
+	opts.Journal, _ = config.ExportJournalFilePath()
+	if cfg.ArchiveBaseline {
+		a.archiveOptions(&opts, cfg.ArchiveHardLink)
+	}
+	if cfg.ExportChangelog {
+		a.mu.Lock()
+		meta := a.lastMeta
+		a.mu.Unlock()
+		if meta.Baseline == "" {
+			meta.Baseline = baseName
+		}
+		opts.Changelog = report.RenderChangelog(items, meta, time.Now())
+	}
+	return opts
+}
+
+// archiveOptions 设置导出时一并保存的基准 ZIP 和最近一次比较结果，读取比较结果失败时只记录警告
+func (a *App) archiveOptions(opts *compare.ExportOptions, hardLink bool) {
+	a.mu.Lock()
+	result, meta, baseline := a.lastResult, a.lastMeta, a.lastBaseline
+	a.mu.Unlock()
+	opts.Baseline, opts.HardLink = baseline, hardLink
+	if result == nil {
+		return
+	}
+
+	full, err := a.fullResult(result)
+	if err == nil {
+		opts.Result, err = report.RenderResultJSON(full, meta, time.Now())
+	}
+	if err != nil {
+		runtime.LogWarning(a.ctx, fmt.Sprintf("archive compare result: %v", err))
+	}
+}
+
+// exportNaming 返回按输出子目录模板确定的实际导出目录，以及渲染文件名模板使用的变量
+func (a *App) exportNaming(outputDir, baseName string) (string, compare.NameVars, error) {
+	vars := compare.NameVars{Base: baseName, Time: time.Now()}
+	if a.configMgr == nil {
+		return outputDir, vars, nil
+	}
+	cfg := a.configMgr.Get()
+	vars.Ticket = cfg.ExportTicket
+	a.mu.Lock()
+	if len(a.tickets) > 0 {
+		vars.Ticket = strings.Join(ticketIDs(a.tickets), "_")
+	}
+	a.mu.Unlock()
+	vars.DateFormat = cfg.NameDateFormat
+	vars.Label = cfg.NameLabel
+	if strings.TrimSpace(cfg.ExportDirTemplate) == "" {
+		return outputDir, vars, nil
+	}
+	subDir, err := compare.RenderDirName(cfg.ExportDirTemplate, vars)
+	if err != nil {
+		return "", vars, appError(err)
+	}
+	if remote.IsRemote(outputDir) {
+		return remote.Join(outputDir, subDir), vars, nil
+	}
+	return filepath.Join(outputDir, subDir), vars, nil
+}
+
+// exportRemote 导出到远程目标（sftp://、s3://），zipName 为空时逐个上传文件，否则打包为 ZIP 上传
+// 成功后将地址记录为最近使用的输出目录
+func (a *App) exportRemote(items []models.DiffItem, dest, zipName string, opts compare.ExportOptions) error {
+	var cfg models.RemoteConfig
+	if a.configMgr != nil {
+		cfg = a.configMgr.Get().Remote
+	}
+	target, err := remote.Open(dest, cfg)
+	if err != nil {
+		return appError(err)
+	}
+	defer target.Close()
+
+	progress := func(current, total int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Current: current,
+			Total:   total,
+			Message: message,
+		})
+	}
+	if zipName == "" {
+		err = compare.ExportDiffsToRemote(items, target, opts, progress)
+	} else {
+		err = compare.ExportZipToRemote(items, target, zipName, opts, progress)
+	}
+	if err != nil {
+		return apperr.ErrRemoteFailed.WithDetail(target.String()).Wrap(err)
+	}
+
+	if a.configMgr != nil {
+		a.configMgr.AddRecentOutputDir(remote.Redact(dest))
+	}
+	return nil
+}
+
+// zipNameTemplate 返回配置的 ZIP 文件名模板
+func (a *App) zipNameTemplate() string {
+	if a.configMgr == nil {
+		return ""
+	}
+	return a.configMgr.Get().ZipNameTemplate
+}
+
+// GenerateSigningKey 生成导出签名使用的 Ed25519 密钥对，返回公钥（PEM），供分发给接收方校验
+func (a *App) GenerateSigningKey(overwrite bool) (string, error) {
+	if config.ReadOnly() {
+		return "", apperr.ErrReadOnly
+	}
+	keyPath, err := config.SigningKeyPath()
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := signing.GenerateKey(keyPath, overwrite)
+	if errors.Is(err, signing.ErrKeyExists) {
+		return "", apperr.ErrInvalidArgument.WithMessage("签名密钥已存在，重新生成后接收方需要更新公钥")
+	}
+	return publicKey, err
+}
+
+// GetSigningPublicKey 返回导出签名的公钥（PEM），尚未生成时返回错误
+func (a *App) GetSigningPublicKey() (string, error) {
+	keyPath, err := config.SigningKeyPath()
+	if err != nil {
+		return "", err
+	}
+	publicKey, err := signing.PublicKey(keyPath)
+	if err != nil {
+		return "", appError(err)
+	}
+	return publicKey, nil
+}
+
+// ExportToZip 直接将选中的差异文件导出为 ZIP
+func (a *App) ExportToZip(items []models.DiffItem, outputDir, baseName string) (string, error) {
+	if config.ReadOnly() {
+		return "", apperr.ErrReadOnly
+	}
+	if outputDir == "" {
+		return "", apperr.ErrInvalidArgument.WithMessage("请选择输出目录")
+	}
+
+	outputDir, vars, err := a.exportNaming(outputDir, baseName)
+	if err != nil {
+		return "", err
+	}
+	zipName, err := compare.GenerateZipName(a.zipNameTemplate(), vars)
+	if err != nil {
+		return "", appError(err)
+	}
+	opts := a.exportOptions(items, baseName)
+	if len(opts.Recipients) > 0 {
+		zipName += encrypt.FileExt
+	}
+	if remote.IsRemote(outputDir) {
+		if err := a.exportRemote(items, outputDir, zipName, opts); err != nil {
+			return "", err
+		}
+		return remote.Redact(remote.Join(outputDir, zipName)), a.commitExported(items, baseName)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", appError(err)
+	}
+	zipPath := filepath.Join(outputDir, zipName)
+
+	if err := checkLocks([]string{zipPath}); err != nil {
+		return "", err
+	}
+
+	err = compare.ExportDiffsToZip(items, zipPath, opts, func(current, total int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Current: current,
+			Total:   total,
+			Message: message,
+		})
+	})
+
+	if err != nil {
+		return "", appError(err)
+	}
+
+	if err := a.commitExported(items, baseName); err != nil {
+		return zipPath, err
+	}
+
+	return zipPath, nil
+}
+
+// ExportDeltaPackage 将最近一次与基准 ZIP 比较得到的选中差异文件打包为差分 ZIP，返回差分包路径
+// 按配置的 deltaPatchMinSize 将较大的修改文件保存为相对基准的二进制补丁，可用 ApplyDeltaPackage 应用到与基准一致的目录
+func (a *App) ExportDeltaPackage(items []models.DiffItem, outputDir, baseName string) (string, error) {
+	if config.ReadOnly() {
+		return "", apperr.ErrReadOnly
+	}
+	if outputDir == "" {
+		return "", apperr.ErrInvalidArgument.WithMessage("请选择输出目录")
+	}
+	a.mu.Lock()
+	baselinePath := a.lastBaseline
+	a.mu.Unlock()
+	if !strings.EqualFold(filepath.Ext(baselinePath), ".zip") {
+		return "", apperr.ErrInvalidArgument.WithMessage("只有与基准 ZIP 比较的结果可以导出为差分包")
+	}
+
+	outputDir, vars, err := a.exportNaming(outputDir, baseName)
+	if err != nil {
+		return "", err
+	}
+	deltaName, err := compare.GenerateZipName(a.zipNameTemplate(), vars)
+	if err != nil {
+		return "", appError(err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", appError(err)
+	}
+	deltaPath := filepath.Join(outputDir, deltaName)
+	if err := checkLocks([]string{deltaPath}); err != nil {
+		return "", err
+	}
+
+	baseline, err := a.openZip(baselinePath)
+	if err != nil {
+		return "", err
+	}
+	defer baseline.Close()
+	var opts compare.DeltaOptions
+	if a.configMgr != nil {
+		opts.PatchMinSize = a.configMgr.Get().DeltaPatchMinSize
+	}
+	err = compare.ExportDelta(baseline, items, deltaPath, opts, func(current, total int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Phase:   "export",
+			Current: current,
+			Total:   total,
+			Message: message,
+		})
+	})
+	if err != nil {
+		return "", appError(err)
+	}
+	return deltaPath, nil
+}
+
+// CreateDeltaPackage 比较旧 ZIP 和新 ZIP，将新增和修改的文件及删除清单打包为差分 ZIP，返回差分包路径
+func (a *App) CreateDeltaPackage(oldZipPath, newZipPath, outputDir string) (string, error) {
+	if config.ReadOnly() {
+		return "", apperr.ErrReadOnly
+	}
+	if oldZipPath == "" || newZipPath == "" {
+		return "", apperr.ErrInvalidArgument.WithMessage("请选择新旧两个 ZIP 文件")
+	}
+	if outputDir == "" {
+		return "", apperr.ErrInvalidArgument.WithMessage("请选择输出目录")
+	}
+	for _, path := range []string{oldZipPath, newZipPath} {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return "", apperr.ErrZipNotFound.WithDetail(path)
+		}
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(newZipPath), filepath.Ext(newZipPath))
+	outputDir, vars, err := a.exportNaming(outputDir, baseName)
+	if err != nil {
+		return "", err
+	}
+	deltaName, err := compare.GenerateZipName(a.zipNameTemplate(), vars)
+	if err != nil {
+		return "", appError(err)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", appError(err)
+	}
+	deltaPath := filepath.Join(outputDir, deltaName)
+	if err := checkLocks([]string{deltaPath}); err != nil {
+		return "", err
+	}
+
+	var rules []models.ExcludeRule
+	var opts compare.DeltaOptions
+	if a.configMgr != nil {
+		rules = a.configMgr.EffectiveExcludeRules()
+		opts.PatchMinSize = a.configMgr.Get().DeltaPatchMinSize
+	}
+	_, err = compare.CreateDelta(oldZipPath, newZipPath, deltaPath, rules, opts, func(current, total int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Phase:   "export",
+			Current: current,
+			Total:   total,
+			Message: message,
+		})
+	})
+	if err != nil {
+		return "", appError(err)
+	}
+	return deltaPath, nil
+}
+
+// ApplyDeltaPackage 将差分包应用到与旧基准一致的目标目录，dryRun 时只校验
+// 存在冲突时不修改目标目录，返回的错误详情为第一个冲突的文件
+func (a *App) ApplyDeltaPackage(deltaPath, targetDir string, dryRun bool) (*models.DeltaApplyReport, error) {
+	if config.ReadOnly() && !dryRun {
+		return nil, apperr.ErrReadOnly.WithMessage("只读模式下只能校验差分包")
+	}
+	if deltaPath == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择差分包")
+	}
+	if _, err := os.Stat(deltaPath); os.IsNotExist(err) {
+		return nil, apperr.ErrZipNotFound.WithDetail(deltaPath)
+	}
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return nil, apperr.ErrWorkDirMissing.WithDetail(targetDir)
+	}
+
+	report, err := compare.ApplyDelta(deltaPath, targetDir, dryRun, func(current, total int, message string) {
+		runtime.EventsEmit(a.ctx, "backend:progress", models.ProgressEvent{
+			Phase:   "export",
+			Current: current,
+			Total:   total,
+			Message: message,
+		})
+	})
+	if errors.Is(err, compare.ErrDeltaConflict) {
+		first := report.Conflicts[0]
+		return nil, apperr.ErrDeltaConflict.WithDetail(fmt.Sprintf("%s: %s（共 %d 个文件）", first.Path, first.Reason, len(report.Conflicts)))
+	}
+	if errors.Is(err, compare.ErrDeltaLocked) {
+		return nil, lockedError(report.Locked)
+	}
+	if err != nil && report != nil && len(report.Applied) > 0 {
+		// 部分文件已替换，列出这些文件以便确认目标目录的状态
+		return nil, apperr.ErrFileFailed.WithMessage(fmt.Sprintf("应用差分包中途失败，已修改 %d 个文件", len(report.Applied))).
+			WithDetail(strings.Join(report.Applied, "\n")).Wrap(err)
+	}
+	if err != nil {
+		return nil, appError(err)
+	}
+	return report, nil
+}
+
+// VerifyPackage 校验本工具导出的 ZIP：按包内 SHA256SUMS 检查被篡改、缺失和多出的文件，
+// 并用配置中信任的公钥和本机签名公钥校验 ZIP 旁的分离签名
+func (a *App) VerifyPackage(zipPath string) (*models.PackageVerifyReport, error) {
+	if zipPath == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择 ZIP 文件")
+	}
+	if _, err := os.Stat(zipPath); os.IsNotExist(err) {
+		return nil, apperr.ErrZipNotFound.WithDetail(zipPath)
+	}
+
+	var publicKeys []string
+	if a.configMgr != nil {
+		publicKeys = append(publicKeys, a.configMgr.Get().TrustedSigningKeys...)
+	}
+	if keyPath, err := config.SigningKeyPath(); err == nil {
+		if publicKey, err := signing.PublicKey(keyPath); err == nil {
+			publicKeys = append(publicKeys, publicKey)
+		}
+	}
+
+	report, err := compare.VerifyPackage(zipPath, publicKeys)
+	if err != nil {
+		return nil, appError(err)
+	}
+	return report, nil
+}
+
+// CheckExportLocks 检查导出目标中被其他进程占用的文件，供前端在导出前提示
+func (a *App) CheckExportLocks(items []models.DiffItem, outputDir string) []models.LockedFile {
+	return platform.FindLockedFiles(exportTargets(items, outputDir))
+}
+
+// CheckExportWritable 预检导出目标是否可写，返回没有写入权限的路径
+func (a *App) CheckExportWritable(items []models.DiffItem, outputDir string) []models.PathIssue {
+	return platform.CheckWritable(exportTargets(items, outputDir))
+}
+
+// ExportDiffsElevated 通过 UAC 提示以管理员身份导出差异文件，用于写入 Program Files、inetpub 等受保护目录
+func (a *App) ExportDiffsElevated(items []models.DiffItem, outputDir, baseName string) error {
+	if config.ReadOnly() {
+		return apperr.ErrReadOnly
+	}
+	if outputDir == "" {
+		return apperr.ErrInvalidArgument.WithMessage("请选择输出目录")
+	}
+	outputDir, _, err := a.exportNaming(outputDir, baseName)
+	if err != nil {
+		return err
+	}
+	if err := checkLocks(exportTargets(items, outputDir)); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	workDir := a.lastMeta.WorkDir
+	a.mu.Unlock()
+
+	job := elevatedExportJob{Items: items, WorkDir: workDir, OutputDir: outputDir, Options: a.exportOptions(items, baseName)}
+	if err := runElevatedJob(exe, job); err != nil {
+		return appError(err)
+	}
+
+	return a.commitExported(items, baseName)
+}
+
+// exportTargets 返回导出选中项时将写入的目标路径
+func exportTargets(items []models.DiffItem, outputDir string) []string {
+	targets := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Selected && item.Type != "deleted" {
+			targets = append(targets, filepath.Join(outputDir, item.RelPath))
+		}
+	}
+	return targets
+}
+
+// checkLocks 在写入前检查目标文件是否被占用，被占用时返回列出文件和进程的错误
+func checkLocks(paths []string) error {
+	if locked := platform.FindLockedFiles(paths); len(locked) > 0 {
+		return lockedError(locked)
+	}
+	return nil
+}
+
+// lockedError 返回每行列出一个被占用文件及占用进程的错误
+func lockedError(locked []models.LockedFile) error {
+	lines := make([]string, 0, len(locked))
+	for _, f := range locked {
+		if len(f.Processes) > 0 {
+			lines = append(lines, fmt.Sprintf("%s（%s）", f.Path, strings.Join(f.Processes, "、")))
+		} else {
+			lines = append(lines, f.Path)
+		}
+	}
+	return apperr.ErrFileLocked.WithDetail(strings.Join(lines, "\n"))
+}
+
+// commitExported 按配置将导出的文件提交到工作目录的 git 仓库
+func (a *App) commitExported(items []models.DiffItem, baseName string) error {
+	if a.configMgr == nil {
+		return nil
+	}
+	cfg := a.configMgr.Get()
+	if !cfg.GitCommitOnExport {
+		return nil
+	}
+
+	paths := make([]string, 0)
+	for _, item := range items {
+		if item.Selected && item.Type != "deleted" && item.SourcePath != "" {
+			paths = append(paths, item.SourcePath)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	repoDir := filepath.Dir(paths[0])
+	if !vcs.IsGitRepo(repoDir) {
+		return nil
+	}
+
+	message, err := vcs.RenderCommitMessage(cfg.GitCommitMessage, baseName, len(paths))
+	if err != nil {
+		return apperr.ErrVcsFailed.WithMessage("导出成功，但提交信息模板无效").Wrap(err)
+	}
+	if _, err := vcs.CommitFiles(repoDir, paths, message); err != nil {
+		return apperr.ErrVcsFailed.WithMessage("导出成功，但提交到 git 失败").Wrap(err)
+	}
+	return nil
+}
+
+// GetConfig 获取配置
+func (a *App) GetConfig() models.Config {
+	if a.configMgr == nil {
+		return models.Config{}
+	}
+	return a.configMgr.Get()
+}
+
+// SaveConfig 保存配置，共享规则地址改变时立即重新获取
+func (a *App) SaveConfig(cfg models.Config) error {
+	if a.configMgr == nil {
+		return apperr.ErrNotInitialized
+	}
+	if err := compare.ValidateKeywordRules(cfg.KeywordRules); err != nil {
+		return appError(err)
+	}
+	if err := compare.ValidateContentIgnoreRules(cfg.ContentIgnoreRules); err != nil {
+		return appError(err)
+	}
+	if err := compare.ValidateNormalizeRules(cfg.NormalizeRules); err != nil {
+		return appError(err)
+	}
+	sourceChanged := cfg.SharedRulesURL != a.configMgr.Get().SharedRulesURL
+	if err := a.configMgr.Set(cfg); err != nil {
+		return appError(err)
+	}
+	a.applyLogLevel()
+	if sourceChanged {
+		go a.RefreshSharedRules()
+	}
+	return nil
+}
+
+// GetSetting 获取通用设置项（界面偏好等），不存在时返回 nil
+func (a *App) GetSetting(key string) any {
+	if a.configMgr == nil {
+		return nil
+	}
+	value, _ := a.configMgr.GetSetting(key)
+	return value
+}
+
+// SetSetting 设置通用设置项并保存到配置文件，value 为 nil 时删除该项
+func (a *App) SetSetting(key string, value any) error {
+	if a.configMgr == nil {
+		return apperr.ErrNotInitialized
+	}
+	if key == "" {
+		return apperr.ErrInvalidArgument.WithMessage("设置项名称不能为空")
+	}
+	return appError(a.configMgr.SetSetting(key, value))
+}
+
+// GetZipRootFolder 获取 ZIP 文件的根目录名称
+func (a *App) GetZipRootFolder(zipPath string) (string, error) {
+	zipReader, err := a.openZip(zipPath)
+	if err != nil {
+		return "", err
+	}
+	defer zipReader.Close()
+
+	return zipReader.GetRootFolder(), nil
+}
+
+// GetExcludeRules 获取排除规则
+func (a *App) GetExcludeRules() []models.ExcludeRule {
+	if a.configMgr == nil {
+		return []models.ExcludeRule{}
+	}
+	return a.configMgr.GetExcludeRules()
+}
+
+// GetSharedRules 获取团队共享规则集
+func (a *App) GetSharedRules() models.SharedRuleSet {
+	if a.configMgr == nil {
+		return models.SharedRuleSet{}
+	}
+	return a.configMgr.SharedRules()
+}
+
+// RefreshSharedRules 立即重新获取团队共享规则，失败时继续使用缓存的规则
+func (a *App) RefreshSharedRules() (models.SharedRuleSet, error) {
+	if a.configMgr == nil {
+		return models.SharedRuleSet{}, apperr.ErrNotInitialized
+	}
+	if err := a.configMgr.RefreshSharedRules(a.ctx); err != nil {
+		return a.configMgr.SharedRules(), apperr.ErrSharedRules.Wrap(err)
+	}
+	return a.configMgr.SharedRules(), nil
+}
+
+// SetExcludeRules 设置排除规则
+func (a *App) SetExcludeRules(rules []models.ExcludeRule) error {
+	if a.configMgr == nil {
+		return apperr.ErrNotInitialized
+	}
+	return appError(a.configMgr.SetExcludeRules(rules))
+}
+
+// AddExcludeRule 添加排除规则
+func (a *App) AddExcludeRule(rule models.ExcludeRule) error {
+	if a.configMgr == nil {
+		return apperr.ErrNotInitialized
+	}
+	return appError(a.configMgr.AddExcludeRule(rule))
+}
+
+// ImportIgnoreFile 从 .gitignore / .dockerignore 文件导入排除规则，返回新增的规则
+func (a *App) ImportIgnoreFile(path string) ([]models.ExcludeRule, error) {
+	if a.configMgr == nil {
+		return nil, apperr.ErrNotInitialized
+	}
+
+	rules, err := compare.ParseIgnoreFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, apperr.ErrNoRules.WithDetail(path)
+	}
+
+	if err := a.configMgr.AddExcludeRule(rules...); err != nil {
+		return nil, appError(err)
+	}
+	return rules, nil
+}
+
+// RemoveExcludeRule 删除排除规则
+func (a *App) RemoveExcludeRule(index int) error {
+	if a.configMgr == nil {
+		return apperr.ErrNotInitialized
+	}
+	return appError(a.configMgr.RemoveExcludeRule(index))
+}
+
+// ResetExcludeRules 重置为默认排除规则
+func (a *App) ResetExcludeRules() error {
+	if a.configMgr == nil {
+		return apperr.ErrNotInitialized
+	}
+	return appError(a.configMgr.ResetExcludeRules())
+}
+
+// appError 将内部包返回的已知错误转换为带错误码的应用错误，其他错误原样返回
+// 单个差异文件的错误附带文件路径，未分类时使用 FILE_FAILED
+func appError(err error) error {
+	var itemErr *compare.ItemError
+	if !errors.As(err, &itemErr) {
+		return classifyError(err)
+	}
+	var appErr *apperr.Error
+	if !errors.As(classifyError(err), &appErr) {
+		appErr = apperr.ErrFileFailed.Wrap(err)
+	}
+	return appErr.WithPath(itemErr.RelPath)
+}
+
+// classifyError 按内部包的错误选择对应的应用错误
+func classifyError(err error) error {
+	var appErr *apperr.Error
+	var verifyErr *compare.VerifyError
+	var ruleErr *compare.RuleError
+	switch {
+	case err == nil, errors.As(err, &appErr):
+		return err
+	case errors.Is(err, compare.ErrEncrypted):
+		return apperr.ErrEncrypted.Wrap(err)
+	case errors.As(err, &ruleErr):
+		return apperr.ErrInvalidArgument.WithMessage(fmt.Sprintf("%s「%s」无效: %v", ruleErr.Kind, ruleErr.Name, ruleErr.Err)).Wrap(err)
+	case errors.As(err, &verifyErr) && len(verifyErr.Files) > 0:
+		return apperr.ErrVerifyFailed.WithDetail(strings.Join(verifyErr.Files, "\n")).WithPath(verifyErr.Files[0])
+	case errors.Is(err, remote.ErrInvalidURL):
+		return apperr.ErrInvalidArgument.WithMessage("远程目标地址无效，应为 sftp://用户@主机/目录 或 s3://存储桶/前缀").Wrap(err)
+	case errors.Is(err, compare.ErrNothingSelected):
+		return apperr.ErrNothingSelected
+	case errors.Is(err, compare.ErrDeltaConflict):
+		return apperr.ErrDeltaConflict.Wrap(err)
+	case errors.Is(err, compare.ErrDeltaInvalid), errors.Is(err, bindiff.ErrCorrupt):
+		return apperr.ErrDeltaInvalid.Wrap(err)
+	case errors.Is(err, signing.ErrNoKey):
+		return apperr.ErrNoSigningKey.Wrap(err)
+	case errors.Is(err, encrypt.ErrInvalidRecipient):
+		return apperr.ErrInvalidArgument.WithMessage("加密公钥无效，应为 age1... 格式")
+	case errors.Is(err, compare.ErrChangelogConflict):
+		return apperr.ErrInvalidArgument.WithMessage("导出的文件中已有 CHANGELOG.md，请关闭「附带变更日志」后再导出")
+	case errors.Is(err, compare.ErrNoSnapshot):
+		return apperr.ErrNoSnapshot.Wrap(err)
+	case errors.Is(err, compare.ErrEvidenceConflict):
+		return apperr.ErrInvalidArgument.WithMessage("导出的文件与要保存的基准 ZIP 或 compare-result.json 同名，请关闭配置中的 archiveBaseline 后再导出").Wrap(err)
+	case errors.Is(err, difftool.ErrNotConfigured):
+		return apperr.ErrInvalidArgument.WithMessage("请先在配置中设置外部比较工具（externalDiffTool），如 code --diff {old} {new}")
+	case errors.Is(err, difftool.ErrInvalidCommand):
+		return apperr.ErrExternalTool.WithMessage("外部比较工具的命令无效，请检查引号是否成对").Wrap(err)
+	case errors.Is(err, compare.ErrInvalidHashAlgorithm):
+		return apperr.ErrInvalidArgument.WithMessage("比较算法应为 crc32、md5、sha256 或留空")
+	case errors.Is(err, compare.ErrInvalidEOL):
+		return apperr.ErrInvalidArgument.WithMessage("配置项 exportEol 应为 lf、crlf 或留空")
+	case errors.Is(err, compare.ErrNameTemplate):
+		return apperr.ErrInvalidArgument.WithMessage("导出命名模板无效").Wrap(err)
+	case errors.Is(err, compare.ErrNoDifferences):
+		return apperr.ErrNothingSelected.WithMessage("两个 ZIP 的内容相同，没有差异")
+	case errors.Is(err, platform.ErrElevationCancelled):
+		return apperr.ErrCancelled.Wrap(err)
+	case errors.Is(err, platform.ErrElevationUnsupported):
+		return apperr.ErrElevationFailed.WithMessage("当前系统不支持以管理员身份导出")
+	case errors.Is(err, review.ErrInvalidStatus):
+		return apperr.ErrInvalidArgument.WithMessage("审阅状态无效").Wrap(err)
+	case errors.Is(err, config.ErrReadOnly):
+		return apperr.ErrReadOnly.WithMessage("只读模式下不保存配置，修改只在本次运行中生效").Wrap(err)
+	case errors.Is(err, os.ErrPermission):
+		return apperr.ErrPermissionDenied.Wrap(err)
+	case errors.Is(err, zip.ErrFormat):
+		return apperr.ErrZipInvalid.Wrap(err)
 	}
-	return a.configMgr.ResetExcludeRules()
+	return err
 }
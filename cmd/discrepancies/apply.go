@@ -0,0 +1,77 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/config"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "apply",
+		summary: "校验目标目录与差分包的旧版本一致后，应用补丁、复制和删除文件",
+		usage:   "apply --delta 差分.zip [--dir 目标目录] [--check] [--progress ndjson]",
+		setup:   setupApply,
+	})
+}
+
+func setupApply(fs *flag.FlagSet) func(args []string) error {
+	deltaPath := fs.String("delta", "", "差分 ZIP 文件")
+	targetDir := fs.String("dir", ".", "目标目录（与旧基准一致）")
+	check := fs.Bool("check", false, "只校验，不修改目标目录")
+	progressMode := addProgressFlag(fs)
+
+	return func(args []string) error {
+		if len(args) > 0 || *deltaPath == "" {
+			return errUsage
+		}
+		if !*check && config.ReadOnly() {
+			return fmt.Errorf("只读模式下只能使用 --check 校验")
+		}
+		if _, err := os.Stat(*deltaPath); err != nil {
+			return fmt.Errorf("差分包不存在: %s", *deltaPath)
+		}
+		if info, err := os.Stat(*targetDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("目标目录不存在: %s", *targetDir)
+		}
+
+		progress, err := newProgressReporter(*progressMode)
+		if err != nil {
+			return err
+		}
+		report, err := compare.ApplyDelta(*deltaPath, *targetDir, *check, progress.exportProgress())
+		progress.done()
+		if errors.Is(err, compare.ErrDeltaConflict) {
+			for _, conflict := range report.Conflicts {
+				fmt.Printf("! %s: %s\n", conflict.Path, conflict.Reason)
+			}
+			return fmt.Errorf("%d 个文件与差分包的旧版本不一致，未做任何修改", len(report.Conflicts))
+		}
+		if errors.Is(err, compare.ErrDeltaLocked) {
+			for _, f := range report.Locked {
+				fmt.Printf("! %s %v\n", f.Path, f.Processes)
+			}
+			return fmt.Errorf("%d 个文件被其他程序占用，未做任何修改", len(report.Locked))
+		}
+		if err != nil && report != nil && len(report.Applied) > 0 {
+			for _, p := range report.Applied {
+				fmt.Printf("+ %s\n", p)
+			}
+			return fmt.Errorf("应用中途失败，以上 %d 个文件已修改: %w", len(report.Applied), err)
+		}
+		if err != nil {
+			return err
+		}
+
+		if report.DryRun {
+			fmt.Printf("校验通过：%d 个文件待应用，%d 个文件已是新版本\n", report.Pending, report.Skipped)
+			return nil
+		}
+		fmt.Printf("已应用差分包：新增 %d，替换 %d，补丁 %d，删除 %d，跳过 %d；%d 个文件校验一致\n",
+			report.Added, report.Replaced, report.Patched, report.Deleted, report.Skipped, report.Verified)
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/models"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "audit",
+		summary: "比较后检查差异文本文件在基准和工作目录中的换行符（LF、CRLF、混用）和编码（UTF-8、UTF-8 BOM、Shift-JIS、GBK）",
+		usage:   "audit --zip 基准.zip [--dir 工作目录] [--sub 子目录] [--depth 2] [--issues] [--encodings utf-8,utf-8-bom]",
+		setup:   setupAudit,
+	})
+}
+
+func setupAudit(fs *flag.FlagSet) func(args []string) error {
+	zipPath := fs.String("zip", "", "基准 ZIP 文件")
+	workDir := fs.String("dir", ".", "工作目录")
+	scope := addScopeFlags(fs)
+	issuesOnly := fs.Bool("issues", false, "只列出换行符或编码与基准不同、混用换行符或编码不符合要求的文件")
+	encodings := fs.String("encodings", "", "允许的编码，逗号分隔，默认取 .discrepancies.json 的 allowedEncodings；有不符合的文件时返回错误")
+
+	return func(args []string) error {
+		if len(args) > 0 || *zipPath == "" {
+			return errUsage
+		}
+
+		project, err := loadProjectConfig(*workDir)
+		if err != nil {
+			return err
+		}
+		allowed := project.AllowedEncodings
+		if *encodings != "" {
+			allowed = splitList(*encodings)
+		}
+
+		comparer, err := newComparer(*zipPath, *workDir, scope)
+		if err != nil {
+			return err
+		}
+		result, err := comparer.Compare()
+		if err != nil {
+			return err
+		}
+		zipReader, err := compare.NewZipReader(*zipPath)
+		if err != nil {
+			return err
+		}
+		defer zipReader.Close()
+
+		report := compare.AuditTextFiles(zipReader, result.Items, allowed)
+		for _, file := range report.Files {
+			issue := file.EOLChanged || file.NewEOL == models.EOLMixed || file.EncodingChanged || file.PolicyViolation
+			if *issuesOnly && !issue {
+				continue
+			}
+			mark := " "
+			if issue {
+				mark = "!"
+			}
+			note := ""
+			switch {
+			case file.PolicyViolation:
+				note = "  编码不符合要求"
+			case file.EncodingOnly:
+				note = "  仅转换编码"
+			}
+			fmt.Printf("%s %-5s → %-5s  %-9s → %-9s  %s%s\n", mark,
+				eolLabel(file.OldEOL), eolLabel(file.NewEOL),
+				encodingLabel(file.OldEncoding), encodingLabel(file.NewEncoding),
+				filepath.ToSlash(file.RelPath), note)
+		}
+		fmt.Printf("工作目录: LF %d，CRLF %d，混用 %d；换行符与基准不同 %d\n", report.LF, report.CRLF, report.Mixed, report.EOLChanged)
+		fmt.Printf("编码与基准不同 %d（仅转换编码 %d）", report.EncodingChanged, report.EncodingOnly)
+		if len(allowed) > 0 {
+			fmt.Printf("；不符合 %s %d", strings.Join(allowed, "、"), report.Violations)
+		}
+		fmt.Println()
+
+		if report.Violations > 0 {
+			return fmt.Errorf("%d 个文件的编码不符合要求", report.Violations)
+		}
+		return nil
+	}
+}
+
+// eolLabel 换行符的显示名称，不存在的一侧显示为 -
+func eolLabel(eol string) string {
+	switch eol {
+	case "":
+		return "-"
+	case models.EOLMixed:
+		return "mixed"
+	}
+	return strings.ToUpper(eol)
+}
+
+// encodingLabel 编码的显示名称，不存在的一侧显示为 -
+func encodingLabel(enc string) string {
+	if enc == "" {
+		return "-"
+	}
+	return enc
+}
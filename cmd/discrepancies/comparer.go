@@ -0,0 +1,97 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/models"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// scopeFlags 限定比较范围的参数
+type scopeFlags struct {
+	sub   *string
+	depth *int
+}
+
+// addScopeFlags 注册 --sub 和 --depth 参数
+func addScopeFlags(fs *flag.FlagSet) scopeFlags {
+	return scopeFlags{
+		sub:   fs.String("sub", "", "只比较该子目录（相对于 ZIP 根目录和工作目录）"),
+		depth: fs.Int("depth", 0, "只比较前几层目录中的文件（从 --sub 指定的子目录开始计算），0 表示不限制"),
+	}
+}
+
+// newComparer 创建比较器，使用工作目录下 .discrepancies.json 中的规则，没有项目配置时使用默认排除规则
+// zipPath 为空时只扫描工作目录（记录快照或与快照比较）
+func newComparer(zipPath, workDir string, scope scopeFlags) (*compare.Comparer, error) {
+	if _, err := os.Stat(zipPath); zipPath != "" && err != nil {
+		return nil, fmt.Errorf("ZIP 文件不存在: %s", zipPath)
+	}
+	if info, err := os.Stat(workDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("工作目录不存在: %s", workDir)
+	}
+
+	project, err := loadProjectConfig(workDir)
+	if err != nil {
+		return nil, err
+	}
+	comparer := compare.NewComparer(zipPath, workDir)
+	if err := comparer.SetSubPath(*scope.sub); err != nil {
+		return nil, fmt.Errorf("--sub 必须是工作目录下的相对路径: %s", *scope.sub)
+	}
+	if info, err := os.Stat(filepath.Join(workDir, *scope.sub)); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("子目录不存在: %s", filepath.Join(workDir, *scope.sub))
+	}
+	comparer.SetMaxDepth(*scope.depth)
+	comparer.SetExcludeRules(project.ExcludeRules)
+	comparer.SetRespectGitignore(project.RespectGitignore)
+	normalizeRules := project.NormalizeRules
+	if project.IgnoreBOM {
+		normalizeRules = append([]models.NormalizeRule{compare.BOMNormalizeRule()}, normalizeRules...)
+	}
+	if project.IgnoreEncoding {
+		normalizeRules = append([]models.NormalizeRule{compare.EncodingNormalizeRule()}, normalizeRules...)
+	}
+	comparer.SetNormalizer(compare.NewNormalizer(normalizeRules, project.ContentIgnoreRules))
+	comparer.SetIgnoreBOM(project.IgnoreBOM)
+	comparer.SetIgnoreEncoding(project.IgnoreEncoding)
+	comparer.SetDetectCommentsOnly(project.DetectCommentsOnly)
+	comparer.SetKeywordScanner(compare.NewKeywordScanner(project.KeywordRules))
+	return comparer, nil
+}
+
+// loadProjectConfig 读取目录下的 .discrepancies.json，不存在时返回使用默认排除规则和关键字规则的配置
+// 规则无法解析时返回指出该规则的错误
+func loadProjectConfig(dir string) (models.ProjectConfig, error) {
+	project, ok, err := config.LoadProjectConfig(dir)
+	if err != nil {
+		return models.ProjectConfig{}, err
+	}
+	if !ok {
+		project.ExcludeRules = config.DefaultExcludeRules()
+		project.KeywordRules = config.DefaultKeywordRules()
+	}
+	if err := compare.ValidateKeywordRules(project.KeywordRules); err != nil {
+		return models.ProjectConfig{}, projectRuleError(err)
+	}
+	if err := compare.ValidateContentIgnoreRules(project.ContentIgnoreRules); err != nil {
+		return models.ProjectConfig{}, projectRuleError(err)
+	}
+	if err := compare.ValidateNormalizeRules(project.NormalizeRules); err != nil {
+		return models.ProjectConfig{}, projectRuleError(err)
+	}
+	return project, nil
+}
+
+// projectRuleError 说明项目配置中哪条规则无法解析
+func projectRuleError(err error) error {
+	var ruleErr *compare.RuleError
+	if errors.As(err, &ruleErr) {
+		return fmt.Errorf("%s 中的%s「%s」无效: %v", config.ProjectFileName, ruleErr.Kind, ruleErr.Name, ruleErr.Err)
+	}
+	return err
+}
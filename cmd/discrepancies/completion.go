@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "completion",
+		summary: "输出 shell 补全脚本（bash、zsh、fish、powershell）",
+		usage:   "completion <bash|zsh|fish|powershell>",
+		setup:   setupCompletion,
+	})
+}
+
+// completionShells 支持的 shell 及对应的生成函数
+var completionShells = map[string]func(w io.Writer){
+	"bash":       writeBashCompletion,
+	"zsh":        writeZshCompletion,
+	"fish":       writeFishCompletion,
+	"powershell": writePowerShellCompletion,
+}
+
+func setupCompletion(fs *flag.FlagSet) func(args []string) error {
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+		write, ok := completionShells[args[0]]
+		if !ok {
+			return fmt.Errorf("不支持的 shell: %s", args[0])
+		}
+		write(os.Stdout)
+		return nil
+	}
+}
+
+// flagInfo 子命令参数的描述，用于生成补全脚本和手册
+type flagInfo struct {
+	name     string
+	usage    string
+	defValue string
+	isBool   bool
+}
+
+// commandFlags 在新的 FlagSet 上注册子命令的参数并返回参数列表
+func commandFlags(cmd *command) []flagInfo {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	cmd.setup(fs)
+
+	flags := make([]flagInfo, 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		info := flagInfo{name: f.Name, usage: f.Usage, defValue: f.DefValue}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			info.isBool = true
+		}
+		flags = append(flags, info)
+	})
+	return flags
+}
+
+// commandNames 返回所有子命令名称
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+	return names
+}
+
+// quoteSingle 转义单引号，用于 shell 和 PowerShell 的单引号字符串
+func quoteSingle(s string, escaped string) string {
+	return strings.ReplaceAll(s, "'", escaped)
+}
+
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintln(w, "# bash completion for discrepancies")
+	fmt.Fprintln(w, "_discrepancies() {")
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `    if [ "$COMP_CWORD" -eq 1 ]; then`)
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W '%s' -- \"$cur\"))\n", strings.Join(commandNames(), " "))
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    case "${COMP_WORDS[1]}" in`)
+	for _, cmd := range commands {
+		words := make([]string, 0)
+		for _, f := range commandFlags(cmd) {
+			words = append(words, "--"+f.name)
+		}
+		if cmd.name == "completion" {
+			words = append(words, "bash", "zsh", "fish", "powershell")
+		}
+		if len(words) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "        %s)\n", cmd.name)
+		fmt.Fprintln(w, `            if [[ "$cur" == -* || "$COMP_CWORD" -eq 2 ]]; then`)
+		fmt.Fprintf(w, "                COMPREPLY=($(compgen -W '%s' -- \"$cur\"))\n", strings.Join(words, " "))
+		fmt.Fprintln(w, "            fi")
+		fmt.Fprintln(w, "            ;;")
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o default -F _discrepancies discrepancies")
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintln(w, "#compdef discrepancies")
+	fmt.Fprintln(w, "_discrepancies() {")
+	fmt.Fprintln(w, "    local -a commands")
+	fmt.Fprintln(w, "    commands=(")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "        '%s:%s'\n", cmd.name, quoteSingle(cmd.summary, `'\''`))
+	}
+	fmt.Fprintln(w, "    )")
+	fmt.Fprintln(w, "    if (( CURRENT == 2 )); then")
+	fmt.Fprintln(w, "        _describe 'command' commands")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    case $words[2] in")
+	for _, cmd := range commands {
+		specs := make([]string, 0)
+		for _, f := range commandFlags(cmd) {
+			usage := quoteSingle(strings.NewReplacer("[", `\[`, "]", `\]`).Replace(f.usage), `'\''`)
+			if f.isBool {
+				specs = append(specs, fmt.Sprintf("'--%s[%s]'", f.name, usage))
+			} else {
+				specs = append(specs, fmt.Sprintf("'--%s=[%s]:%s:_files'", f.name, usage, f.name))
+			}
+		}
+		if cmd.name == "completion" {
+			specs = append(specs, "'1:shell:(bash zsh fish powershell)'")
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "        %s) _arguments %s ;;\n", cmd.name, strings.Join(specs, " "))
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, `_discrepancies "$@"`)
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintln(w, "# fish completion for discrepancies")
+	fmt.Fprintln(w, "complete -c discrepancies -f")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "complete -c discrepancies -n '__fish_use_subcommand' -a %s -d '%s'\n",
+			cmd.name, quoteSingle(cmd.summary, `\'`))
+	}
+	for _, cmd := range commands {
+		condition := fmt.Sprintf("__fish_seen_subcommand_from %s", cmd.name)
+		for _, f := range commandFlags(cmd) {
+			requires := " -r -F"
+			if f.isBool {
+				requires = ""
+			}
+			fmt.Fprintf(w, "complete -c discrepancies -n '%s' -l %s%s -d '%s'\n",
+				condition, f.name, requires, quoteSingle(f.usage, `\'`))
+		}
+		if cmd.name == "completion" {
+			fmt.Fprintf(w, "complete -c discrepancies -n '%s' -a 'bash zsh fish powershell'\n", condition)
+		}
+	}
+}
+
+func writePowerShellCompletion(w io.Writer) {
+	fmt.Fprintln(w, "# PowerShell completion for discrepancies")
+	fmt.Fprintln(w, "Register-ArgumentCompleter -Native -CommandName discrepancies -ScriptBlock {")
+	fmt.Fprintln(w, "    param($wordToComplete, $commandAst, $cursorPosition)")
+	fmt.Fprintln(w, "    $commands = @{")
+	for _, cmd := range commands {
+		words := make([]string, 0)
+		for _, f := range commandFlags(cmd) {
+			words = append(words, "'--"+f.name+"'")
+		}
+		if cmd.name == "completion" {
+			words = append(words, "'bash'", "'zsh'", "'fish'", "'powershell'")
+		}
+		fmt.Fprintf(w, "        '%s' = @(%s)\n", cmd.name, strings.Join(words, ", "))
+	}
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $elements = $commandAst.CommandElements | ForEach-Object { $_.ToString() }")
+	fmt.Fprintln(w, "    if ($elements.Count -le 1 -or ($elements.Count -eq 2 -and $wordToComplete)) {")
+	fmt.Fprintln(w, "        $candidates = $commands.Keys")
+	fmt.Fprintln(w, "    } else {")
+	fmt.Fprintln(w, "        $candidates = $commands[$elements[1]]")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $candidates | Where-Object { $_ -like \"$wordToComplete*\" } | Sort-Object | ForEach-Object {")
+	fmt.Fprintln(w, "        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"Discrepancies/internal/encrypt"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "decrypt",
+		summary: "用 age 私钥解密加密导出的 .zip.age",
+		usage:   "decrypt --identity 私钥文件 [--out 输出.zip] 包.zip.age",
+		writes:  true,
+		setup:   setupDecrypt,
+	})
+}
+
+func setupDecrypt(fs *flag.FlagSet) func(args []string) error {
+	identityFile := fs.String("identity", "", "age 私钥文件（AGE-SECRET-KEY-1...）")
+	output := fs.String("out", "", "输出文件，默认为去掉 .age 的文件名")
+
+	return func(args []string) error {
+		if len(args) != 1 || *identityFile == "" {
+			return errUsage
+		}
+		input := args[0]
+		target := *output
+		if target == "" {
+			if !strings.HasSuffix(input, encrypt.FileExt) {
+				return fmt.Errorf("请使用 --out 指定输出文件")
+			}
+			target = strings.TrimSuffix(input, encrypt.FileExt)
+		}
+
+		identity, err := readIdentity(*identityFile)
+		if err != nil {
+			return err
+		}
+
+		in, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		plain, err := encrypt.Decrypt(in, identity)
+		if err != nil {
+			return err
+		}
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, plain); err != nil {
+			out.Close()
+			os.Remove(target)
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+
+		fmt.Printf("已解密到 %s\n", target)
+		return nil
+	}
+}
+
+// readIdentity 读取私钥文件中的第一个 age 私钥，忽略注释行（与 age-keygen 的输出格式兼容）
+func readIdentity(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "AGE-SECRET-KEY-") {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("私钥文件中没有 age 私钥: %s", path)
+}
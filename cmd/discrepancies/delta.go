@@ -0,0 +1,120 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "delta",
+		summary: "比较新旧两个基准 ZIP（或基准 ZIP 与工作目录），将新增和修改的文件及删除清单打包为差分 ZIP",
+		usage:   "delta --old 旧.zip (--new 新.zip | --dir 工作目录 [--sub 子目录] [--depth 2]) --out 差分.zip [--ticket 构建号] [--date-format iso] [--patch] [--patch-min-size 1048576] [--progress ndjson]",
+		writes:  true,
+		setup:   setupDelta,
+	})
+}
+
+func setupDelta(fs *flag.FlagSet) func(args []string) error {
+	oldZip := fs.String("old", "", "旧的基准 ZIP 文件")
+	newZip := fs.String("new", "", "新的基准 ZIP 文件")
+	workDir := fs.String("dir", "", "与旧 ZIP 比较的工作目录，代替 --new")
+	scope := addScopeFlags(fs)
+	output := fs.String("out", "", "差分 ZIP 文件，可使用 {base}（新 ZIP 的文件名，使用 --dir 时为旧 ZIP 的文件名）、{ticket}、{date}、{time}、{env:变量名} 占位符")
+	names := addNameFlags(fs)
+	patch := fs.Bool("patch", false, "较大的修改文件保存为相对旧版本的二进制补丁")
+	patchMinSize := fs.Int64("patch-min-size", compare.DefaultPatchMinSize, "保存为补丁的文件大小下限（字节）")
+	progressMode := addProgressFlag(fs)
+
+	return func(args []string) error {
+		if len(args) > 0 || *oldZip == "" || (*newZip == "") == (*workDir == "") || *output == "" {
+			return errUsage
+		}
+		for _, path := range []string{*oldZip, *newZip} {
+			if _, err := os.Stat(path); path != "" && err != nil {
+				return fmt.Errorf("ZIP 文件不存在: %s", path)
+			}
+		}
+
+		baseName := strings.TrimSuffix(filepath.Base(*newZip), filepath.Ext(*newZip))
+		if *workDir != "" {
+			baseName = strings.TrimSuffix(filepath.Base(*oldZip), filepath.Ext(*oldZip))
+		}
+		deltaPath, _, err := renderOutput(*output, baseName, names)
+		if err != nil {
+			return err
+		}
+
+		progress, err := newProgressReporter(*progressMode)
+		if err != nil {
+			return err
+		}
+		var opts compare.DeltaOptions
+		if *patch {
+			opts.PatchMinSize = max(*patchMinSize, 1)
+		}
+		if *workDir != "" {
+			return exportDelta(*oldZip, *workDir, scope, deltaPath, opts, progress)
+		}
+
+		// 排除规则取自当前目录的 .discrepancies.json
+		project, err := loadProjectConfig(".")
+		if err != nil {
+			return err
+		}
+		result, err := compare.CreateDelta(*oldZip, *newZip, deltaPath, project.ExcludeRules, opts, progress.exportProgress())
+		progress.done()
+		if errors.Is(err, compare.ErrNoDifferences) {
+			fmt.Println("两个 ZIP 的内容相同，没有生成差分包")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("已生成差分包 %s：新增 %d，修改 %d，删除 %d\n", deltaPath, result.Added, result.Modified, result.Deleted)
+		return nil
+	}
+}
+
+// exportDelta 比较基准 ZIP 与工作目录，将差异文件打包为差分 ZIP，读取失败的文件只提示
+func exportDelta(zipPath, workDir string, scope scopeFlags, deltaPath string, opts compare.DeltaOptions, progress *progressReporter) error {
+	comparer, err := newComparer(zipPath, workDir, scope)
+	if err != nil {
+		return err
+	}
+	progress.attach(comparer)
+	result, err := comparer.Compare()
+	progress.done()
+	if err != nil {
+		return err
+	}
+	for _, item := range result.Items {
+		if item.Error != "" {
+			fmt.Fprintf(os.Stderr, "警告: %s: %s\n", filepath.ToSlash(item.RelPath), item.Error)
+		}
+	}
+
+	baseline, err := compare.NewZipReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer baseline.Close()
+	err = compare.ExportDelta(baseline, result.Items, deltaPath, opts, progress.exportProgress())
+	progress.done()
+	if errors.Is(err, compare.ErrNothingSelected) {
+		fmt.Println("工作目录与基准 ZIP 一致，没有生成差分包")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("已生成差分包 %s：新增 %d，修改 %d，删除 %d，重命名 %d\n", deltaPath, result.Added, result.Modified, result.Deleted, result.Renamed)
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/encrypt"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/remote"
+	"Discrepancies/internal/report"
+	"Discrepancies/internal/store"
+	"Discrepancies/internal/tracker"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stringList 可重复指定的字符串参数
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func init() {
+	commands = append(commands, &command{
+		name:    "export",
+		summary: "比较后按路径和差异类型筛选，导出差异文件到目录或 ZIP",
+		usage:   "export --zip 基准.zip [--dir 工作目录] [--sub 子目录] [--depth 2] --out 输出目录|sftp://用户@主机/目录|s3://存储桶/前缀 [--only 'src/**'] [--types added,modified] [--as-zip] [--name '{base}_{ticket}_{date}'] [--ticket 构建号] [--date-format iso] [--label delta] [--changelog] [--note '路径=说明'] [--fail-on-findings] [--eol crlf] [--checksums] [--md5sums] [--verify] [--archive-baseline] [--hard-link] [--sign] [--encrypt-to age1...] [--progress ndjson]",
+		writes:  true,
+		setup:   setupExport,
+	})
+}
+
+func setupExport(fs *flag.FlagSet) func(args []string) error {
+	zipPath := fs.String("zip", "", "基准 ZIP 文件")
+	workDir := fs.String("dir", ".", "工作目录")
+	scope := addScopeFlags(fs)
+	outputDir := fs.String("out", "", "输出目录或远程目标（sftp://、s3://），可使用 {base}、{ticket}、{date}、{time}、{env:变量名} 占位符")
+	types := fs.String("types", "added,modified", "导出的差异类型，逗号分隔（added、modified、renamed）")
+	asZip := fs.Bool("as-zip", false, "打包为 ZIP 文件（保存在输出目录中）")
+	nameTemplate := fs.String("name", compare.DefaultZipNameTemplate, "ZIP 文件名模板，占位符同 --out")
+	names := addNameFlags(fs)
+	var opts compare.ExportOptions
+	fs.BoolVar(&opts.Checksums, "checksums", false, "写入 SHA256SUMS")
+	fs.BoolVar(&opts.MD5Sums, "md5sums", false, "写入 MD5SUMS")
+	fs.StringVar(&opts.EOL, "eol", "", "将导出的文本文件的换行符统一为 lf 或 crlf")
+	fs.BoolVar(&opts.Verify, "verify", false, "导出后重新读取写入的文件，校验内容与源文件一致")
+	archive := fs.Bool("archive-baseline", false, "将基准 ZIP 和比较结果（compare-result.json）保存到输出目录（--as-zip 时保存在 ZIP 旁边）")
+	fs.BoolVar(&opts.HardLink, "hard-link", false, "保存基准 ZIP 时优先创建硬链接，无法链接时复制")
+	sign := fs.Bool("sign", false, "用 keygen 生成的密钥生成分离签名（.sig）")
+	var recipients stringList
+	fs.Var(&recipients, "encrypt-to", "用 age 公钥（age1...）加密 ZIP，可重复指定（需同时使用 --as-zip）")
+	changelog := fs.Bool("changelog", false, "附带 CHANGELOG.md，列出导出的文件")
+	var notes stringList
+	fs.Var(&notes, "note", "写入变更日志的文件说明，格式为 路径=说明，可重复指定")
+	failOnFindings := fs.Bool("fail-on-findings", false, "导出的文件命中关键字规则时不导出，返回错误")
+	var only stringList
+	fs.Var(&only, "only", "仅导出匹配的路径（glob，可重复指定）")
+	progressMode := addProgressFlag(fs)
+
+	return func(args []string) error {
+		if len(args) > 0 || *zipPath == "" || *outputDir == "" || (len(recipients) > 0 && !*asZip) || compare.ValidateEOL(opts.EOL) != nil {
+			return errUsage
+		}
+		opts.Recipients = recipients
+
+		baseName := strings.TrimSuffix(filepath.Base(*zipPath), filepath.Ext(*zipPath))
+		outDir, vars, err := renderOutput(*outputDir, baseName, names)
+		if err != nil {
+			return err
+		}
+		zipName, err := compare.GenerateZipName(*nameTemplate, vars)
+		if err != nil {
+			return nameError(err)
+		}
+
+		progress, err := newProgressReporter(*progressMode)
+		if err != nil {
+			return err
+		}
+		if *sign {
+			if opts.SigningKey, err = config.SigningKeyPath(); err != nil {
+				return err
+			}
+		}
+		comparer, err := newComparer(*zipPath, *workDir, scope)
+		if err != nil {
+			return err
+		}
+		progress.attach(comparer)
+		result, err := comparer.Compare()
+		progress.done()
+		if err != nil {
+			return err
+		}
+
+		filter := models.ResultFilter{Types: splitList(*types), Paths: only}
+		items := make([]models.DiffItem, 0)
+		for _, item := range store.FilterItems(result.Items, filter) {
+			// 无法读取的文件不导出，只提示
+			if item.Error != "" {
+				fmt.Fprintf(os.Stderr, "警告: %s: %s\n", filepath.ToSlash(item.RelPath), item.Error)
+				continue
+			}
+			if item.Unstable {
+				fmt.Fprintf(os.Stderr, "警告: %s: 比较时文件正在变化，导出的可能不是最终内容\n", filepath.ToSlash(item.RelPath))
+			}
+			items = append(items, item)
+		}
+		if len(items) == 0 {
+			fmt.Println("没有符合条件的差异文件")
+			return nil
+		}
+		if flagged := reportFindings(items); flagged > 0 && *failOnFindings {
+			return fmt.Errorf("%d 个文件命中关键字规则，已取消导出", flagged)
+		}
+		meta := report.Meta{Baseline: filepath.Base(*zipPath), WorkDir: *workDir}
+		for _, id := range tracker.Normalize([]string{*names.ticket}) {
+			meta.Tickets = append(meta.Tickets, models.TicketRef{ID: id})
+		}
+		if *changelog {
+			if err := applyNotes(items, notes); err != nil {
+				return err
+			}
+			opts.Changelog = report.RenderChangelog(items, meta, vars.Time)
+		}
+		if *archive {
+			opts.Baseline = *zipPath
+			if opts.Result, err = report.RenderResultJSON(result, meta, vars.Time); err != nil {
+				return err
+			}
+		}
+
+		if remote.IsRemote(outDir) {
+			return exportRemote(items, outDir, zipName, *asZip, opts, progress)
+		}
+		if *asZip {
+			if err := os.MkdirAll(outDir, 0755); err != nil {
+				return err
+			}
+			target := filepath.Join(outDir, zipName)
+			if len(recipients) > 0 {
+				target += encrypt.FileExt
+			}
+			err := compare.ExportDiffsToZip(items, target, opts, progress.exportProgress())
+			progress.done()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("已导出 %d 个文件到 %s\n", len(items), target)
+			return nil
+		}
+
+		// 上次导出到同一目录中途失败时跳过已导出的文件
+		opts.Journal, _ = config.ExportJournalFilePath()
+		err = compare.ExportDiffs(items, outDir, opts, progress.exportProgress())
+		progress.done()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已导出 %d 个文件到 %s\n", len(items), outDir)
+		return nil
+	}
+}
+
+// exportRemote 导出到远程目标，连接使用配置文件中的 remote 设置和环境变量
+func exportRemote(items []models.DiffItem, dest, zipName string, asZip bool, opts compare.ExportOptions, progress *progressReporter) error {
+	var cfg models.RemoteConfig
+	if mgr, err := config.NewManager(); err == nil {
+		cfg = mgr.Get().Remote
+	}
+	target, err := remote.Open(dest, cfg)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	if asZip {
+		if len(opts.Recipients) > 0 {
+			zipName += encrypt.FileExt
+		}
+		err = compare.ExportZipToRemote(items, target, zipName, opts, progress.exportProgress())
+		progress.done()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已导出 %d 个文件到 %s\n", len(items), remote.Join(target.String(), zipName))
+		return nil
+	}
+	err = compare.ExportDiffsToRemote(items, target, opts, progress.exportProgress())
+	progress.done()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已导出 %d 个文件到 %s\n", len(items), target)
+	return nil
+}
+
+// reportFindings 将命中关键字规则的行输出到标准错误，返回命中的文件数
+func reportFindings(items []models.DiffItem) int {
+	flagged := 0
+	for _, item := range items {
+		for _, finding := range item.Findings {
+			fmt.Fprintf(os.Stderr, "警告: %s:%d 命中「%s」: %s\n", filepath.ToSlash(item.RelPath), finding.Line, finding.Rule, finding.Text)
+		}
+		if len(item.Findings) > 0 {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// applyNotes 将 路径=说明 形式的参数填入对应差异项的变更说明
+func applyNotes(items []models.DiffItem, notes []string) error {
+	for _, note := range notes {
+		path, text, ok := strings.Cut(note, "=")
+		if !ok {
+			return fmt.Errorf("--note 的格式应为 路径=说明: %s", note)
+		}
+		found := false
+		for i := range items {
+			if items[i].RelPath == filepath.FromSlash(path) || filepath.ToSlash(items[i].RelPath) == path {
+				items[i].Note = text
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("--note 指定的文件不在导出列表中: %s", path)
+		}
+	}
+	return nil
+}
+
+// splitList 拆分逗号分隔的参数，忽略空项
+func splitList(value string) []string {
+	list := make([]string, 0)
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"Discrepancies/internal/config"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "init",
+		summary: "在目录中生成项目配置文件 " + config.ProjectFileName + "，按识别到的项目类型预设排除规则",
+		usage:   "init [--dir 目录] [--force]",
+		writes:  true,
+		setup:   setupInit,
+	})
+}
+
+func setupInit(fs *flag.FlagSet) func(args []string) error {
+	dir := fs.String("dir", ".", "项目目录")
+	force := fs.Bool("force", false, "覆盖已存在的配置文件")
+
+	return func(args []string) error {
+		if len(args) > 0 {
+			return errUsage
+		}
+
+		path := filepath.Join(*dir, config.ProjectFileName)
+		if _, err := os.Stat(path); err == nil && !*force {
+			return fmt.Errorf("%s 已存在，使用 --force 覆盖", path)
+		}
+
+		types, err := config.DetectProjectTypes(*dir)
+		if err != nil {
+			return err
+		}
+
+		path, err = config.WriteProjectConfig(*dir, config.NewProjectConfig(types))
+		if err != nil {
+			return err
+		}
+
+		if len(types) > 0 {
+			fmt.Printf("识别到项目类型: %s\n", strings.Join(types, ", "))
+		} else {
+			fmt.Println("未识别到项目类型，仅写入通用规则")
+		}
+		fmt.Printf("已生成 %s\n", path)
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/encrypt"
+	"Discrepancies/internal/signing"
+	"errors"
+	"flag"
+	"fmt"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "keygen",
+		summary: "生成导出签名使用的 Ed25519 密钥对，输出公钥；--age 生成用于加密的 age 密钥",
+		usage:   "keygen [--force] | keygen --age",
+		setup:   setupKeygen,
+	})
+}
+
+func setupKeygen(fs *flag.FlagSet) func(args []string) error {
+	force := fs.Bool("force", false, "覆盖已有的密钥")
+	age := fs.Bool("age", false, "生成 age 密钥（供接收方解密），私钥输出到标准输出")
+
+	return func(args []string) error {
+		if len(args) > 0 {
+			return errUsage
+		}
+		if *age {
+			identity, recipient, err := encrypt.GenerateIdentity()
+			if err != nil {
+				return err
+			}
+			// 与 age-keygen 的输出格式相同
+			fmt.Printf("# public key: %s\n%s\n", recipient, identity)
+			return nil
+		}
+		if config.ReadOnly() {
+			return fmt.Errorf("只读模式下只能使用 --age 生成 age 密钥")
+		}
+
+		keyPath, err := config.SigningKeyPath()
+		if err != nil {
+			return err
+		}
+		publicKey, err := signing.GenerateKey(keyPath, *force)
+		if errors.Is(err, signing.ErrKeyExists) {
+			return fmt.Errorf("签名密钥已存在: %s（使用 --force 覆盖，接收方需要更新公钥）", keyPath)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("已生成签名密钥: %s\n将以下公钥（%s.pub）提供给接收方：\n\n%s", keyPath, keyPath, publicKey)
+		return nil
+	}
+}
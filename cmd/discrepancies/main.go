@@ -0,0 +1,117 @@
+// discrepancies 命令行工具，提供不依赖图形界面的比较、导出等功能
+package main
+
+import (
+	"Discrepancies/internal/config"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// command 子命令定义
+// setup 在 FlagSet 上注册参数并返回执行函数，补全脚本和帮助信息也由它生成
+type command struct {
+	name    string
+	summary string
+	usage   string
+	writes  bool // 是否写入文件，只读模式下拒绝执行
+	setup   func(fs *flag.FlagSet) func(args []string) error
+}
+
+// errUsage 参数错误，退出码为 2
+var errUsage = errors.New("usage error")
+
+// commands 所有子命令，按帮助中显示的顺序排列
+var commands []*command
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 解析子命令并执行，返回退出码
+// 命令前的 --read-only 开启只读模式：写入文件的命令拒绝执行，比较时不写检查点和临时文件
+// --portable 开启便携模式：签名密钥等数据文件使用可执行文件所在目录
+func run(args []string, stdout, stderr io.Writer) int {
+	for len(args) > 0 && (args[0] == config.ReadOnlyFlag || args[0] == config.PortableFlag) {
+		if args[0] == config.ReadOnlyFlag {
+			config.SetReadOnly(true)
+		} else {
+			config.SetPortable(true)
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "未知命令: %s\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+
+	if cmd.writes && config.ReadOnly() {
+		fmt.Fprintf(stderr, "错误: 只读模式下不能执行 %s 命令\n", cmd.name)
+		return 1
+	}
+
+	fs := newFlagSet(cmd, stderr)
+	exec := cmd.setup(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	if err := exec(fs.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			return 2
+		}
+		fmt.Fprintf(stderr, "错误: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// findCommand 按名称查找子命令
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// newFlagSet 创建子命令的 FlagSet
+func newFlagSet(cmd *command, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(output, "用法: discrepancies %s\n\n%s\n\n参数:\n", cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// printUsage 输出命令列表
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "用法: discrepancies [全局参数] <命令> [参数]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "全局参数:")
+	fmt.Fprintf(w, "  %-12s %s\n", config.ReadOnlyFlag, "只读模式，不写入任何文件，拒绝执行导出等写入文件的命令")
+	fmt.Fprintf(w, "  %-12s %s\n", config.PortableFlag, "便携模式，签名密钥等数据文件保存在可执行文件所在目录")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "使用 discrepancies <命令> -h 查看命令的参数")
+}
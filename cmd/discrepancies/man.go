@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "man",
+		summary: "输出 roff 格式的手册页（man discrepancies）",
+		usage:   "man",
+		setup:   setupMan,
+	})
+}
+
+func setupMan(fs *flag.FlagSet) func(args []string) error {
+	return func(args []string) error {
+		if len(args) > 0 {
+			return errUsage
+		}
+		writeManPage(os.Stdout)
+		return nil
+	}
+}
+
+// roffEscape 转义 roff 中有特殊含义的字符
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// writeManPage 根据子命令定义生成手册页
+func writeManPage(w io.Writer) {
+	fmt.Fprintln(w, `.TH DISCREPANCIES 1 "" "discrepancies" "User Commands"`)
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `discrepancies \- 比较 ZIP 压缩包与工作目录的文件差异`)
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, `.B discrepancies`)
+	fmt.Fprintln(w, `\fIcommand\fR [\fIoptions\fR]`)
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, cmd := range commands {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", roffEscape("discrepancies "+cmd.usage))
+		fmt.Fprintln(w, roffEscape(cmd.summary))
+
+		flags := commandFlags(cmd)
+		if len(flags) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, ".RS")
+		for _, f := range flags {
+			fmt.Fprintln(w, ".TP")
+			if f.isBool {
+				fmt.Fprintf(w, `.B \-\-%s`+"\n", roffEscape(f.name))
+			} else {
+				fmt.Fprintf(w, `.BI \-\-%s " value"`+"\n", roffEscape(f.name))
+			}
+			usage := f.usage
+			if !f.isBool && f.defValue != "" {
+				usage += fmt.Sprintf("（默认 %s）", f.defValue)
+			}
+			fmt.Fprintln(w, roffEscape(usage))
+		}
+		fmt.Fprintln(w, ".RE")
+	}
+	fmt.Fprintln(w, ".SH FILES")
+	fmt.Fprintln(w, ".TP")
+	fmt.Fprintln(w, `.I .discrepancies.json`)
+	fmt.Fprintln(w, "工作目录中的项目配置，包含排除规则（由 init 命令生成）")
+	fmt.Fprintln(w, ".SH EXIT STATUS")
+	fmt.Fprintln(w, "0 表示成功，1 表示执行失败，2 表示参数错误。")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/tmpl"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// nameFlags 输出路径和文件名模板使用的参数
+type nameFlags struct {
+	ticket     *string
+	dateFormat *string
+	label      *string
+}
+
+// addNameFlags 注册 --ticket、--date-format、--label 参数
+func addNameFlags(fs *flag.FlagSet) nameFlags {
+	return nameFlags{
+		ticket:     fs.String("ticket", "", "构建号或工单号，可在 --out 和 --name 中以 {ticket} 引用"),
+		dateFormat: fs.String("date-format", tmpl.DateFormatChinese, "{date} 的格式：chinese、iso、compact 或 Go 时间格式"),
+		label:      fs.String("label", compare.DefaultNameLabel, "文件名中缀 {label}"),
+	}
+}
+
+// renderOutput 渲染输出路径中的占位符（{base}、{label}、{ticket}、{date}、{time}、{env:变量名}），不含占位符时原样返回
+func renderOutput(path, baseName string, flags nameFlags) (string, compare.NameVars, error) {
+	vars := compare.NameVars{
+		Base:       baseName,
+		Ticket:     *flags.ticket,
+		Time:       time.Now(),
+		DateFormat: *flags.dateFormat,
+		Label:      *flags.label,
+	}
+	rendered, err := compare.RenderName(path, vars)
+	return rendered, vars, nameError(err)
+}
+
+// nameError 将模板错误转换为提示占位符问题的错误
+func nameError(err error) error {
+	if errors.Is(err, compare.ErrNameTemplate) {
+		return fmt.Errorf("模板中的占位符无法识别或变量未设置（%w）", err)
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// 进度输出格式
+const (
+	progressAuto   = "auto"   // 标准错误是终端时输出文本，否则输出 NDJSON
+	progressText   = "text"   // 单行刷新的文本
+	progressNDJSON = "ndjson" // 每行一个 JSON 对象，供 CI 和包装脚本解析
+	progressNone   = "none"   // 不输出
+)
+
+// progressLine NDJSON 格式的进度记录
+type progressLine struct {
+	models.ProgressEvent
+	Bytes     int64 `json:"bytes"`     // 已计算哈希的字节数
+	ElapsedMs int64 `json:"elapsedMs"` // 自开始以来的毫秒数
+}
+
+// progressReporter 将比较和导出的进度输出到标准错误
+type progressReporter struct {
+	mode  string
+	out   io.Writer
+	start time.Time
+	mu    sync.Mutex
+}
+
+// addProgressFlag 注册 --progress 参数
+func addProgressFlag(fs *flag.FlagSet) *string {
+	return fs.String("progress", progressAuto, "进度输出格式：auto、text、ndjson、none")
+}
+
+// newProgressReporter 按格式创建进度输出，auto 时根据标准错误是否是终端选择
+func newProgressReporter(mode string) (*progressReporter, error) {
+	switch mode {
+	case progressAuto:
+		mode = progressNDJSON
+		if info, err := os.Stderr.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			mode = progressText
+		}
+	case progressText, progressNDJSON, progressNone:
+	default:
+		return nil, fmt.Errorf("不支持的进度格式: %s", mode)
+	}
+	return &progressReporter{mode: mode, out: os.Stderr, start: time.Now()}, nil
+}
+
+// attach 设置比较器的进度和心跳回调
+func (p *progressReporter) attach(comparer *compare.Comparer) {
+	comparer.OnProgress = func(current, total int, message string) {
+		p.report(compare.PhaseCompare, current, total, comparer.Stats().BytesHashed, message)
+	}
+	comparer.OnHeartbeat = func(phase string, count int, message string) {
+		p.report(phase, count, 0, 0, message)
+	}
+}
+
+// exportProgress 返回导出函数使用的进度回调
+func (p *progressReporter) exportProgress() func(current, total int, message string) {
+	return func(current, total int, message string) {
+		p.report("export", current, total, 0, message)
+	}
+}
+
+// report 输出一条进度
+func (p *progressReporter) report(phase string, current, total int, bytes int64, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.mode {
+	case progressText:
+		if total > 0 {
+			fmt.Fprintf(p.out, "\r[%d/%d] %s\033[K", current, total, message)
+		} else {
+			fmt.Fprintf(p.out, "\r%s\033[K", message)
+		}
+	case progressNDJSON:
+		line := progressLine{
+			ProgressEvent: models.ProgressEvent{Phase: phase, Current: current, Total: total, Message: message},
+			Bytes:         bytes,
+			ElapsedMs:     time.Since(p.start).Milliseconds(),
+		}
+		if data, err := json.Marshal(line); err == nil {
+			p.out.Write(append(data, '\n'))
+		}
+	}
+}
+
+// done 结束进度输出，文本格式时换行
+func (p *progressReporter) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.mode == progressText {
+		fmt.Fprintln(p.out)
+	}
+}
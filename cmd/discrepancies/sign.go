@@ -0,0 +1,46 @@
+package main
+
+import (
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/signing"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "sign",
+		summary: "用本机的签名密钥为文件（如发布的安装包）生成分离签名 文件.sig",
+		usage:   "sign 文件...",
+		writes:  true,
+		setup:   setupSign,
+	})
+}
+
+func setupSign(fs *flag.FlagSet) func(args []string) error {
+	return func(args []string) error {
+		if len(args) == 0 {
+			return errUsage
+		}
+		keyPath, err := config.SigningKeyPath()
+		if err != nil {
+			return err
+		}
+		for _, path := range args {
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				return fmt.Errorf("文件不存在: %s", path)
+			}
+			sigPath, err := signing.SignFile(path, keyPath)
+			if errors.Is(err, signing.ErrNoKey) {
+				return fmt.Errorf("尚未生成签名密钥，请先运行 discrepancies keygen")
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(sigPath)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"Discrepancies/internal/config"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "snapshot",
+		summary: "记录工作目录中所有文件的 SHA-256 作为快照，之后用 changes 查看此后的变化，不需要基准 ZIP",
+		usage:   "snapshot [--dir 工作目录] [--sub 子目录] [--depth 2] [--out 快照文件]",
+		writes:  true,
+		setup:   setupSnapshot,
+	})
+	commands = append(commands, &command{
+		name:    "changes",
+		summary: "与 snapshot 记录的快照比较，列出此后新增（+）、修改（~）和删除（-）的文件",
+		usage:   "changes [--dir 工作目录] [--sub 子目录] [--depth 2] [--snapshot 快照文件]",
+		setup:   setupChanges,
+	})
+}
+
+func setupSnapshot(fs *flag.FlagSet) func(args []string) error {
+	workDir := fs.String("dir", ".", "工作目录")
+	scope := addScopeFlags(fs)
+	out := fs.String("out", "", "快照文件（sha256sum 格式），默认保存在配置目录中，与图形界面共用")
+
+	return func(args []string) error {
+		if len(args) > 0 {
+			return errUsage
+		}
+		snapshotPath, err := resolveSnapshotPath(*workDir, *out)
+		if err != nil {
+			return err
+		}
+		comparer, err := newComparer("", *workDir, scope)
+		if err != nil {
+			return err
+		}
+		n, err := comparer.Snapshot(snapshotPath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已记录 %d 个文件: %s\n", n, snapshotPath)
+		return nil
+	}
+}
+
+func setupChanges(fs *flag.FlagSet) func(args []string) error {
+	workDir := fs.String("dir", ".", "工作目录")
+	scope := addScopeFlags(fs)
+	snapshot := fs.String("snapshot", "", "快照文件，默认使用 snapshot 为该目录记录的快照")
+
+	return func(args []string) error {
+		if len(args) > 0 {
+			return errUsage
+		}
+		snapshotPath, err := resolveSnapshotPath(*workDir, *snapshot)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(snapshotPath)
+		if err != nil {
+			return fmt.Errorf("没有找到快照，请先运行 snapshot: %s", snapshotPath)
+		}
+		comparer, err := newComparer("", *workDir, scope)
+		if err != nil {
+			return err
+		}
+		result, err := comparer.CompareSnapshot(snapshotPath)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("自 %s 的快照以来: %d 个差异（新增 %d，修改 %d，删除 %d）\n",
+			info.ModTime().Format(time.DateTime), result.TotalFiles, result.Added, result.Modified, result.Deleted)
+		printItems(os.Stdout, result.Items)
+		return nil
+	}
+}
+
+// resolveSnapshotPath 返回指定的快照文件，未指定时返回配置目录中该工作目录的快照
+func resolveSnapshotPath(workDir, path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	absDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", err
+	}
+	return config.SnapshotFilePath(absDir)
+}
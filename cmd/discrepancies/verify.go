@@ -0,0 +1,84 @@
+package main
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/signing"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// errVerifyFailed 校验未通过，详情已输出
+var errVerifyFailed = errors.New("校验未通过")
+
+func init() {
+	commands = append(commands, &command{
+		name:    "verify",
+		summary: "按包内 SHA256SUMS 和分离签名校验导出的 ZIP",
+		usage:   "verify [--key 公钥.pem] 包.zip",
+		setup:   setupVerify,
+	})
+}
+
+func setupVerify(fs *flag.FlagSet) func(args []string) error {
+	var keyFiles stringList
+	fs.Var(&keyFiles, "key", "信任的公钥文件（PEM，可重复指定），本机的签名公钥总是受信任")
+
+	return func(args []string) error {
+		if len(args) != 1 {
+			return errUsage
+		}
+
+		var publicKeys []string
+		for _, keyFile := range keyFiles {
+			data, err := os.ReadFile(keyFile)
+			if err != nil {
+				return fmt.Errorf("无法读取公钥: %s", keyFile)
+			}
+			publicKeys = append(publicKeys, string(data))
+		}
+		if keyPath, err := config.SigningKeyPath(); err == nil {
+			if publicKey, err := signing.PublicKey(keyPath); err == nil {
+				publicKeys = append(publicKeys, publicKey)
+			}
+		}
+
+		report, err := compare.VerifyPackage(args[0], publicKeys)
+		if err != nil {
+			return err
+		}
+
+		if report.HasManifest {
+			fmt.Printf("清单: %d 个文件，%d 个一致\n", report.Files, report.Verified)
+		} else {
+			fmt.Printf("清单: 包内没有 %s\n", compare.SHA256SumsName)
+		}
+		for _, name := range report.Tampered {
+			fmt.Printf("  已修改: %s\n", name)
+		}
+		for _, name := range report.Missing {
+			fmt.Printf("  缺失: %s\n", name)
+		}
+		for _, name := range report.Unlisted {
+			fmt.Printf("  不在清单中: %s\n", name)
+		}
+
+		switch report.Signature {
+		case models.SignatureValid:
+			fmt.Println("签名: 有效")
+		case models.SignatureInvalid:
+			fmt.Println("签名: 无效（内容已改变或公钥不受信任）")
+		default:
+			fmt.Printf("签名: 缺少 %s%s\n", args[0], signing.SignatureExt)
+		}
+
+		if !report.Valid {
+			return errVerifyFailed
+		}
+		fmt.Println("校验通过")
+		return nil
+	}
+}
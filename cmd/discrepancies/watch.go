@@ -0,0 +1,182 @@
+package main
+
+import (
+	"Discrepancies/internal/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "watch",
+		summary: "持续比较工作目录与基准 ZIP，变化时输出新增、变化和恢复的差异",
+		usage:   "watch --zip 基准.zip [--dir 工作目录] [--sub 子目录] [--depth 2] [--interval 2s]",
+		setup:   setupWatch,
+	})
+}
+
+func setupWatch(fs *flag.FlagSet) func(args []string) error {
+	zipPath := fs.String("zip", "", "基准 ZIP 文件")
+	workDir := fs.String("dir", ".", "工作目录")
+	scope := addScopeFlags(fs)
+	interval := fs.Duration("interval", 2*time.Second, "比较间隔")
+
+	return func(args []string) error {
+		if len(args) > 0 || *zipPath == "" || *interval <= 0 {
+			return errUsage
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return watch(ctx, *zipPath, *workDir, scope, *interval, os.Stdout)
+	}
+}
+
+// watch 按间隔重复比较，输出与上一次结果相比的变化，直到 ctx 取消
+func watch(ctx context.Context, zipPath, workDir string, scope scopeFlags, interval time.Duration, out io.Writer) error {
+	var previous map[string]watchState
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		comparer, err := newComparer(zipPath, workDir, scope)
+		if err != nil {
+			return err
+		}
+		result, err := comparer.Compare()
+		if err != nil {
+			return err
+		}
+
+		current := make(map[string]watchState, len(result.Items))
+		for _, item := range result.Items {
+			current[item.RelPath] = newWatchState(item, previous[item.RelPath])
+		}
+
+		if previous == nil {
+			fmt.Fprintf(out, "%s 开始监视: %d 个差异（新增 %d，修改 %d，删除 %d），按 Ctrl+C 退出\n",
+				time.Now().Format("15:04:05"), result.TotalFiles, result.Added, result.Modified, result.Deleted)
+			printItems(out, result.Items)
+		} else {
+			printChanges(out, previous, current)
+		}
+		previous = current
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchState 差异项在一次比较中的状态，内容哈希用于发现已修改的文件又被编辑
+type watchState struct {
+	item    models.DiffItem
+	modTime time.Time
+	size    int64
+	hash    string // 工作目录中文件内容的 SHA-256，删除或无法读取的文件为空
+}
+
+// newWatchState 记录差异项的状态，文件的大小和修改时间与上一次相同时沿用上一次的哈希
+func newWatchState(item models.DiffItem, previous watchState) watchState {
+	state := watchState{item: item}
+	if item.Type == "deleted" || item.SourcePath == "" {
+		return state
+	}
+	info, err := os.Stat(item.SourcePath)
+	if err != nil {
+		return state
+	}
+	state.modTime, state.size = info.ModTime(), info.Size()
+	if previous.hash != "" && previous.modTime.Equal(state.modTime) && previous.size == state.size {
+		state.hash = previous.hash
+		return state
+	}
+	state.hash = fileSHA256(item.SourcePath)
+	return state
+}
+
+// fileSHA256 计算文件内容的 SHA-256，读取失败时返回空字符串
+func fileSHA256(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// printChanges 输出两次比较结果之间的变化
+// 新出现、类型改变或内容又被修改的差异按类型输出，消失的差异（文件恢复为与基准一致）以 = 输出
+func printChanges(out io.Writer, previous, current map[string]watchState) {
+	changed := make([]models.DiffItem, 0)
+	for relPath, state := range current {
+		old, ok := previous[relPath]
+		if !ok || old.item.Type != state.item.Type || old.item.NewSize != state.item.NewSize || old.hash != state.hash {
+			changed = append(changed, state.item)
+		}
+	}
+	for relPath := range previous {
+		if _, ok := current[relPath]; !ok {
+			changed = append(changed, models.DiffItem{RelPath: relPath, Type: "reverted"})
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "%s 差异变化:\n", time.Now().Format("15:04:05"))
+	printItems(out, changed)
+}
+
+// printItems 按路径顺序输出差异项，每行一个
+func printItems(out io.Writer, items []models.DiffItem) {
+	sorted := make([]models.DiffItem, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].RelPath < sorted[j].RelPath })
+
+	for _, item := range sorted {
+		if item.Error != "" {
+			fmt.Fprintf(out, "  ! %s（%s）\n", item.RelPath, item.Error)
+			continue
+		}
+		if item.GeneratedFrom != "" {
+			fmt.Fprintf(out, "  %s %s（由 %s 生成）\n", typeMarker(item.Type), item.RelPath, item.GeneratedFrom)
+			continue
+		}
+		fmt.Fprintf(out, "  %s %s\n", typeMarker(item.Type), item.RelPath)
+	}
+}
+
+// typeMarker 差异类型的单字符标记
+func typeMarker(itemType string) string {
+	switch itemType {
+	case "added":
+		return "+"
+	case "deleted":
+		return "-"
+	case "renamed":
+		return ">"
+	case "reverted":
+		return "="
+	default:
+		return "~"
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"Discrepancies/internal/apperr"
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/platform"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// elevatedExportFlag 以管理员身份重新运行导出时使用的命令行参数
+const elevatedExportFlag = "--elevated-export"
+
+// elevatedExportJob 通过命名管道传递给提权子进程的导出任务
+// 任务不落盘，避免未提权的同用户进程在子进程读取前替换任务文件
+type elevatedExportJob struct {
+	Items     []models.DiffItem     `json:"items"`
+	WorkDir   string                `json:"workDir"` // 工作目录，子进程只导出其中的文件
+	OutputDir string                `json:"outputDir"`
+	Options   compare.ExportOptions `json:"options"` // 导出选项
+}
+
+// elevatedExportResult 提权子进程通过管道返回的执行结果
+type elevatedExportResult struct {
+	Error string `json:"error"` // 成功时为空
+}
+
+// runElevatedExport 在提权子进程中从管道读取导出任务并执行，结果写回管道
+func runElevatedExport(pipeName string) int {
+	if !strings.HasPrefix(pipeName, platform.JobPipePrefix) {
+		return 2
+	}
+	conn, err := os.OpenFile(pipeName, os.O_RDWR, 0)
+	if err != nil {
+		return 2
+	}
+	defer conn.Close()
+
+	var job elevatedExportJob
+	if err := json.NewDecoder(conn).Decode(&job); err != nil {
+		return 2
+	}
+
+	var result elevatedExportResult
+	if err := job.checkPaths(); err != nil {
+		result.Error = err.Error()
+	} else if err := compare.ExportDiffs(job.Items, job.OutputDir, job.Options, nil); err != nil {
+		result.Error = err.Error()
+	}
+
+	if err := json.NewEncoder(conn).Encode(result); err != nil {
+		return 2
+	}
+	if result.Error != "" {
+		return 1
+	}
+	return 0
+}
+
+// checkPaths 检查每个导出项的源文件都在工作目录中、目标路径不会跳出输出目录
+// 提权子进程以管理员身份读写文件，不能信任任务中的任意路径
+func (job *elevatedExportJob) checkPaths() error {
+	if !filepath.IsAbs(job.WorkDir) {
+		return errors.New("export job has no work directory")
+	}
+	for _, item := range job.Items {
+		if !filepath.IsLocal(item.RelPath) {
+			return fmt.Errorf("export path %q is outside the output directory", item.RelPath)
+		}
+		if !withinDir(job.WorkDir, item.SourcePath) {
+			return fmt.Errorf("source %q is outside the work directory", item.SourcePath)
+		}
+		for _, stream := range item.Streams {
+			if !withinDir(job.WorkDir, stream.SourcePath) {
+				return fmt.Errorf("source %q is outside the work directory", stream.SourcePath)
+			}
+		}
+	}
+	return nil
+}
+
+// withinDir 判断 path 是否位于 dir 之下，空路径（如已删除的文件）视为符合
+func withinDir(dir, path string) bool {
+	if path == "" {
+		return true
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil || filepath.IsAbs(rel) {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// runElevatedJob 通过 UAC 提示启动提权子进程，经命名管道发送导出任务并等待结果
+func runElevatedJob(exe string, job elevatedExportJob) error {
+	request, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	pipe, err := platform.NewJobPipe()
+	if err != nil {
+		if errors.Is(err, platform.ErrElevationUnsupported) {
+			return err
+		}
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+	defer pipe.Close()
+
+	type exchange struct {
+		reply []byte
+		err   error
+	}
+	done := make(chan exchange, 1)
+	go func() {
+		reply, err := pipe.Exchange(request)
+		done <- exchange{reply, err}
+	}()
+
+	_, runErr := platform.RunElevatedAndWait(exe, []string{elevatedExportFlag, pipe.Name()})
+	pipe.Abort()
+	got := <-done
+	if runErr != nil {
+		if errors.Is(runErr, platform.ErrElevationCancelled) {
+			return runErr
+		}
+		return apperr.ErrElevationFailed.Wrap(runErr)
+	}
+	if got.err != nil {
+		return fmt.Errorf("failed to read export result: %w", got.err)
+	}
+
+	var result elevatedExportResult
+	if err := json.Unmarshal(got.reply, &result); err != nil {
+		return fmt.Errorf("failed to read export result: %w", err)
+	}
+	if result.Error != "" {
+		return errors.New(result.Error)
+	}
+	return nil
+}
@@ -4,20 +4,90 @@ import {models} from '../models';
 
 export function AddExcludeRule(arg1:models.ExcludeRule):Promise<void>;
 
-export function Compare(arg1:string,arg2:string):Promise<models.CompareResult>;
+export function ApplyDeltaPackage(arg1:string,arg2:string,arg3:boolean):Promise<models.DeltaApplyReport>;
 
-export function ExportDiffs(arg1:Array<models.DiffItem>,arg2:string):Promise<void>;
+export function AuditTextFiles(arg1:string):Promise<models.TextAuditReport>;
+
+export function CheckExportLocks(arg1:Array<models.DiffItem>,arg2:string):Promise<Array<models.LockedFile>>;
+
+export function CheckExportWritable(arg1:Array<models.DiffItem>,arg2:string):Promise<Array<models.PathIssue>>;
+
+export function CheckForUpdates():Promise<models.UpdateInfo>;
+
+export function CleanupTemp():Promise<models.TempUsage>;
+
+export function Compare(arg1:string,arg2:string,arg3:Array<models.ExcludeRule>,arg4:string,arg5:string):Promise<models.CompareResult>;
+
+export function CompareGit(arg1:string,arg2:string,arg3:Array<models.ExcludeRule>):Promise<models.CompareResult>;
+
+export function CompareSnapshot(arg1:string,arg2:string,arg3:Array<models.ExcludeRule>):Promise<models.CompareResult>;
+
+export function CompareWithOptions(arg1:models.CompareOptions):Promise<models.CompareResult>;
+
+export function CopySummaryToClipboard(arg1:string):Promise<void>;
+
+export function CreateDeltaPackage(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function DetectFileType(arg1:string,arg2:string,arg3:string):Promise<models.FileType>;
+
+export function DetectZipNameEncoding(arg1:string):Promise<string>;
+
+export function EstimateCompare(arg1:string,arg2:string):Promise<models.CompareEstimate>;
+
+export function ExportDeltaPackage(arg1:Array<models.DiffItem>,arg2:string,arg3:string):Promise<string>;
+
+export function ExportDiffs(arg1:Array<models.DiffItem>,arg2:string,arg3:string):Promise<void>;
+
+export function ExportDiffsElevated(arg1:Array<models.DiffItem>,arg2:string,arg3:string):Promise<void>;
+
+export function ExportPrintReport(arg1:Array<models.DiffItem>,arg2:string,arg3:string):Promise<string>;
 
 export function ExportToZip(arg1:Array<models.DiffItem>,arg2:string,arg3:string):Promise<string>;
 
+export function FindBaselineTag(arg1:string,arg2:string):Promise<string>;
+
+export function GenerateSigningKey(arg1:boolean):Promise<string>;
+
 export function GetConfig():Promise<models.Config>;
 
 export function GetExcludeRules():Promise<Array<models.ExcludeRule>>;
 
-export function GetTextDiff(arg1:string,arg2:string,arg3:string):Promise<models.TextDiff>;
+export function GetLaunchRequest():Promise<models.OpenRequest>;
+
+export function GetLogFilePath():Promise<string>;
+
+export function GetRecentPaths():Promise<models.RecentPaths>;
+
+export function GetResultPage(arg1:string,arg2:number,arg3:number,arg4:models.ResultFilter,arg5:string):Promise<models.ResultPage>;
+
+export function GetSetting(arg1:string):Promise<any>;
+
+export function GetSharedRules():Promise<models.SharedRuleSet>;
+
+export function GetShellIntegration():Promise<boolean>;
+
+export function GetSigningPublicKey():Promise<string>;
+
+export function GetSnapshot(arg1:string):Promise<models.SnapshotInfo>;
+
+export function GetTempUsage():Promise<models.TempUsage>;
+
+export function GetTextDiff(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<models.TextDiff>;
+
+export function GetVersion():Promise<string>;
+
+export function GetZipNameEncodings():Promise<Array<string>>;
 
 export function GetZipRootFolder(arg1:string):Promise<string>;
 
+export function ImportIgnoreFile(arg1:string):Promise<Array<models.ExcludeRule>>;
+
+export function IsReadOnly():Promise<boolean>;
+
+export function OpenExternalDiff(arg1:string):Promise<void>;
+
+export function RefreshSharedRules():Promise<models.SharedRuleSet>;
+
 export function RemoveExcludeRule(arg1:number):Promise<void>;
 
 export function ResetExcludeRules():Promise<void>;
@@ -31,3 +101,15 @@ export function SelectWorkDir():Promise<string>;
 export function SelectZipFile():Promise<string>;
 
 export function SetExcludeRules(arg1:Array<models.ExcludeRule>):Promise<void>;
+
+export function SetReview(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function SetSetting(arg1:string,arg2:any):Promise<void>;
+
+export function SetShellIntegration(arg1:boolean):Promise<void>;
+
+export function SetTickets(arg1:Array<string>):Promise<Array<models.TicketRef>>;
+
+export function TakeSnapshot(arg1:string):Promise<models.SnapshotInfo>;
+
+export function VerifyPackage(arg1:string):Promise<models.PackageVerifyReport>;
@@ -6,18 +6,94 @@ export function AddExcludeRule(arg1) {
   return window['go']['main']['App']['AddExcludeRule'](arg1);
 }
 
-export function Compare(arg1, arg2) {
-  return window['go']['main']['App']['Compare'](arg1, arg2);
+export function ApplyDeltaPackage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ApplyDeltaPackage'](arg1, arg2, arg3);
 }
 
-export function ExportDiffs(arg1, arg2) {
-  return window['go']['main']['App']['ExportDiffs'](arg1, arg2);
+export function AuditTextFiles(arg1) {
+  return window['go']['main']['App']['AuditTextFiles'](arg1);
+}
+
+export function CheckExportLocks(arg1, arg2) {
+  return window['go']['main']['App']['CheckExportLocks'](arg1, arg2);
+}
+
+export function CheckExportWritable(arg1, arg2) {
+  return window['go']['main']['App']['CheckExportWritable'](arg1, arg2);
+}
+
+export function CheckForUpdates() {
+  return window['go']['main']['App']['CheckForUpdates']();
+}
+
+export function CleanupTemp() {
+  return window['go']['main']['App']['CleanupTemp']();
+}
+
+export function Compare(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['Compare'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function CompareGit(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CompareGit'](arg1, arg2, arg3);
+}
+
+export function CompareSnapshot(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CompareSnapshot'](arg1, arg2, arg3);
+}
+
+export function CompareWithOptions(arg1) {
+  return window['go']['main']['App']['CompareWithOptions'](arg1);
+}
+
+export function CopySummaryToClipboard(arg1) {
+  return window['go']['main']['App']['CopySummaryToClipboard'](arg1);
+}
+
+export function CreateDeltaPackage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateDeltaPackage'](arg1, arg2, arg3);
+}
+
+export function DetectFileType(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DetectFileType'](arg1, arg2, arg3);
+}
+
+export function DetectZipNameEncoding(arg1) {
+  return window['go']['main']['App']['DetectZipNameEncoding'](arg1);
+}
+
+export function EstimateCompare(arg1, arg2) {
+  return window['go']['main']['App']['EstimateCompare'](arg1, arg2);
+}
+
+export function ExportDeltaPackage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportDeltaPackage'](arg1, arg2, arg3);
+}
+
+export function ExportDiffs(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportDiffs'](arg1, arg2, arg3);
+}
+
+export function ExportDiffsElevated(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportDiffsElevated'](arg1, arg2, arg3);
+}
+
+export function ExportPrintReport(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportPrintReport'](arg1, arg2, arg3);
 }
 
 export function ExportToZip(arg1, arg2, arg3) {
   return window['go']['main']['App']['ExportToZip'](arg1, arg2, arg3);
 }
 
+export function FindBaselineTag(arg1, arg2) {
+  return window['go']['main']['App']['FindBaselineTag'](arg1, arg2);
+}
+
+export function GenerateSigningKey(arg1) {
+  return window['go']['main']['App']['GenerateSigningKey'](arg1);
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
@@ -26,14 +102,78 @@ export function GetExcludeRules() {
   return window['go']['main']['App']['GetExcludeRules']();
 }
 
-export function GetTextDiff(arg1, arg2, arg3) {
-  return window['go']['main']['App']['GetTextDiff'](arg1, arg2, arg3);
+export function GetLaunchRequest() {
+  return window['go']['main']['App']['GetLaunchRequest']();
+}
+
+export function GetLogFilePath() {
+  return window['go']['main']['App']['GetLogFilePath']();
+}
+
+export function GetRecentPaths() {
+  return window['go']['main']['App']['GetRecentPaths']();
+}
+
+export function GetResultPage(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['GetResultPage'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function GetSetting(arg1) {
+  return window['go']['main']['App']['GetSetting'](arg1);
+}
+
+export function GetSharedRules() {
+  return window['go']['main']['App']['GetSharedRules']();
+}
+
+export function GetShellIntegration() {
+  return window['go']['main']['App']['GetShellIntegration']();
+}
+
+export function GetSigningPublicKey() {
+  return window['go']['main']['App']['GetSigningPublicKey']();
+}
+
+export function GetSnapshot(arg1) {
+  return window['go']['main']['App']['GetSnapshot'](arg1);
+}
+
+export function GetTempUsage() {
+  return window['go']['main']['App']['GetTempUsage']();
+}
+
+export function GetTextDiff(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['GetTextDiff'](arg1, arg2, arg3, arg4);
+}
+
+export function GetVersion() {
+  return window['go']['main']['App']['GetVersion']();
+}
+
+export function GetZipNameEncodings() {
+  return window['go']['main']['App']['GetZipNameEncodings']();
 }
 
 export function GetZipRootFolder(arg1) {
   return window['go']['main']['App']['GetZipRootFolder'](arg1);
 }
 
+export function ImportIgnoreFile(arg1) {
+  return window['go']['main']['App']['ImportIgnoreFile'](arg1);
+}
+
+export function IsReadOnly() {
+  return window['go']['main']['App']['IsReadOnly']();
+}
+
+export function OpenExternalDiff(arg1) {
+  return window['go']['main']['App']['OpenExternalDiff'](arg1);
+}
+
+export function RefreshSharedRules() {
+  return window['go']['main']['App']['RefreshSharedRules']();
+}
+
 export function RemoveExcludeRule(arg1) {
   return window['go']['main']['App']['RemoveExcludeRule'](arg1);
 }
@@ -61,3 +201,27 @@ export function SelectZipFile() {
 export function SetExcludeRules(arg1) {
   return window['go']['main']['App']['SetExcludeRules'](arg1);
 }
+
+export function SetReview(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetReview'](arg1, arg2, arg3);
+}
+
+export function SetSetting(arg1, arg2) {
+  return window['go']['main']['App']['SetSetting'](arg1, arg2);
+}
+
+export function SetShellIntegration(arg1) {
+  return window['go']['main']['App']['SetShellIntegration'](arg1);
+}
+
+export function SetTickets(arg1) {
+  return window['go']['main']['App']['SetTickets'](arg1);
+}
+
+export function TakeSnapshot(arg1) {
+  return window['go']['main']['App']['TakeSnapshot'](arg1);
+}
+
+export function VerifyPackage(arg1) {
+  return window['go']['main']['App']['VerifyPackage'](arg1);
+}
@@ -1,10 +1,177 @@
 export namespace models {
 	
+	export class CompareEstimate {
+	    baselineFiles: number;
+	    baselineBytes: number;
+	    workFiles: number;
+	    workBytes: number;
+	    hashBytes: number;
+	    excluded: number;
+	    estimatedMs: number;
+	    samples: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareEstimate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.baselineFiles = source["baselineFiles"];
+	        this.baselineBytes = source["baselineBytes"];
+	        this.workFiles = source["workFiles"];
+	        this.workBytes = source["workBytes"];
+	        this.hashBytes = source["hashBytes"];
+	        this.excluded = source["excluded"];
+	        this.estimatedMs = source["estimatedMs"];
+	        this.samples = source["samples"];
+	    }
+	}
+	export class ExcludeRule {
+	    pattern: string;
+	    type: string;
+	    isDir: boolean;
+	    enabled: boolean;
+	    negate: boolean;
+	    comment: string;
+	    source: string;
+	    fromIgnore: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExcludeRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.type = source["type"];
+	        this.isDir = source["isDir"];
+	        this.enabled = source["enabled"];
+	        this.negate = source["negate"];
+	        this.comment = source["comment"];
+	        this.source = source["source"];
+	        this.fromIgnore = source["fromIgnore"];
+	    }
+	}
+	export class CompareOptions {
+	    zipPath: string;
+	    workDir: string;
+	    excludeRules: ExcludeRule[];
+	    sessionRules: ExcludeRule[];
+	    nameEncoding: string;
+	    subPath: string;
+	    hashAlgorithm: string;
+	    fastMode: boolean;
+	    concurrency: number;
+	    caseInsensitive: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompareOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.zipPath = source["zipPath"];
+	        this.workDir = source["workDir"];
+	        this.excludeRules = this.convertValues(source["excludeRules"], ExcludeRule);
+	        this.sessionRules = this.convertValues(source["sessionRules"], ExcludeRule);
+	        this.nameEncoding = source["nameEncoding"];
+	        this.subPath = source["subPath"];
+	        this.hashAlgorithm = source["hashAlgorithm"];
+	        this.fastMode = source["fastMode"];
+	        this.concurrency = source["concurrency"];
+	        this.caseInsensitive = source["caseInsensitive"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SizeChange {
+	    relPath: string;
+	    type: string;
+	    oldSize: number;
+	    newSize: number;
+	    delta: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SizeChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.type = source["type"];
+	        this.oldSize = source["oldSize"];
+	        this.newSize = source["newSize"];
+	        this.delta = source["delta"];
+	    }
+	}
+	export class PathIssue {
+	    path: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PathIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class KeywordFinding {
+	    rule: string;
+	    line: number;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new KeywordFinding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rule = source["rule"];
+	        this.line = source["line"];
+	        this.text = source["text"];
+	    }
+	}
 	export class DiffItem {
 	    relPath: string;
 	    type: string;
 	    selected: boolean;
 	    sourcePath: string;
+	    oldPath: string;
+	    unversioned: boolean;
+	    note: string;
+	    review: string;
+	    error: string;
+	    unstable: boolean;
+	    bomOnly: boolean;
+	    encodingOnly: boolean;
+	    commentsOnly: boolean;
+	    generatedFrom: string;
+	    findings: KeywordFinding[];
+	    linesAdded: number;
+	    linesRemoved: number;
+	    oldSize: number;
+	    newSize: number;
+	    stream: string;
+	    streams: DiffItem[];
 	
 	    static createFrom(source: any = {}) {
 	        return new DiffItem(source);
@@ -16,14 +183,66 @@ export namespace models {
 	        this.type = source["type"];
 	        this.selected = source["selected"];
 	        this.sourcePath = source["sourcePath"];
+	        this.oldPath = source["oldPath"];
+	        this.unversioned = source["unversioned"];
+	        this.note = source["note"];
+	        this.review = source["review"];
+	        this.error = source["error"];
+	        this.unstable = source["unstable"];
+	        this.bomOnly = source["bomOnly"];
+	        this.encodingOnly = source["encodingOnly"];
+	        this.commentsOnly = source["commentsOnly"];
+	        this.generatedFrom = source["generatedFrom"];
+	        this.findings = this.convertValues(source["findings"], KeywordFinding);
+	        this.linesAdded = source["linesAdded"];
+	        this.linesRemoved = source["linesRemoved"];
+	        this.oldSize = source["oldSize"];
+	        this.newSize = source["newSize"];
+	        this.stream = source["stream"];
+	        this.streams = this.convertValues(source["streams"], DiffItem);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class CompareResult {
+	    resultId: string;
 	    items: DiffItem[];
 	    totalFiles: number;
 	    added: number;
 	    modified: number;
 	    deleted: number;
+	    renamed: number;
+	    unversioned: number;
+	    flagged: number;
+	    generated: number;
+	    failed: number;
+	    spilled: boolean;
+	    skipped: PathIssue[];
+	    largest: SizeChange[];
+	    maxDepth: number;
+	    container: string;
+	    totalAddedBytes: number;
+	    totalModifiedBytesDelta: number;
+	    totalDeletedBytes: number;
+	    unchanged: number;
+	    unchangedBytes: number;
+	    excluded: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new CompareResult(source);
@@ -31,11 +250,28 @@ export namespace models {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.resultId = source["resultId"];
 	        this.items = this.convertValues(source["items"], DiffItem);
 	        this.totalFiles = source["totalFiles"];
 	        this.added = source["added"];
 	        this.modified = source["modified"];
 	        this.deleted = source["deleted"];
+	        this.renamed = source["renamed"];
+	        this.unversioned = source["unversioned"];
+	        this.flagged = source["flagged"];
+	        this.generated = source["generated"];
+	        this.failed = source["failed"];
+	        this.spilled = source["spilled"];
+	        this.skipped = this.convertValues(source["skipped"], PathIssue);
+	        this.largest = this.convertValues(source["largest"], SizeChange);
+	        this.maxDepth = source["maxDepth"];
+	        this.container = source["container"];
+	        this.totalAddedBytes = source["totalAddedBytes"];
+	        this.totalModifiedBytesDelta = source["totalModifiedBytesDelta"];
+	        this.totalDeletedBytes = source["totalDeletedBytes"];
+	        this.unchanged = source["unchanged"];
+	        this.unchangedBytes = source["unchangedBytes"];
+	        this.excluded = source["excluded"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -56,31 +292,158 @@ export namespace models {
 		    return a;
 		}
 	}
-	export class ExcludeRule {
-	    pattern: string;
+	export class RemoteConfig {
+	    sshKeyFile: string;
+	    knownHostsFile: string;
+	    s3Endpoint: string;
+	    s3Region: string;
+	    s3AccessKey: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RemoteConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sshKeyFile = source["sshKeyFile"];
+	        this.knownHostsFile = source["knownHostsFile"];
+	        this.s3Endpoint = source["s3Endpoint"];
+	        this.s3Region = source["s3Region"];
+	        this.s3AccessKey = source["s3AccessKey"];
+	    }
+	}
+	export class IssueTrackerConfig {
 	    type: string;
-	    isDir: boolean;
+	    baseUrl: string;
+	    user: string;
+	    token: string;
+	    resolveTitles: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new IssueTrackerConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.baseUrl = source["baseUrl"];
+	        this.user = source["user"];
+	        this.token = source["token"];
+	        this.resolveTitles = source["resolveTitles"];
+	    }
+	}
+	export class KeywordRule {
+	    name: string;
+	    pattern: string;
+	    extensions: string[];
 	    enabled: boolean;
 	    comment: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ExcludeRule(source);
+	        return new KeywordRule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
 	        this.pattern = source["pattern"];
-	        this.type = source["type"];
-	        this.isDir = source["isDir"];
+	        this.extensions = source["extensions"];
+	        this.enabled = source["enabled"];
+	        this.comment = source["comment"];
+	    }
+	}
+	export class NormalizeRule {
+	    pattern: string;
+	    steps: string[];
+	    enabled: boolean;
+	    comment: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NormalizeRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.steps = source["steps"];
+	        this.enabled = source["enabled"];
+	        this.comment = source["comment"];
+	    }
+	}
+	export class ContentIgnoreRule {
+	    pattern: string;
+	    extensions: string[];
+	    enabled: boolean;
+	    comment: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ContentIgnoreRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.extensions = source["extensions"];
 	        this.enabled = source["enabled"];
 	        this.comment = source["comment"];
 	    }
 	}
 	export class Config {
-	    lastZipPath: string;
-	    lastWorkDir: string;
-	    lastOutputDir: string;
+	    recentZipPaths: string[];
+	    recentWorkDirs: string[];
+	    recentOutputDirs: string[];
 	    excludeRules: ExcludeRule[];
+	    sharedRulesUrl: string;
+	    sharedRulesRefresh: number;
+	    updateFeedUrl: string;
+	    updateDownload: boolean;
+	    contentIgnoreRules: ContentIgnoreRule[];
+	    normalizeRules: NormalizeRule[];
+	    keywordRules: KeywordRule[];
+	    allowedEncodings: string[];
+	    largestChanges: number;
+	    readRetries: number;
+	    readRetryDelay: number;
+	    detectCommentsOnly: boolean;
+	    ignoreBom: boolean;
+	    ignoreEncoding: boolean;
+	    listUnchanged: boolean;
+	    maxDepth: number;
+	    readOnly: boolean;
+	    respectGitignore: boolean;
+	    maxPreviewSize: number;
+	    zipNameEncoding: string;
+	    enableGitBlame: boolean;
+	    externalDiffTool: string;
+	    gitCommitOnExport: boolean;
+	    gitCommitMessage: string;
+	    enableSvnStatus: boolean;
+	    logLevel: string;
+	    debugTrace: boolean;
+	    resultMemoryLimit: number;
+	    compareStreams: boolean;
+	    deltaPatchMinSize: number;
+	    exportChecksums: boolean;
+	    exportMd5Sums: boolean;
+	    signExports: boolean;
+	    verifyExports: boolean;
+	    exportEol: string;
+	    trustedSigningKeys: string[];
+	    encryptRecipients: string[];
+	    zipNameTemplate: string;
+	    exportDirTemplate: string;
+	    exportTicket: string;
+	    nameDateFormat: string;
+	    nameLabel: string;
+	    reportNameTemplate: string;
+	    reportTitleTemplate: string;
+	    summaryTitleTemplate: string;
+	    exportChangelog: boolean;
+	    archiveBaseline: boolean;
+	    archiveHardLink: boolean;
+	    issueTracker: IssueTrackerConfig;
+	    remote: RemoteConfig;
+	    settings: Record<string, any>;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -88,10 +451,61 @@ export namespace models {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.lastZipPath = source["lastZipPath"];
-	        this.lastWorkDir = source["lastWorkDir"];
-	        this.lastOutputDir = source["lastOutputDir"];
+	        this.recentZipPaths = source["recentZipPaths"];
+	        this.recentWorkDirs = source["recentWorkDirs"];
+	        this.recentOutputDirs = source["recentOutputDirs"];
 	        this.excludeRules = this.convertValues(source["excludeRules"], ExcludeRule);
+	        this.sharedRulesUrl = source["sharedRulesUrl"];
+	        this.sharedRulesRefresh = source["sharedRulesRefresh"];
+	        this.updateFeedUrl = source["updateFeedUrl"];
+	        this.updateDownload = source["updateDownload"];
+	        this.contentIgnoreRules = this.convertValues(source["contentIgnoreRules"], ContentIgnoreRule);
+	        this.normalizeRules = this.convertValues(source["normalizeRules"], NormalizeRule);
+	        this.keywordRules = this.convertValues(source["keywordRules"], KeywordRule);
+	        this.allowedEncodings = source["allowedEncodings"];
+	        this.largestChanges = source["largestChanges"];
+	        this.readRetries = source["readRetries"];
+	        this.readRetryDelay = source["readRetryDelay"];
+	        this.detectCommentsOnly = source["detectCommentsOnly"];
+	        this.ignoreBom = source["ignoreBom"];
+	        this.ignoreEncoding = source["ignoreEncoding"];
+	        this.listUnchanged = source["listUnchanged"];
+	        this.maxDepth = source["maxDepth"];
+	        this.readOnly = source["readOnly"];
+	        this.respectGitignore = source["respectGitignore"];
+	        this.maxPreviewSize = source["maxPreviewSize"];
+	        this.zipNameEncoding = source["zipNameEncoding"];
+	        this.enableGitBlame = source["enableGitBlame"];
+	        this.externalDiffTool = source["externalDiffTool"];
+	        this.gitCommitOnExport = source["gitCommitOnExport"];
+	        this.gitCommitMessage = source["gitCommitMessage"];
+	        this.enableSvnStatus = source["enableSvnStatus"];
+	        this.logLevel = source["logLevel"];
+	        this.debugTrace = source["debugTrace"];
+	        this.resultMemoryLimit = source["resultMemoryLimit"];
+	        this.compareStreams = source["compareStreams"];
+	        this.deltaPatchMinSize = source["deltaPatchMinSize"];
+	        this.exportChecksums = source["exportChecksums"];
+	        this.exportMd5Sums = source["exportMd5Sums"];
+	        this.signExports = source["signExports"];
+	        this.verifyExports = source["verifyExports"];
+	        this.exportEol = source["exportEol"];
+	        this.trustedSigningKeys = source["trustedSigningKeys"];
+	        this.encryptRecipients = source["encryptRecipients"];
+	        this.zipNameTemplate = source["zipNameTemplate"];
+	        this.exportDirTemplate = source["exportDirTemplate"];
+	        this.exportTicket = source["exportTicket"];
+	        this.nameDateFormat = source["nameDateFormat"];
+	        this.nameLabel = source["nameLabel"];
+	        this.reportNameTemplate = source["reportNameTemplate"];
+	        this.reportTitleTemplate = source["reportTitleTemplate"];
+	        this.summaryTitleTemplate = source["summaryTitleTemplate"];
+	        this.exportChangelog = source["exportChangelog"];
+	        this.archiveBaseline = source["archiveBaseline"];
+	        this.archiveHardLink = source["archiveHardLink"];
+	        this.issueTracker = this.convertValues(source["issueTracker"], IssueTrackerConfig);
+	        this.remote = this.convertValues(source["remote"], RemoteConfig);
+	        this.settings = source["settings"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -113,9 +527,92 @@ export namespace models {
 		}
 	}
 	
+	export class LockedFile {
+	    path: string;
+	    processes: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new LockedFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.processes = source["processes"];
+	    }
+	}
+	export class DeltaApplyReport {
+	    pending: number;
+	    added: number;
+	    replaced: number;
+	    patched: number;
+	    deleted: number;
+	    skipped: number;
+	    verified: number;
+	    conflicts: PathIssue[];
+	    dryRun: boolean;
+	    locked: LockedFile[];
+	    applied: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DeltaApplyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pending = source["pending"];
+	        this.added = source["added"];
+	        this.replaced = source["replaced"];
+	        this.patched = source["patched"];
+	        this.deleted = source["deleted"];
+	        this.skipped = source["skipped"];
+	        this.verified = source["verified"];
+	        this.conflicts = this.convertValues(source["conflicts"], PathIssue);
+	        this.dryRun = source["dryRun"];
+	        this.locked = this.convertValues(source["locked"], LockedFile);
+	        this.applied = source["applied"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class LineBlame {
+	    commit: string;
+	    author: string;
+	    date: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LineBlame(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.commit = source["commit"];
+	        this.author = source["author"];
+	        this.date = source["date"];
+	    }
+	}
 	export class DiffLine {
 	    type: string;
 	    content: string;
+	    newLine: number;
+	    blame?: LineBlame;
 	
 	    static createFrom(source: any = {}) {
 	        return new DiffLine(source);
@@ -125,13 +622,311 @@ export namespace models {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.type = source["type"];
 	        this.content = source["content"];
+	        this.newLine = source["newLine"];
+	        this.blame = this.convertValues(source["blame"], LineBlame);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class FileType {
+	    mimeType: string;
+	    category: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FileType(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mimeType = source["mimeType"];
+	        this.category = source["category"];
+	    }
+	}
+	
+	
+	
+	
+	
+	
+	export class OpenRequest {
+	    zipPath: string;
+	    workDir: string;
+	    compare: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OpenRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.zipPath = source["zipPath"];
+	        this.workDir = source["workDir"];
+	        this.compare = source["compare"];
+	    }
+	}
+	export class PackageVerifyReport {
+	    hasManifest: boolean;
+	    files: number;
+	    verified: number;
+	    tampered: string[];
+	    missing: string[];
+	    unlisted: string[];
+	    signature: string;
+	    valid: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PackageVerifyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hasManifest = source["hasManifest"];
+	        this.files = source["files"];
+	        this.verified = source["verified"];
+	        this.tampered = source["tampered"];
+	        this.missing = source["missing"];
+	        this.unlisted = source["unlisted"];
+	        this.signature = source["signature"];
+	        this.valid = source["valid"];
+	    }
+	}
+	
+	export class RecentPaths {
+	    zipPaths: string[];
+	    workDirs: string[];
+	    outputDirs: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RecentPaths(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.zipPaths = source["zipPaths"];
+	        this.workDirs = source["workDirs"];
+	        this.outputDirs = source["outputDirs"];
+	    }
+	}
+	
+	export class ResultFilter {
+	    types: string[];
+	    query: string;
+	    paths: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ResultFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.types = source["types"];
+	        this.query = source["query"];
+	        this.paths = source["paths"];
+	    }
+	}
+	export class ResultPage {
+	    resultId: string;
+	    total: number;
+	    offset: number;
+	    items: DiffItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ResultPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.resultId = source["resultId"];
+	        this.total = source["total"];
+	        this.offset = source["offset"];
+	        this.items = this.convertValues(source["items"], DiffItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SharedRuleSet {
+	    source: string;
+	    fetchedAt: string;
+	    rules: ExcludeRule[];
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SharedRuleSet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.fetchedAt = source["fetchedAt"];
+	        this.rules = this.convertValues(source["rules"], ExcludeRule);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class SnapshotInfo {
+	    path: string;
+	    files: number;
+	    takenAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.files = source["files"];
+	        this.takenAt = source["takenAt"];
+	    }
+	}
+	export class TempUsage {
+	    dir: string;
+	    files: number;
+	    bytes: number;
+	    inUse: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TempUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.files = source["files"];
+	        this.bytes = source["bytes"];
+	        this.inUse = source["inUse"];
+	    }
+	}
+	export class TextFileAudit {
+	    relPath: string;
+	    type: string;
+	    oldEol: string;
+	    newEol: string;
+	    eolChanged: boolean;
+	    oldEncoding: string;
+	    newEncoding: string;
+	    encodingChanged: boolean;
+	    encodingOnly: boolean;
+	    policyViolation: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TextFileAudit(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.type = source["type"];
+	        this.oldEol = source["oldEol"];
+	        this.newEol = source["newEol"];
+	        this.eolChanged = source["eolChanged"];
+	        this.oldEncoding = source["oldEncoding"];
+	        this.newEncoding = source["newEncoding"];
+	        this.encodingChanged = source["encodingChanged"];
+	        this.encodingOnly = source["encodingOnly"];
+	        this.policyViolation = source["policyViolation"];
 	    }
 	}
+	export class TextAuditReport {
+	    files: TextFileAudit[];
+	    lf: number;
+	    crlf: number;
+	    mixed: number;
+	    eolChanged: number;
+	    encodingChanged: number;
+	    encodingOnly: number;
+	    violations: number;
 	
+	    static createFrom(source: any = {}) {
+	        return new TextAuditReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = this.convertValues(source["files"], TextFileAudit);
+	        this.lf = source["lf"];
+	        this.crlf = source["crlf"];
+	        this.mixed = source["mixed"];
+	        this.eolChanged = source["eolChanged"];
+	        this.encodingChanged = source["encodingChanged"];
+	        this.encodingOnly = source["encodingOnly"];
+	        this.violations = source["violations"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class TextDiff {
 	    oldContent: string;
 	    newContent: string;
 	    lines: DiffLine[];
+	    truncated: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new TextDiff(source);
@@ -142,6 +937,7 @@ export namespace models {
 	        this.oldContent = source["oldContent"];
 	        this.newContent = source["newContent"];
 	        this.lines = this.convertValues(source["lines"], DiffLine);
+	        this.truncated = source["truncated"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -162,6 +958,45 @@ export namespace models {
 		    return a;
 		}
 	}
+	
+	export class TicketRef {
+	    id: string;
+	    title: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TicketRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	    }
+	}
+	export class UpdateInfo {
+	    current: string;
+	    latest: string;
+	    available: boolean;
+	    notes: string;
+	    url: string;
+	    stagedPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current = source["current"];
+	        this.latest = source["latest"];
+	        this.available = source["available"];
+	        this.notes = source["notes"];
+	        this.url = source["url"];
+	        this.stagedPath = source["stagedPath"];
+	    }
+	}
 
 }
 
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"Discrepancies/internal/apperr"
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/ipc"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/platform"
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 注册和取消注册协议及右键菜单的命令行参数，处理后直接退出，供安装脚本使用
+const (
+	registerShellFlag   = "--register-shell"
+	unregisterShellFlag = "--unregister-shell"
+)
+
+// parseOpenArgs 从命令行参数中识别 ZIP 文件（含 APK、IPA、VSIX）和工作目录，相对路径基于 cwd 解析
+// discrepancies:// 链接按 parseOpenURL 解析
+func parseOpenArgs(args []string, cwd string) models.OpenRequest {
+	var req models.OpenRequest
+	for _, arg := range args {
+		if arg == "" || strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(arg), platform.URLScheme+":") {
+			req = parseOpenURL(arg)
+			continue
+		}
+		path := arg
+		if !filepath.IsAbs(path) && cwd != "" {
+			path = filepath.Join(cwd, path)
+		}
+		classifyOpenPath(&req, path)
+	}
+	return req
+}
+
+// parseOpenURL 解析 discrepancies://compare?zip=…&dir=…&run=1 形式的链接，run=1 且两个路径都有效时打开后立即比较
+// 链接可能来自网页，只接受本机的绝对路径：不访问网络共享（UNC 路径），避免打开链接时向外部服务器发起认证
+func parseOpenURL(raw string) models.OpenRequest {
+	var req models.OpenRequest
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(u.Scheme, platform.URLScheme) {
+		return req
+	}
+	action := cmp.Or(u.Host, strings.Trim(u.Opaque, "/"), strings.Trim(u.Path, "/"))
+	if !strings.EqualFold(action, "compare") {
+		return req
+	}
+
+	query := u.Query()
+	for _, path := range []string{query.Get("zip"), query.Get("dir")} {
+		if isLocalAbsPath(path) {
+			classifyOpenPath(&req, filepath.Clean(path))
+		}
+	}
+	req.Compare = query.Get("run") == "1" && req.ZipPath != "" && req.WorkDir != ""
+	return req
+}
+
+// isLocalAbsPath 判断路径是否是本机的绝对路径（不是 \\server\share 或 //server/share 形式的网络路径）
+func isLocalAbsPath(path string) bool {
+	return filepath.IsAbs(path) && !strings.HasPrefix(path, `\\`) && !strings.HasPrefix(path, "//")
+}
+
+// classifyOpenPath 按路径类型填入请求：目录为工作目录，ZIP 文件（含 APK、IPA、VSIX）为基准，不存在的路径忽略
+func classifyOpenPath(req *models.OpenRequest, path string) {
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		return
+	case info.IsDir():
+		req.WorkDir = path
+	case strings.EqualFold(filepath.Ext(path), ".zip") || compare.ContainerKind(path) != "":
+		req.ZipPath = path
+	}
+}
+
+// handleSecondInstance 处理后启动的实例转交的参数：激活窗口并通知前端打开对应路径
+func (a *App) handleSecondInstance(msg ipc.Message) {
+	req := parseOpenArgs(msg.Args, msg.WorkDir)
+
+	a.mu.Lock()
+	ctx := a.ctx
+	if ctx == nil {
+		// 界面尚未启动，留给前端启动后获取
+		a.launch = req
+	}
+	a.mu.Unlock()
+	if ctx == nil {
+		return
+	}
+
+	runtime.WindowUnminimise(ctx)
+	runtime.WindowShow(ctx)
+	if req.ZipPath != "" || req.WorkDir != "" {
+		runtime.EventsEmit(ctx, "backend:open", req)
+	}
+}
+
+// GetLaunchRequest 返回启动时通过命令行或文件关联传入的路径（仅返回一次）
+func (a *App) GetLaunchRequest() models.OpenRequest {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	req := a.launch
+	a.launch = models.OpenRequest{}
+	return req
+}
+
+// handleOpenURL 处理 macOS 通过系统事件（而不是命令行参数）传入的 discrepancies:// 链接
+func (a *App) handleOpenURL(rawURL string) {
+	a.handleSecondInstance(ipc.Message{Args: []string{rawURL}})
+}
+
+// GetShellIntegration 返回 discrepancies:// 协议和 ZIP 右键菜单是否已注册到当前程序
+func (a *App) GetShellIntegration() bool {
+	return platform.ShellRegistered()
+}
+
+// SetShellIntegration 为当前用户注册或取消注册 discrepancies:// 协议和 ZIP 右键菜单「用 Discrepancies 比较」
+func (a *App) SetShellIntegration(enabled bool) error {
+	if config.ReadOnly() {
+		return apperr.ErrReadOnly
+	}
+	var err error
+	if enabled {
+		err = platform.RegisterShell()
+	} else {
+		err = platform.UnregisterShell()
+	}
+	return appError(err)
+}
+
+// runShellFlag 处理 --register-shell 和 --unregister-shell，返回进程退出码
+func runShellFlag(flag string) int {
+	var err error
+	if flag == registerShellFlag {
+		err = platform.RegisterShell()
+	} else {
+		err = platform.UnregisterShell()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+// Package apperr 定义 App 接口返回给前端的错误模型
+// 每个错误都有稳定的错误码，前端据此处理和本地化，而不依赖错误消息文本
+package apperr
+
+import (
+	"Discrepancies/internal/models"
+	"errors"
+)
+
+// Error 带错误码的应用错误
+type Error struct {
+	Code    string // 稳定的错误码
+	Message string // 默认的用户提示（中文）
+	Detail  string // 补充信息，如相关的路径
+	Path    string // 出错的差异文件（相对路径），前端据此标记对应的行
+	cause   error
+}
+
+// 预定义的错误，使用 errors.Is 按错误码比较
+var (
+	ErrInvalidArgument  = &Error{Code: "INVALID_ARGUMENT", Message: "参数无效"}
+	ErrZipNotFound      = &Error{Code: "ZIP_NOT_FOUND", Message: "ZIP 文件不存在"}
+	ErrZipInvalid       = &Error{Code: "ZIP_INVALID", Message: "无法读取 ZIP 文件"}
+	ErrEncrypted        = &Error{Code: "ENCRYPTED", Message: "ZIP 文件已加密，暂不支持比较"}
+	ErrWorkDirMissing   = &Error{Code: "WORKDIR_MISSING", Message: "工作目录不存在"}
+	ErrNotGitRepo       = &Error{Code: "NOT_GIT_REPO", Message: "工作目录不是 git 仓库"}
+	ErrCancelled        = &Error{Code: "CANCELLED", Message: "操作已取消"}
+	ErrNotInitialized   = &Error{Code: "NOT_INITIALIZED", Message: "配置管理器未初始化"}
+	ErrNoResult         = &Error{Code: "NO_RESULT", Message: "请先进行比较"}
+	ErrNothingSelected  = &Error{Code: "NOTHING_SELECTED", Message: "没有选中的文件"}
+	ErrUnsupportedFile  = &Error{Code: "UNSUPPORTED_FILE", Message: "不支持预览非文本文件"}
+	ErrFileLocked       = &Error{Code: "FILE_LOCKED", Message: "文件被其他程序占用，请关闭后重试"}
+	ErrPermissionDenied = &Error{Code: "PERMISSION_DENIED", Message: "没有写入权限，可尝试以管理员身份导出"}
+	ErrElevationFailed  = &Error{Code: "ELEVATION_FAILED", Message: "以管理员身份运行失败"}
+	ErrNoRules          = &Error{Code: "NO_RULES", Message: "文件中没有可导入的规则"}
+	ErrVcsFailed        = &Error{Code: "VCS_FAILED", Message: "版本控制操作失败"}
+	ErrDeltaConflict    = &Error{Code: "DELTA_CONFLICT", Message: "目标目录与差分包的旧版本不一致"}
+	ErrDeltaInvalid     = &Error{Code: "DELTA_INVALID", Message: "差分包无效或已损坏"}
+	ErrNoSigningKey     = &Error{Code: "NO_SIGNING_KEY", Message: "尚未生成签名密钥"}
+	ErrNoSnapshot       = &Error{Code: "NO_SNAPSHOT", Message: "工作目录还没有记录快照"}
+	ErrFileFailed       = &Error{Code: "FILE_FAILED", Message: "文件处理失败"}
+	ErrVerifyFailed     = &Error{Code: "VERIFY_FAILED", Message: "导出的文件与源文件不一致，可能在写入时损坏，请重新导出"}
+	ErrRemoteFailed     = &Error{Code: "REMOTE_FAILED", Message: "写入远程目标失败"}
+	ErrExternalTool     = &Error{Code: "EXTERNAL_TOOL", Message: "无法启动外部比较工具，请检查配置中的命令"}
+	ErrReadOnly         = &Error{Code: "READ_ONLY", Message: "只读模式下不能写入文件"}
+	ErrSharedRules      = &Error{Code: "SHARED_RULES", Message: "获取团队共享规则失败，继续使用缓存的规则"}
+	ErrUpdateFailed     = &Error{Code: "UPDATE_FAILED", Message: "检查更新失败"}
+	ErrInternal         = &Error{Code: "INTERNAL", Message: "内部错误"}
+)
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	msg := e.Message
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+	return msg
+}
+
+// Unwrap 返回底层错误
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 按错误码判断是否是同一类错误
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// WithDetail 返回附带补充信息的错误副本
+func (e *Error) WithDetail(detail string) *Error {
+	c := *e
+	c.Detail = detail
+	return &c
+}
+
+// WithPath 返回标记了出错文件的错误副本
+func (e *Error) WithPath(path string) *Error {
+	c := *e
+	c.Path = path
+	return &c
+}
+
+// WithMessage 返回使用指定提示的错误副本
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// Wrap 返回包装了底层错误的副本
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.cause = err
+	return &c
+}
+
+// Format 将错误转换为前端可识别的结构，作为 Wails 的 ErrorFormatter 使用
+// 未分类的错误统一使用 INTERNAL 错误码，消息为原始错误文本
+func Format(err error) any {
+	var e *Error
+	if !errors.As(err, &e) {
+		return models.APIError{Code: ErrInternal.Code, Message: err.Error()}
+	}
+
+	detail := e.Detail
+	if e.cause != nil {
+		if detail != "" {
+			detail += ": "
+		}
+		detail += e.cause.Error()
+	}
+	return models.APIError{Code: e.Code, Message: e.Message, Detail: detail, Path: e.Path}
+}
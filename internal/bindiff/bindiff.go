@@ -0,0 +1,272 @@
+package bindiff
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// magic 补丁文件头
+const magic = "DSCPATCH1"
+
+// MaxFileSize 生成补丁时新旧文件都需读入内存，超过该大小的文件不生成补丁
+const MaxFileSize = 512 << 20
+
+// minBlockSize 块匹配的最小块大小，旧文件较大时按索引条目数上限放大
+const minBlockSize = 32
+
+// maxIndexBlocks 旧文件块索引的条目数上限
+const maxIndexBlocks = 1 << 22
+
+// 补丁操作
+const (
+	opCopy   = 'C' // 从旧文件复制：偏移、长度
+	opInsert = 'I' // 插入补丁中的数据：长度、数据
+	opEnd    = 'E' // 结束
+)
+
+// ErrCorrupt 补丁格式错误或与旧文件不匹配
+var ErrCorrupt = errors.New("corrupt binary patch")
+
+// blockRef 旧文件中一个块的弱校验和及其偏移
+type blockRef struct {
+	hash   uint32
+	offset uint32
+}
+
+// Diff 生成从 oldData 到 newData 的二进制补丁
+// 以 rsync 弱校验和索引旧文件的块，在新文件中滚动查找匹配并向两端扩展，
+// 匹配部分记录为复制操作，其余为插入数据，整体以 deflate 压缩
+func Diff(oldData, newData []byte, w io.Writer) error {
+	if len(oldData) > MaxFileSize || len(newData) > MaxFileSize {
+		return fmt.Errorf("file too large for binary patch: %d bytes", max(len(oldData), len(newData)))
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return err
+	}
+	if err := writeUvarint(bw, uint64(len(newData))); err != nil {
+		return err
+	}
+
+	fw, err := flate.NewWriter(bw, flate.BestCompression)
+	if err != nil {
+		return err
+	}
+	e := &encoder{w: bufio.NewWriter(fw)}
+
+	block := max(minBlockSize, len(oldData)/maxIndexBlocks+1)
+	index := buildIndex(oldData, block)
+
+	literal, pos := 0, 0
+	var sum rollingSum
+	if len(newData) >= block {
+		sum.init(newData[:block])
+	}
+	for pos+block <= len(newData) {
+		if offset, ok := findBlock(index, sum.value(), oldData, newData[pos:pos+block]); ok {
+			// 向前扩展到上一次操作的末尾，向后扩展到不再相同
+			start, oldStart := pos, offset
+			for start > literal && oldStart > 0 && oldData[oldStart-1] == newData[start-1] {
+				start--
+				oldStart--
+			}
+			end, oldEnd := pos+block, offset+block
+			for end < len(newData) && oldEnd < len(oldData) && oldData[oldEnd] == newData[end] {
+				end++
+				oldEnd++
+			}
+
+			e.insert(newData[literal:start])
+			e.copy(oldStart, end-start)
+			literal, pos = end, end
+			if pos+block <= len(newData) {
+				sum.init(newData[pos : pos+block])
+			}
+			continue
+		}
+
+		if pos+block < len(newData) {
+			sum.roll(newData[pos], newData[pos+block], block)
+		}
+		pos++
+	}
+	e.insert(newData[literal:])
+	e.end()
+
+	if e.err != nil {
+		return e.err
+	}
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Apply 将补丁应用到旧文件，结果写入 w
+func Apply(old io.ReaderAt, patch io.Reader, w io.Writer) error {
+	br := bufio.NewReader(patch)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != magic {
+		return ErrCorrupt
+	}
+	newSize, err := binary.ReadUvarint(br)
+	if err != nil {
+		return ErrCorrupt
+	}
+
+	fr := bufio.NewReader(flate.NewReader(br))
+	var written uint64
+	for {
+		op, err := fr.ReadByte()
+		if err != nil {
+			return ErrCorrupt
+		}
+
+		switch op {
+		case opCopy:
+			offset, err1 := binary.ReadUvarint(fr)
+			length, err2 := binary.ReadUvarint(fr)
+			if err1 != nil || err2 != nil || !fits(offset, length, written, newSize) {
+				return ErrCorrupt
+			}
+			n, err := io.Copy(w, io.NewSectionReader(old, int64(offset), int64(length)))
+			if err != nil {
+				return err
+			}
+			if uint64(n) != length {
+				return ErrCorrupt
+			}
+			written += length
+		case opInsert:
+			length, err := binary.ReadUvarint(fr)
+			if err != nil || !fits(0, length, written, newSize) {
+				return ErrCorrupt
+			}
+			n, err := io.CopyN(w, fr, int64(length))
+			if err != nil || uint64(n) != length {
+				return ErrCorrupt
+			}
+			written += length
+		case opEnd:
+			if written != newSize {
+				return ErrCorrupt
+			}
+			return nil
+		default:
+			return ErrCorrupt
+		}
+	}
+}
+
+// fits 检查操作的偏移和长度能转换为 int64，且写入后不超过补丁头中的新文件大小
+func fits(offset, length, written, newSize uint64) bool {
+	return offset <= math.MaxInt64 && length <= math.MaxInt64 && length <= newSize-written
+}
+
+// buildIndex 按块计算旧文件的弱校验和，按校验和排序
+func buildIndex(data []byte, block int) []blockRef {
+	index := make([]blockRef, 0, len(data)/block)
+	var sum rollingSum
+	for offset := 0; offset+block <= len(data); offset += block {
+		sum.init(data[offset : offset+block])
+		index = append(index, blockRef{hash: sum.value(), offset: uint32(offset)})
+	}
+	sort.Slice(index, func(i, j int) bool {
+		if index[i].hash != index[j].hash {
+			return index[i].hash < index[j].hash
+		}
+		return index[i].offset < index[j].offset
+	})
+	return index
+}
+
+// findBlock 查找与 target 内容相同的旧文件块，返回其偏移
+func findBlock(index []blockRef, hash uint32, oldData, target []byte) (int, bool) {
+	i := sort.Search(len(index), func(i int) bool { return index[i].hash >= hash })
+	for ; i < len(index) && index[i].hash == hash; i++ {
+		offset := int(index[i].offset)
+		if bytes.Equal(oldData[offset:offset+len(target)], target) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// rollingSum rsync 弱校验和，可逐字节滑动窗口
+type rollingSum struct {
+	a, b uint32
+}
+
+func (s *rollingSum) init(window []byte) {
+	s.a, s.b = 0, 0
+	n := uint32(len(window))
+	for i, c := range window {
+		s.a += uint32(c)
+		s.b += (n - uint32(i)) * uint32(c)
+	}
+}
+
+// roll 移出窗口首字节 out，移入 in
+func (s *rollingSum) roll(out, in byte, block int) {
+	s.a += uint32(in) - uint32(out)
+	s.b += s.a - uint32(block)*uint32(out)
+}
+
+func (s *rollingSum) value() uint32 {
+	return s.a&0xffff | s.b<<16
+}
+
+// encoder 写入补丁操作，记录第一个错误
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) insert(data []byte) {
+	if len(data) == 0 || e.err != nil {
+		return
+	}
+	e.err = e.w.WriteByte(opInsert)
+	if e.err == nil {
+		e.err = writeUvarint(e.w, uint64(len(data)))
+	}
+	if e.err == nil {
+		_, e.err = e.w.Write(data)
+	}
+}
+
+func (e *encoder) copy(offset, length int) {
+	if e.err != nil {
+		return
+	}
+	e.err = e.w.WriteByte(opCopy)
+	if e.err == nil {
+		e.err = writeUvarint(e.w, uint64(offset))
+	}
+	if e.err == nil {
+		e.err = writeUvarint(e.w, uint64(length))
+	}
+}
+
+func (e *encoder) end() {
+	if e.err == nil {
+		e.err = e.w.WriteByte(opEnd)
+	}
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	_, err := w.Write(buf[:binary.PutUvarint(buf[:], v)])
+	return err
+}
@@ -0,0 +1,153 @@
+package bindiff
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// roundTrip 生成补丁并应用到旧文件，检查结果与新文件一致，返回补丁大小
+func roundTrip(t *testing.T, oldData, newData []byte) int {
+	t.Helper()
+	var patch bytes.Buffer
+	if err := Diff(oldData, newData, &patch); err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	size := patch.Len()
+
+	var out bytes.Buffer
+	if err := Apply(bytes.NewReader(oldData), &patch, &out); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), newData) {
+		t.Fatalf("round trip mismatch: got %d bytes, want %d", out.Len(), len(newData))
+	}
+	return size
+}
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	data := make([]byte, n)
+	r.Read(data)
+	return data
+}
+
+func TestRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	base := randomBytes(r, 64<<10)
+
+	tests := []struct {
+		name     string
+		old, new []byte
+	}{
+		{"both empty", nil, nil},
+		{"old empty", nil, []byte("new content")},
+		{"new empty", base, nil},
+		{"identical", base, base},
+		{"shorter than a block", []byte("abc"), []byte("abd")},
+		{"insert in middle", base, append(append(append([]byte{}, base[:30000]...), "inserted"...), base[30000:]...)},
+		{"delete in middle", base, append(append([]byte{}, base[:20000]...), base[40000:]...)},
+		{"append", base, append(append([]byte{}, base...), randomBytes(r, 1000)...)},
+		{"prepend", base, append(randomBytes(r, 1000), base...)},
+		{"unrelated", base, randomBytes(r, 64<<10)},
+		{"repeated blocks", bytes.Repeat([]byte("0123456789abcdef"), 4096), bytes.Repeat([]byte("0123456789abcdeF"), 4096)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roundTrip(t, tt.old, tt.new)
+		})
+	}
+}
+
+func TestRoundTripRandomEdits(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 20; i++ {
+		oldData := randomBytes(r, 1+r.Intn(200<<10))
+		newData := append([]byte{}, oldData...)
+		for j := 0; j < 1+r.Intn(10); j++ {
+			pos := r.Intn(len(newData) + 1)
+			switch r.Intn(3) {
+			case 0: // 插入
+				newData = append(newData[:pos], append(randomBytes(r, r.Intn(500)), newData[pos:]...)...)
+			case 1: // 删除
+				end := min(len(newData), pos+r.Intn(500))
+				newData = append(newData[:pos], newData[end:]...)
+			default: // 覆盖
+				copy(newData[pos:], randomBytes(r, r.Intn(500)))
+			}
+		}
+		roundTrip(t, oldData, newData)
+	}
+}
+
+func TestPatchSmallForSmallChange(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	oldData := randomBytes(r, 1<<20)
+	newData := append([]byte{}, oldData...)
+	copy(newData[500000:], "changed")
+
+	if size := roundTrip(t, oldData, newData); size > 4096 {
+		t.Errorf("patch for a 7-byte change in 1 MB is %d bytes", size)
+	}
+}
+
+func TestApplyCorrupt(t *testing.T) {
+	oldData := bytes.Repeat([]byte("old file content "), 1000)
+	newData := append([]byte("prefix "), oldData...)
+	var patch bytes.Buffer
+	if err := Diff(oldData, newData, &patch); err != nil {
+		t.Fatal(err)
+	}
+	valid := patch.Bytes()
+
+	tests := []struct {
+		name  string
+		patch []byte
+		old   []byte
+	}{
+		{"empty", nil, oldData},
+		{"bad magic", append([]byte("XXXXXXXXX"), valid[len(magic):]...), oldData},
+		{"truncated", valid[:len(valid)/2], oldData},
+		{"old file too short", valid, oldData[:100]},
+		{"copy past new size", craftPatch(10, opCopy, 0, 100, opEnd), oldData},
+		{"insert past new size", craftPatch(3, opInsert, 5, 'a', 'b', 'c', 'd', 'e', opEnd), oldData},
+		{"offset overflows int64", craftPatch(1<<63+10, opCopy, 1<<63, 10, opEnd), oldData},
+		{"length overflows int64", craftPatch(1<<64-1, opInsert, 1<<63, opEnd), oldData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Apply(bytes.NewReader(tt.old), bytes.NewReader(tt.patch), &bytes.Buffer{})
+			if !errors.Is(err, ErrCorrupt) {
+				t.Errorf("Apply = %v, want ErrCorrupt", err)
+			}
+		})
+	}
+}
+
+func TestApplyStopsAtNewSize(t *testing.T) {
+	oldData := bytes.Repeat([]byte("x"), 1000)
+	var out bytes.Buffer
+	err := Apply(bytes.NewReader(oldData), bytes.NewReader(craftPatch(10, opCopy, 0, 1000, opEnd)), &out)
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Apply = %v, want ErrCorrupt", err)
+	}
+	if out.Len() > 10 {
+		t.Errorf("Apply wrote %d bytes past the declared size of 10", out.Len())
+	}
+}
+
+// craftPatch 按给定的新文件大小和操作序列构造补丁
+// 操作码和插入的数据均小于 0x80，与参数一样按 uvarint 写入时正好是单个字节
+func craftPatch(newSize uint64, ops ...uint64) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	buf.Write(binary.AppendUvarint(nil, newSize))
+	fw, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	for _, op := range ops {
+		fw.Write(binary.AppendUvarint(nil, op))
+	}
+	fw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,263 @@
+package compare
+
+import (
+	"Discrepancies/internal/bindiff"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/platform"
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrDeltaConflict 目标目录中的文件与差分包的旧版本不一致
+var ErrDeltaConflict = errors.New("target does not match delta baseline")
+
+// ErrDeltaInvalid 差分包缺少索引或内容与索引不符
+var ErrDeltaInvalid = errors.New("invalid delta package")
+
+// ErrDeltaLocked 需要修改的目标文件被其他进程占用
+var ErrDeltaLocked = errors.New("delta targets are locked")
+
+// 校验目标文件的结果
+const (
+	stateOld      = iota // 与旧版本一致，需要应用
+	stateNew             // 已是新版本，跳过
+	stateConflict        // 与两个版本都不一致
+)
+
+// ApplyDelta 将差分包应用到与旧基准一致的目标目录
+// 先校验所有文件，存在冲突时不做任何修改并返回 ErrDeltaConflict；需要修改的文件被占用时同样不做修改，返回 ErrDeltaLocked。
+// 然后将所有新版本写入目标旁的临时文件并校验 MD5，全部成功后才逐个替换目标文件、删除文件；
+// 替换中途失败时报告中的 Applied 列出已修改的文件。dryRun 时只校验
+func ApplyDelta(deltaPath, targetDir string, dryRun bool, onProgress func(current, total int, message string)) (*models.DeltaApplyReport, error) {
+	reader, err := zip.OpenReader(deltaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip file: %w", err)
+	}
+	defer reader.Close()
+
+	entries := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		entries[f.Name] = f
+	}
+	index, err := readDeltaIndex(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DeltaApplyReport{Conflicts: make([]models.PathIssue, 0), DryRun: dryRun}
+	states := make([]int, len(index.Files))
+	for i, file := range index.Files {
+		if onProgress != nil {
+			onProgress(i+1, len(index.Files), fmt.Sprintf("校验: %s", file.Path))
+		}
+		if !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return nil, fmt.Errorf("%w: unsafe path %s", ErrDeltaInvalid, file.Path)
+		}
+		switch file.Action {
+		case deltaAdd, deltaReplace, deltaPatch:
+			if file.Entry == "" || entries[file.Entry] == nil {
+				return nil, fmt.Errorf("%w: missing entry %q for %s", ErrDeltaInvalid, file.Entry, file.Path)
+			}
+		case deltaDelete:
+		default:
+			return nil, fmt.Errorf("%w: unknown action %q for %s", ErrDeltaInvalid, file.Action, file.Path)
+		}
+
+		state, reason := checkDeltaTarget(filepath.Join(targetDir, filepath.FromSlash(file.Path)), file)
+		states[i] = state
+		switch state {
+		case stateOld:
+			report.Pending++
+		case stateNew:
+			report.Skipped++
+		case stateConflict:
+			report.Conflicts = append(report.Conflicts, models.PathIssue{Path: file.Path, Reason: reason})
+		}
+	}
+	if len(report.Conflicts) > 0 {
+		return report, ErrDeltaConflict
+	}
+
+	// 已存在的目标（替换、补丁、删除）被占用时，替换到一半才失败会留下部分更新的目录
+	existing := make([]string, 0, report.Pending)
+	for i, file := range index.Files {
+		if states[i] == stateOld && file.Action != deltaAdd {
+			existing = append(existing, filepath.Join(targetDir, filepath.FromSlash(file.Path)))
+		}
+	}
+	if report.Locked = platform.FindLockedFiles(existing); len(report.Locked) > 0 {
+		return report, ErrDeltaLocked
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	// 先写出所有新版本，任一文件失败时删除临时文件，目标目录保持不变
+	staged := make(map[int]string, report.Pending)
+	removeStaged := func() {
+		for _, tmp := range staged {
+			os.Remove(tmp)
+		}
+	}
+	for i, file := range index.Files {
+		if states[i] != stateOld || file.Action == deltaDelete {
+			continue
+		}
+		if onProgress != nil {
+			onProgress(i+1, len(index.Files), fmt.Sprintf("写入: %s", file.Path))
+		}
+		tmp, err := stageDeltaFile(entries[file.Entry], filepath.Join(targetDir, filepath.FromSlash(file.Path)), file)
+		if err != nil {
+			removeStaged()
+			return report, fmt.Errorf("failed to apply %s: %w", file.Path, err)
+		}
+		staged[i] = tmp
+	}
+
+	for i, file := range index.Files {
+		if states[i] != stateOld {
+			continue
+		}
+		if onProgress != nil {
+			onProgress(i+1, len(index.Files), fmt.Sprintf("应用: %s", file.Path))
+		}
+
+		target := filepath.Join(targetDir, filepath.FromSlash(file.Path))
+		if file.Action == deltaDelete {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				removeStaged()
+				return report, fmt.Errorf("failed to delete %s: %w", file.Path, err)
+			}
+			report.Deleted++
+			report.Applied = append(report.Applied, file.Path)
+			continue
+		}
+
+		if err := os.Rename(staged[i], target); err != nil {
+			removeStaged()
+			return report, fmt.Errorf("failed to apply %s: %w", file.Path, err)
+		}
+		delete(staged, i)
+		report.Applied = append(report.Applied, file.Path)
+		report.Verified++
+		switch file.Action {
+		case deltaAdd:
+			report.Added++
+		case deltaPatch:
+			report.Patched++
+		default:
+			report.Replaced++
+		}
+	}
+	return report, nil
+}
+
+// readDeltaIndex 读取差分包索引
+func readDeltaIndex(entries map[string]*zip.File) (*deltaIndex, error) {
+	f := entries[DeltaIndexName]
+	if f == nil {
+		return nil, fmt.Errorf("%w: missing %s", ErrDeltaInvalid, DeltaIndexName)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var index deltaIndex
+	if err := json.NewDecoder(rc).Decode(&index); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDeltaInvalid, err)
+	}
+	return &index, nil
+}
+
+// checkDeltaTarget 校验目标文件是旧版本、新版本还是冲突，冲突时返回原因
+func checkDeltaTarget(target string, file deltaFile) (int, string) {
+	hash, err := localFileHash(target)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return stateConflict, err.Error()
+	}
+
+	switch {
+	case file.Action == deltaAdd && !exists:
+		return stateOld, ""
+	case file.Action == deltaDelete && !exists:
+		return stateNew, ""
+	case !exists:
+		return stateConflict, "文件不存在"
+	case file.Action != deltaAdd && hash == file.OldHash:
+		return stateOld, ""
+	case file.Action != deltaDelete && hash == file.NewHash:
+		return stateNew, ""
+	case file.Action == deltaAdd:
+		return stateConflict, "文件已存在且内容不同"
+	}
+	return stateConflict, "内容与旧版本不一致"
+}
+
+// stageDeltaFile 将新版本写入目标旁的临时文件并校验 MD5，返回临时文件路径，由调用方替换目标文件
+func stageDeltaFile(entry *zip.File, target string, file deltaFile) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".discrepancies-*.tmp")
+	if err != nil {
+		return "", err
+	}
+
+	hash := md5.New()
+	err = writeDeltaContent(entry, target, file, io.MultiWriter(tmp, hash))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && hex.EncodeToString(hash.Sum(nil)) != file.NewHash {
+		err = fmt.Errorf("%w: checksum mismatch after writing", ErrDeltaInvalid)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	if info, err := os.Stat(target); err == nil {
+		os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	return tmp.Name(), nil
+}
+
+// writeDeltaContent 写出新版本内容：补丁应用到目标文件的旧版本，其余直接解压
+func writeDeltaContent(entry *zip.File, target string, file deltaFile, w io.Writer) error {
+	rc, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if file.Action != deltaPatch {
+		_, err := io.Copy(w, rc)
+		return err
+	}
+
+	old, err := os.Open(target)
+	if err != nil {
+		return err
+	}
+	defer old.Close()
+	return bindiff.Apply(old, rc, w)
+}
+
+// localFileHash 计算本地文件的 MD5（十六进制）
+func localFileHash(path string) (string, error) {
+	hash, _, err := fileHash(path)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash), nil
+}
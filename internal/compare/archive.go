@@ -3,12 +3,19 @@ package compare
 import (
 	"archive/zip"
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
 )
 
+// encryptedFlag 通用标志位第 0 位，表示条目已加密
+const encryptedFlag = 0x1
+
+// ErrEncrypted ZIP 中包含加密的条目，无法读取内容
+var ErrEncrypted = errors.New("zip archive contains encrypted entries")
+
 // ZipEntry 表示 ZIP 文件中的一个条目
 type ZipEntry struct {
 	RelPath string // 相对路径
@@ -18,17 +25,43 @@ type ZipEntry struct {
 
 // ZipReader 封装 ZIP 读取操作
 type ZipReader struct {
-	path   string
-	reader *zip.ReadCloser
+	path      string
+	reader    *zip.ReadCloser
+	encoding  string // 实际使用的文件名编码
+	maxDepth  int    // 列出的最大层数，0 表示不限制
+	container string // 容器类型（apk、ipa、vsix），容器的目录结构固定，不去除根目录
 }
 
-// NewZipReader 创建新的 ZIP 读取器
+// NewZipReader 创建新的 ZIP 读取器，自动识别文件名编码
 func NewZipReader(zipPath string) (*ZipReader, error) {
+	return NewZipReaderWithEncoding(zipPath, FilenameEncodingAuto)
+}
+
+// NewZipReaderWithEncoding 创建新的 ZIP 读取器，按指定编码解码未标记 UTF-8 的文件名
+// encoding 可为 auto、utf-8、shift-jis、gbk、cp437
+func NewZipReaderWithEncoding(zipPath, encoding string) (*ZipReader, error) {
 	reader, err := zip.OpenReader(zipPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open zip file: %w", err)
 	}
-	return &ZipReader{path: zipPath, reader: reader}, nil
+	for _, f := range reader.File {
+		if f.Flags&encryptedFlag != 0 {
+			reader.Close()
+			return nil, ErrEncrypted
+		}
+	}
+
+	resolved, err := decodeZipNames(reader.File, encoding)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+	return &ZipReader{path: zipPath, reader: reader, encoding: resolved, container: ContainerKind(zipPath)}, nil
+}
+
+// FilenameEncoding 返回解码文件名实际使用的编码（auto 时为识别结果）
+func (z *ZipReader) FilenameEncoding() string {
+	return z.encoding
 }
 
 // Close 关闭 ZIP 读取器
@@ -40,9 +73,9 @@ func (z *ZipReader) Close() error {
 }
 
 // GetRootFolder 获取 ZIP 中的根文件夹名称
-// 通常 ZIP 文件会有一个根目录，例如 project-v1.0/
+// 通常 ZIP 文件会有一个根目录，例如 project-v1.0/；APK、IPA、VSIX 等容器返回空字符串
 func (z *ZipReader) GetRootFolder() string {
-	if len(z.reader.File) == 0 {
+	if len(z.reader.File) == 0 || z.container != "" {
 		return ""
 	}
 
@@ -74,7 +107,7 @@ func (z *ZipReader) ListFiles() (map[string]*zip.File, error) {
 
 		// 统一使用正斜杠
 		relPath = filepath.ToSlash(relPath)
-		if relPath != "" {
+		if relPath != "" && z.withinDepth(relPath) {
 			files[relPath] = f
 		}
 	}
@@ -100,7 +133,7 @@ func (z *ZipReader) ListDirs() (map[string]bool, error) {
 		}
 
 		relPath = filepath.ToSlash(relPath)
-		if relPath != "" {
+		if relPath != "" && z.withinDepth(relPath) {
 			dirs[relPath] = true
 		}
 	}
@@ -160,6 +193,56 @@ func (z *ZipReader) ReadFileContent(relPath string) ([]byte, error) {
 	return content, nil
 }
 
+// ExtractFile 将 ZIP 中指定文件的内容写入 destPath，上级目录不存在时创建
+func (z *ZipReader) ExtractFile(relPath, destPath string) error {
+	files, err := z.ListFiles()
+	if err != nil {
+		return err
+	}
+
+	f, exists := files[relPath]
+	if !exists {
+		return fmt.Errorf("file not found in zip: %s", relPath)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open file in zip: %w", err)
+	}
+	defer rc.Close()
+
+	if err := writeFile(rc, destPath); err != nil {
+		return fmt.Errorf("failed to extract file: %w", err)
+	}
+	return nil
+}
+
+// ReadFileHead 读取 ZIP 中指定文件开头最多 limit 字节的内容，同时返回文件的完整大小
+func (z *ZipReader) ReadFileHead(relPath string, limit int64) ([]byte, int64, error) {
+	files, err := z.ListFiles()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, exists := files[relPath]
+	if !exists {
+		return nil, 0, fmt.Errorf("file not found in zip: %s", relPath)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file in zip: %w", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, limit))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read file content: %w", err)
+	}
+
+	return content, int64(f.UncompressedSize64), nil
+}
+
 // GetFileSize 获取 ZIP 中指定文件的大小
 func (z *ZipReader) GetFileSize(relPath string) (int64, error) {
 	files, err := z.ListFiles()
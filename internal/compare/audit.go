@@ -0,0 +1,236 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// maxAuditSize 格式检查的文件大小上限，更大的文件不检查
+const maxAuditSize = maxLineCountSize
+
+// DetectEOL 判断内容使用的换行符，返回 models.EOLLF 等常量
+func DetectEOL(content []byte) string {
+	crlf := bytes.Count(content, []byte("\r\n"))
+	lf := bytes.Count(content, []byte("\n")) - crlf
+	cr := bytes.Count(content, []byte("\r")) - crlf
+
+	kinds, eol := 0, models.EOLNone
+	for _, k := range []struct {
+		n   int
+		eol string
+	}{{lf, models.EOLLF}, {crlf, models.EOLCRLF}, {cr, models.EOLCR}} {
+		if k.n > 0 {
+			kinds++
+			eol = k.eol
+		}
+	}
+	if kinds > 1 {
+		return models.EOLMixed
+	}
+	return eol
+}
+
+// detectTextEOL 判断换行符，UTF-16 的内容先解码
+func detectTextEOL(content []byte, enc string) string {
+	if enc == models.EncodingUTF16LE || enc == models.EncodingUTF16BE {
+		if text, ok := decodeText(content, enc); ok {
+			return DetectEOL([]byte(text))
+		}
+	}
+	return DetectEOL(content)
+}
+
+// textEncodings 可识别的带 BOM 编码及非 UTF-8 编码的解码器
+var textEncodings = map[string]encoding.Encoding{
+	models.EncodingUTF8BOM:  unicode.UTF8BOM,
+	models.EncodingUTF16LE:  unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM),
+	models.EncodingUTF16BE:  unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM),
+	models.EncodingShiftJIS: nameEncodings[FilenameEncodingShiftJIS],
+	models.EncodingGBK:      nameEncodings[FilenameEncodingGBK],
+}
+
+// DetectEncoding 识别文本内容的编码，返回 models.EncodingUTF8 等常量
+// 不是 UTF-8 时比较 Shift-JIS 与 GBK 的解码结果
+func DetectEncoding(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}):
+		return models.EncodingUTF8BOM
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		return models.EncodingUTF16LE
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		return models.EncodingUTF16BE
+	}
+
+	ascii := true
+	for _, b := range content {
+		if b >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return models.EncodingASCII
+	}
+	if utf8.Valid(content) {
+		return models.EncodingUTF8
+	}
+
+	best, bestScore := models.EncodingUnknown, 0
+	for _, enc := range []string{models.EncodingShiftJIS, models.EncodingGBK} {
+		if score, ok := scoreText(textEncodings[enc], content); ok && score > bestScore {
+			best, bestScore = enc, score
+		}
+	}
+	return best
+}
+
+// scoreText 用指定编码解码内容并打分，出现无法解码的字节时返回 false
+// 与文件名不同，正文中很少出现半角片假名，GBK 的汉字按 Shift-JIS 解码时常被拆成半角片假名，因此扣分
+func scoreText(codec encoding.Encoding, content []byte) (int, bool) {
+	decoded, err := codec.NewDecoder().Bytes(content)
+	if err != nil || bytes.ContainsRune(decoded, utf8.RuneError) {
+		return 0, false
+	}
+
+	score := 0
+	for _, r := range string(decoded) {
+		switch {
+		case r < utf8.RuneSelf:
+		case r >= 0x3040 && r <= 0x30FF:
+			// 平假名、片假名
+			score += 3
+		case r >= 0x4E00 && r <= 0x9FFF:
+			score += 2
+		case r >= 0x3000 && r <= 0x303F, r >= 0xFF01 && r <= 0xFF5E:
+			// 全角标点与全角 ASCII
+			score++
+		default:
+			score--
+		}
+	}
+	return score + 1, true
+}
+
+// decodeText 按编码将内容解码为 UTF-8 文本，无法解码时返回 false
+func decodeText(content []byte, enc string) (string, bool) {
+	codec, ok := textEncodings[enc]
+	if !ok {
+		return string(content), enc != models.EncodingUnknown
+	}
+	decoded, err := codec.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", false
+	}
+	return string(decoded), true
+}
+
+// compatibleEncodings 判断两种编码是否相同；纯 ASCII 的内容与 UTF-8、Shift-JIS、GBK 兼容
+func compatibleEncodings(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if a != models.EncodingASCII {
+		a, b = b, a
+	}
+	return a == models.EncodingASCII && (b == models.EncodingUTF8 || b == models.EncodingShiftJIS || b == models.EncodingGBK)
+}
+
+// AuditTextFiles 检查差异项中文本文件两侧的换行符和编码，zipReader 为 nil 时只检查工作目录一侧
+// allowed 为允许的编码，非空时标记工作目录中编码不在其中的文件（ASCII 总是允许）
+func AuditTextFiles(zipReader *ZipReader, items []models.DiffItem, allowed []string) *models.TextAuditReport {
+	report := &models.TextAuditReport{Files: make([]models.TextFileAudit, 0)}
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, enc := range allowed {
+		allowedSet[strings.ToLower(strings.TrimSpace(enc))] = true
+	}
+	for _, item := range items {
+		oldContent, hasOld := auditBaseline(zipReader, item)
+		newContent, hasNew := auditWorkFile(item)
+		if !hasOld && !hasNew {
+			continue
+		}
+		head := newContent
+		if !hasNew {
+			head = oldContent
+		}
+		if !IsTextFile(item.RelPath) && !looksLikeText(head[:min(len(head), 512)]) {
+			continue
+		}
+
+		audit := models.TextFileAudit{RelPath: item.RelPath, Type: item.Type}
+		if hasOld {
+			audit.OldEncoding = DetectEncoding(oldContent)
+			audit.OldEOL = detectTextEOL(oldContent, audit.OldEncoding)
+		}
+		if hasNew {
+			audit.NewEncoding = DetectEncoding(newContent)
+			audit.NewEOL = detectTextEOL(newContent, audit.NewEncoding)
+			if len(allowedSet) > 0 && audit.NewEncoding != models.EncodingASCII && !allowedSet[audit.NewEncoding] {
+				audit.PolicyViolation = true
+				report.Violations++
+			}
+			switch audit.NewEOL {
+			case models.EOLLF:
+				report.LF++
+			case models.EOLCRLF:
+				report.CRLF++
+			case models.EOLMixed:
+				report.Mixed++
+			}
+		}
+		// 只有一行的文件看不出换行符，不算作变化
+		if hasOld && hasNew && audit.OldEOL != models.EOLNone && audit.NewEOL != models.EOLNone && audit.OldEOL != audit.NewEOL {
+			audit.EOLChanged = true
+			report.EOLChanged++
+		}
+		if hasOld && hasNew && !compatibleEncodings(audit.OldEncoding, audit.NewEncoding) {
+			audit.EncodingChanged = true
+			report.EncodingChanged++
+			oldText, okOld := decodeText(oldContent, audit.OldEncoding)
+			newText, okNew := decodeText(newContent, audit.NewEncoding)
+			if okOld && okNew && oldText == newText {
+				audit.EncodingOnly = true
+				report.EncodingOnly++
+			}
+		}
+		report.Files = append(report.Files, audit)
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool { return report.Files[i].RelPath < report.Files[j].RelPath })
+	return report
+}
+
+// auditBaseline 读取差异项在基准中的内容，不存在或过大时返回 false
+func auditBaseline(zipReader *ZipReader, item models.DiffItem) ([]byte, bool) {
+	if zipReader == nil || item.Type == "added" || item.Stream != "" {
+		return nil, false
+	}
+	relPath := item.RelPath
+	if item.OldPath != "" {
+		relPath = item.OldPath
+	}
+	content, size, err := zipReader.ReadFileHead(filepath.ToSlash(relPath), maxAuditSize+1)
+	if err != nil || size > maxAuditSize {
+		return nil, false
+	}
+	return content, true
+}
+
+// auditWorkFile 读取差异项在工作目录中的内容，已删除或过大时返回 false
+func auditWorkFile(item models.DiffItem) ([]byte, bool) {
+	if item.Type == "deleted" || item.SourcePath == "" || item.Stream != "" {
+		return nil, false
+	}
+	content, size, err := readFileHead(item.SourcePath, maxAuditSize+1)
+	if err != nil || size > maxAuditSize {
+		return nil, false
+	}
+	return content, true
+}
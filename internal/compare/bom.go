@@ -0,0 +1,54 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"archive/zip"
+	"bytes"
+	"io"
+)
+
+// utf8BOM UTF-8 字节顺序标记
+var utf8BOM = []byte("\xEF\xBB\xBF")
+
+// SetIgnoreBOM 设置是否忽略只差 UTF-8 BOM 的修改：启用后这类文件视为相同，未启用时仍列为修改并标记 BOMOnly
+func (c *Comparer) SetIgnoreBOM(enabled bool) {
+	c.ignoreBOM = enabled
+}
+
+// BOMNormalizeRule 忽略 BOM 时在规范化规则前加入的规则，使差异预览同样去掉 BOM
+func BOMNormalizeRule() models.NormalizeRule {
+	return models.NormalizeRule{Steps: []string{"bom"}, Enabled: true, Comment: "忽略 UTF-8 BOM"}
+}
+
+// bomOnly 判断哈希不同的两个文件是否只差开头的 UTF-8 BOM
+// 大小恰好相差 BOM 长度时才读取内容，其他文件没有额外开销
+func (c *Comparer) bomOnly(f *zip.File, workFilePath string, workSize int64) bool {
+	oldSize := int64(f.UncompressedSize64)
+	if diff := oldSize - workSize; diff != int64(len(utf8BOM)) && diff != -int64(len(utf8BOM)) {
+		return false
+	}
+	if max(oldSize, workSize) > maxNormalizeSize {
+		return false
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return false
+	}
+	oldContent, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return false
+	}
+	newContent, _, err := readFileHead(workFilePath, maxNormalizeSize+1)
+	if err != nil {
+		return false
+	}
+	return bomOnlyContent(oldContent, newContent)
+}
+
+// bomOnlyContent 判断两侧内容是否只差开头的 UTF-8 BOM
+func bomOnlyContent(oldContent, newContent []byte) bool {
+	return bytes.HasPrefix(oldContent, utf8BOM) != bytes.HasPrefix(newContent, utf8BOM) &&
+		bytes.Equal(stripBOM(oldContent), stripBOM(newContent))
+}
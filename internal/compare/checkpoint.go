@@ -0,0 +1,161 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultCheckpointInterval 默认的检查点保存间隔
+const DefaultCheckpointInterval = 30 * time.Second
+
+// checkpoint 比较过程中定期保存的部分结果，应用崩溃后可从此处继续比较
+type checkpoint struct {
+	ZipPath     string            `json:"zipPath"`
+	WorkDir     string            `json:"workDir"`
+	ZipSize     int64             `json:"zipSize"`
+	ZipModTime  time.Time         `json:"zipModTime"`
+	Fingerprint string            `json:"fingerprint"` // 排除规则等影响结果的设置的摘要
+	Checked     []string          `json:"checked"`     // 已完成比较的 ZIP 中的文件
+	Items       []models.DiffItem `json:"items"`       // 已发现的差异（仅 ZIP 中的文件）
+	SavedAt     time.Time         `json:"savedAt"`
+}
+
+// SetCheckpoint 设置检查点文件和保存间隔，path 为空时不保存检查点
+// 设置后比较时若存在同一 ZIP、工作目录和设置的检查点，则跳过已比较的文件继续比较
+func (c *Comparer) SetCheckpoint(path string, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
+	}
+	c.checkpointPath = path
+	c.checkpointInterval = interval
+}
+
+// newCheckpoint 创建与当前比较对应的空检查点
+func (c *Comparer) newCheckpoint() (*checkpoint, error) {
+	info, err := os.Stat(c.zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 备用数据流和生成文件配对在比较结束后对完整结果计算，恢复检查点后会重新计算，不必计入
+	settings, err := json.Marshal(struct {
+		Rules     []models.ExcludeRule
+		Gitignore bool
+		Encoding  string
+		SubPath   string
+		MaxDepth  int
+		Unchanged bool
+		Hash      string
+		Fast      bool
+		FoldCase  bool
+		IgnoreBOM bool
+		IgnoreEnc bool
+		Normalize []any
+		Comments  bool
+		Keywords  []models.KeywordRule
+	}{c.excludeRules(), c.gitignore != nil, c.nameEncoding, c.subPath, c.maxDepth, c.listUnchanged, c.hashName(), c.fastMode, c.ignoreCase, c.ignoreBOM, c.ignoreEncoding,
+		c.normalizer.settings(), c.detectComments, c.keywords.settings()})
+	if err != nil {
+		return nil, err
+	}
+	sum := md5.Sum(settings)
+
+	return &checkpoint{
+		ZipPath:     c.zipPath,
+		WorkDir:     c.workDir,
+		ZipSize:     info.Size(),
+		ZipModTime:  info.ModTime(),
+		Fingerprint: hex.EncodeToString(sum[:]),
+		Checked:     make([]string, 0),
+		Items:       make([]models.DiffItem, 0),
+	}, nil
+}
+
+// excludeRules 返回当前使用的排除规则
+func (c *Comparer) excludeRules() []models.ExcludeRule {
+	if c.excludeMatcher == nil {
+		return nil
+	}
+	return c.excludeMatcher.rules
+}
+
+// loadCheckpoint 读取检查点，与当前比较不匹配（ZIP 已变化、设置不同等）时返回 false
+func loadCheckpoint(path string, current *checkpoint) (*checkpoint, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var saved checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, false
+	}
+	if saved.ZipPath != current.ZipPath || saved.WorkDir != current.WorkDir ||
+		saved.ZipSize != current.ZipSize || !saved.ZipModTime.Equal(current.ZipModTime) ||
+		saved.Fingerprint != current.Fingerprint {
+		return nil, false
+	}
+	return &saved, true
+}
+
+// save 写入检查点，先写临时文件再重命名，避免崩溃时留下不完整的文件
+func (cp *checkpoint) save(path string) error {
+	cp.SavedAt = time.Now()
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// resumeCheckpoint 准备本次比较的检查点；存在匹配的检查点时将其中的差异恢复到结果中
+// 返回的检查点为 nil 表示未启用检查点，集合为已比较过的文件
+func (c *Comparer) resumeCheckpoint(result *models.CompareResult) (*checkpoint, map[string]bool) {
+	checked := make(map[string]bool)
+	if c.checkpointPath == "" {
+		return nil, checked
+	}
+
+	cp, err := c.newCheckpoint()
+	if err != nil {
+		c.tracef("checkpoint disabled: %v", err)
+		return nil, checked
+	}
+
+	saved, ok := loadCheckpoint(c.checkpointPath, cp)
+	if !ok {
+		return cp, checked
+	}
+
+	c.tracef("resume from checkpoint saved at %s: %d files checked", saved.SavedAt.Format(time.RFC3339), len(saved.Checked))
+	for _, relPath := range saved.Checked {
+		checked[relPath] = true
+	}
+	for _, item := range saved.Items {
+		result.Items = append(result.Items, item)
+		switch item.Type {
+		case "deleted":
+			result.Deleted++
+		case "modified":
+			result.Modified++
+		case TypeUnchanged:
+			result.Unchanged++
+			result.UnchangedBytes += item.OldSize
+		}
+	}
+	return saved, checked
+}
@@ -0,0 +1,124 @@
+package compare
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+)
+
+// 导出时写入的校验和文件，格式与 sha256sum/md5sum 的输出相同，可用 sha256sum -c 校验
+const (
+	SHA256SumsName = "SHA256SUMS"
+	MD5SumsName    = "MD5SUMS"
+)
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	Checksums  bool     `json:"checksums"`  // 写入 SHA256SUMS
+	MD5Sums    bool     `json:"md5Sums"`    // 写入 MD5SUMS，供只支持 MD5 的旧工具使用
+	SigningKey string   `json:"signingKey"` // Ed25519 私钥路径，非空时生成分离签名：目录导出签名 SHA256SUMS，ZIP 导出签名 ZIP 文件
+	Recipients []string `json:"recipients"` // age 公钥（age1...），非空时 ZIP 导出整体加密（仅 ZIP 导出）
+	Changelog  string   `json:"changelog"`  // 写入 CHANGELOG.md 的内容，为空时不写入；计入校验和
+	EOL        string   `json:"eol"`        // 文本文件的换行符转换为 lf 或 crlf，为空时原样复制；校验和按转换后的内容计算
+	Journal    string   `json:"journal"`    // 导出进度记录文件，非空时记录已导出的文件，中断后再次导出到同一目录时跳过（仅目录导出）
+	Verify     bool     `json:"verify"`     // 写入后重新读取导出的文件，与写入时计算的哈希比较，不一致时返回 VerifyError
+	Baseline   string   `json:"baseline"`   // 基准 ZIP 的路径，非空时保存到导出目录（ZIP 导出时保存在 ZIP 旁边），作为比较依据；计入校验和
+	HardLink   bool     `json:"hardLink"`   // 保存基准 ZIP 时优先创建硬链接，无法链接时复制
+	Result     []byte   `json:"result"`     // 比较结果（JSON），非空时与基准 ZIP 一起保存为 compare-result.json
+}
+
+// checksumSet 导出过程中计算的校验和，opts 均未启用时为 nil
+type checksumSet struct {
+	opts  ExportOptions
+	files []checksumFile
+}
+
+// checksumFile 一个导出文件的哈希，复制时计算（digest）或取自导出进度记录（sha256Hex、md5Hex）
+type checksumFile struct {
+	relPath   string
+	digest    *fileDigest
+	sha256Hex string
+	md5Hex    string
+}
+
+// fileDigest 同时计算 SHA-256 和 MD5 的 Writer
+type fileDigest struct {
+	sha256 hash.Hash
+	md5    hash.Hash
+}
+
+func newFileDigest() *fileDigest {
+	return &fileDigest{sha256: sha256.New(), md5: md5.New()}
+}
+
+func (d *fileDigest) Write(p []byte) (int, error) {
+	d.sha256.Write(p)
+	d.md5.Write(p)
+	return len(p), nil
+}
+
+// sums 返回十六进制的 SHA-256 和 MD5
+func (d *fileDigest) sums() (string, string) {
+	return hex.EncodeToString(d.sha256.Sum(nil)), hex.EncodeToString(d.md5.Sum(nil))
+}
+
+func newChecksumSet(opts ExportOptions) *checksumSet {
+	// 签名针对校验和清单，签名时总是写入 SHA256SUMS
+	if opts.SigningKey != "" {
+		opts.Checksums = true
+	}
+	if !opts.Checksums && !opts.MD5Sums {
+		return nil
+	}
+	return &checksumSet{opts: opts}
+}
+
+// add 登记一个导出文件，返回在复制时同时写入以计算哈希的 Writer
+func (s *checksumSet) add(relPath string) io.Writer {
+	if s == nil {
+		return io.Discard
+	}
+	digest := newFileDigest()
+	s.files = append(s.files, checksumFile{relPath: relPath, digest: digest})
+	return digest
+}
+
+// addSums 登记一个已计算过哈希的导出文件
+func (s *checksumSet) addSums(relPath, sha256Hex, md5Hex string) {
+	if s == nil {
+		return
+	}
+	s.files = append(s.files, checksumFile{relPath: relPath, sha256Hex: sha256Hex, md5Hex: md5Hex})
+}
+
+// contents 返回要写入的校验和文件名及内容，按路径排序
+func (s *checksumSet) contents() map[string]string {
+	if s == nil {
+		return nil
+	}
+	sort.Slice(s.files, func(i, j int) bool { return s.files[i].relPath < s.files[j].relPath })
+
+	var sha, md strings.Builder
+	for _, f := range s.files {
+		shaHex, mdHex := f.sha256Hex, f.md5Hex
+		if f.digest != nil {
+			shaHex, mdHex = f.digest.sums()
+		}
+		fmt.Fprintf(&sha, "%s  %s\n", shaHex, f.relPath)
+		fmt.Fprintf(&md, "%s  %s\n", mdHex, f.relPath)
+	}
+
+	files := make(map[string]string)
+	if s.opts.Checksums {
+		files[SHA256SumsName] = sha.String()
+	}
+	if s.opts.MD5Sums {
+		files[MD5SumsName] = md.String()
+	}
+	return files
+}
@@ -0,0 +1,178 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"archive/zip"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// commentSyntax 一种语言的注释和字符串字面量写法
+type commentSyntax struct {
+	line       []string // 行注释的开始标记
+	block      bool     // 是否支持 /* */ 块注释
+	quotes     string   // 字符串（和字符）字面量的引号
+	backslash  bool     // 字符串中是否用反斜杠转义，否则用连写两个引号转义
+	verbatim   bool     // 是否支持 C# 的 @"..." 逐字字符串
+	remComment bool     // 是否支持 VB 的 REM 注释
+}
+
+// commentLanguages 按扩展名识别的语言
+var commentLanguages = map[string]commentSyntax{
+	".cs":  {line: []string{"//"}, block: true, quotes: `"'`, backslash: true, verbatim: true},
+	".js":  {line: []string{"//"}, block: true, quotes: "\"'`", backslash: true},
+	".mjs": {line: []string{"//"}, block: true, quotes: "\"'`", backslash: true},
+	".cjs": {line: []string{"//"}, block: true, quotes: "\"'`", backslash: true},
+	".jsx": {line: []string{"//"}, block: true, quotes: "\"'`", backslash: true},
+	".ts":  {line: []string{"//"}, block: true, quotes: "\"'`", backslash: true},
+	".tsx": {line: []string{"//"}, block: true, quotes: "\"'`", backslash: true},
+	".vb":  {line: []string{"'"}, quotes: `"`, remComment: true},
+	".vbs": {line: []string{"'"}, quotes: `"`, remComment: true},
+	".bas": {line: []string{"'"}, quotes: `"`, remComment: true},
+	".sql": {line: []string{"--"}, block: true, quotes: `'"`},
+}
+
+// SetDetectCommentsOnly 设置是否分析修改的 C#、VB、JavaScript/TypeScript、SQL 文件是否只改了注释
+// 只改了注释（和空行）的文件标记 CommentsOnly，仍列为修改
+func (c *Comparer) SetDetectCommentsOnly(enabled bool) {
+	c.detectComments = enabled
+}
+
+// markCommentsOnly 去掉两侧的注释和空行后内容相同时标记差异项，过大、无法识别语言或编码的文件跳过
+func (c *Comparer) markCommentsOnly(item *models.DiffItem, f *zip.File) {
+	syntax, ok := commentLanguages[strings.ToLower(filepath.Ext(item.RelPath))]
+	if !ok || f.UncompressedSize64 > uint64(maxNormalizeSize) {
+		return
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return
+	}
+	oldContent, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return
+	}
+	newContent, size, err := readFileHead(item.SourcePath, maxNormalizeSize+1)
+	if err != nil || size > maxNormalizeSize {
+		return
+	}
+
+	oldText, ok := decodeText(oldContent, DetectEncoding(oldContent))
+	if !ok {
+		return
+	}
+	newText, ok := decodeText(newContent, DetectEncoding(newContent))
+	if !ok {
+		return
+	}
+	item.CommentsOnly = codeLines(oldText, syntax) == codeLines(newText, syntax)
+}
+
+// codeLines 去掉注释后，返回去掉行尾空白和空行的内容
+func codeLines(text string, syntax commentSyntax) string {
+	text = strings.TrimPrefix(text, "\uFEFF")
+	text = strings.ReplaceAll(stripComments(text, syntax), "\r\n", "\n")
+
+	var sb strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// stripComments 去掉代码中的注释，字符串字面量中的注释标记保持原样；块注释中的换行保留
+func stripComments(text string, syntax commentSyntax) string {
+	var sb strings.Builder
+	lineStart := true // 当前位置之前的本行内容只有空白，用于识别 REM
+	for i := 0; i < len(text); {
+		ch := text[i]
+
+		// 字符串字面量
+		if syntax.verbatim && ch == '@' && i+1 < len(text) && text[i+1] == '"' {
+			end := skipQuoted(text, i+1, '"', false, true)
+			sb.WriteString(text[i:end])
+			i, lineStart = end, false
+			continue
+		}
+		if strings.IndexByte(syntax.quotes, ch) >= 0 {
+			end := skipQuoted(text, i, ch, syntax.backslash, ch == '`')
+			sb.WriteString(text[i:end])
+			i, lineStart = end, false
+			continue
+		}
+
+		// 块注释，保留其中的换行使行号不变
+		if syntax.block && strings.HasPrefix(text[i:], "/*") {
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i + 4
+			}
+			sb.WriteString(strings.Repeat("\n", strings.Count(text[i:end], "\n")))
+			i = end
+			continue
+		}
+
+		// 行注释
+		if isLineComment(text[i:], syntax, lineStart) {
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				break
+			}
+			i += end
+			continue
+		}
+
+		sb.WriteByte(ch)
+		switch ch {
+		case '\n':
+			lineStart = true
+		case ' ', '\t', '\r':
+		default:
+			lineStart = false
+		}
+		i++
+	}
+	return sb.String()
+}
+
+// isLineComment 判断当前位置是否是行注释的开始
+func isLineComment(rest string, syntax commentSyntax, lineStart bool) bool {
+	for _, marker := range syntax.line {
+		if strings.HasPrefix(rest, marker) {
+			return true
+		}
+	}
+	if syntax.remComment && lineStart && len(rest) >= 3 && strings.EqualFold(rest[:3], "rem") {
+		return len(rest) == 3 || rest[3] == ' ' || rest[3] == '\t' || rest[3] == '\r' || rest[3] == '\n'
+	}
+	return false
+}
+
+// skipQuoted 返回从 start 处的引号开始的字符串字面量之后的位置，未闭合时到行尾；multiline 为 true 时可跨行（模板字符串、逐字字符串）
+func skipQuoted(text string, start int, quote byte, backslash, multiline bool) int {
+	for i := start + 1; i < len(text); i++ {
+		switch ch := text[i]; {
+		case backslash && ch == '\\':
+			i++
+		case ch == quote:
+			if !backslash && i+1 < len(text) && text[i+1] == quote {
+				i++ // 连写两个引号表示引号本身
+				continue
+			}
+			return i + 1
+		case ch == '\n' && !multiline:
+			return i
+		}
+	}
+	return len(text)
+}
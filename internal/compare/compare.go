@@ -1,30 +1,66 @@
 package compare
 
 import (
+	"Discrepancies/internal/encrypt"
 	"Discrepancies/internal/models"
+	"Discrepancies/internal/signing"
 	"archive/zip"
 	"bytes"
 	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// ErrNothingSelected 没有选中要导出的差异项
+var ErrNothingSelected = errors.New("no items selected")
+
+// ChangelogName 导出时写入的变更日志文件名
+const ChangelogName = "CHANGELOG.md"
+
+// ErrChangelogConflict 导出的文件中已有根目录的 CHANGELOG.md，写入变更日志会覆盖它
+var ErrChangelogConflict = errors.New("exported files already contain " + ChangelogName)
+
+// ItemError 处理单个差异文件时的错误，RelPath 为出错的文件
+type ItemError struct {
+	RelPath string
+	Err     error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("%s: %v", e.RelPath, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// maxLineCountSize 统计行数变化的文件大小上限，超过时不统计
+const maxLineCountSize int64 = 8 << 20
+
 // ExcludeMatcher 排除规则匹配器
 type ExcludeMatcher struct {
 	rules         []models.ExcludeRule
 	regexCache    map[string]*regexp.Regexp
 	compiledRules []compiledRule
+	generated     []generatedRule // 生成文件规则，不参与排除判断
 }
 
 type compiledRule struct {
-	rule    models.ExcludeRule
-	regex   *regexp.Regexp
-	pattern string
+	rule     models.ExcludeRule
+	regex    *regexp.Regexp
+	pattern  string
+	anchored bool // 是否仅在比较根目录匹配（模式以 / 或 ./ 开头）
 }
 
 // NewExcludeMatcher 创建新的排除匹配器
@@ -44,6 +80,12 @@ func (m *ExcludeMatcher) compile() {
 		if !rule.Enabled {
 			continue
 		}
+		if rule.Type == RuleTypeGenerated {
+			if gr, ok := compileGenerated(rule); ok {
+				m.generated = append(m.generated, gr)
+			}
+			continue
+		}
 
 		cr := compiledRule{rule: rule}
 
@@ -54,8 +96,8 @@ func (m *ExcludeMatcher) compile() {
 			}
 		} else {
 			// Glob 模式，转换为正则表达式
-			cr.pattern = rule.Pattern
-			regexPattern := globToRegex(rule.Pattern)
+			cr.pattern, cr.anchored = trimRootAnchor(rule.Pattern)
+			regexPattern := globToRegex(cr.pattern)
 			if re, err := regexp.Compile(regexPattern); err == nil {
 				cr.regex = re
 			}
@@ -65,60 +107,87 @@ func (m *ExcludeMatcher) compile() {
 	}
 }
 
-// globToRegex 将 glob 模式转换为正则表达式
-func globToRegex(pattern string) string {
-	// 转义正则特殊字符
-	result := regexp.QuoteMeta(pattern)
-	// 替换 glob 通配符
-	result = strings.ReplaceAll(result, `\*\*`, `.*`)    // ** 匹配任意路径
-	result = strings.ReplaceAll(result, `\*`, `[^/]*`)  // * 匹配单级路径中的任意字符
-	result = strings.ReplaceAll(result, `\?`, `.`)      // ? 匹配单个字符
-	return "^" + result + "$"
+// trimRootAnchor 去除模式开头的根锚定前缀（/ 或 ./），并返回模式是否被锚定
+func trimRootAnchor(pattern string) (string, bool) {
+	switch {
+	case strings.HasPrefix(pattern, "./"):
+		return strings.TrimLeft(pattern[2:], "/"), true
+	case strings.HasPrefix(pattern, "/"):
+		return strings.TrimLeft(pattern, "/"), true
+	}
+	return pattern, false
 }
 
 // ShouldExclude 检查路径是否应该被排除
+// 规则按顺序匹配，最后一条命中的规则生效；包含规则（Negate）可重新包含被排除的路径
 func (m *ExcludeMatcher) ShouldExclude(path string, isDir bool) bool {
 	// 统一使用正斜杠
 	path = filepath.ToSlash(path)
 
-	for _, cr := range m.compiledRules {
+	rule, ok := m.MatchingRule(path, isDir)
+	return ok && !rule.Negate
+}
+
+// MatchingRule 返回最后一条命中路径的规则（决定是否排除的规则），没有命中时返回 false
+func (m *ExcludeMatcher) MatchingRule(path string, isDir bool) (models.ExcludeRule, bool) {
+	path = filepath.ToSlash(path)
+
+	var matched *compiledRule
+	for i := range m.compiledRules {
+		cr := &m.compiledRules[i]
 		if cr.regex == nil {
 			continue
 		}
-
-		// 如果规则仅匹配目录，跳过文件
-		if cr.rule.IsDir && !isDir {
-			// 但仍需检查路径中是否包含该目录
-			if m.pathContainsDir(path, cr) {
-				return true
-			}
-			continue
+		if m.matchRule(path, isDir, *cr) {
+			matched = cr
 		}
+	}
 
-		// 对于目录规则，检查路径中的每个部分
-		if cr.rule.IsDir {
-			parts := strings.Split(path, "/")
-			for _, part := range parts {
-				if cr.regex.MatchString(part) {
-					return true
-				}
-			}
-		} else {
-			// 对于文件规则，匹配文件名或完整路径
-			fileName := filepath.Base(path)
-			if cr.regex.MatchString(fileName) || cr.regex.MatchString(path) {
-				return true
-			}
-		}
+	if matched == nil {
+		return models.ExcludeRule{}, false
 	}
+	return matched.rule, true
+}
 
-	return false
+// matchRule 检查单条规则是否命中路径
+func (m *ExcludeMatcher) matchRule(path string, isDir bool, cr compiledRule) bool {
+	// 如果规则仅匹配目录，跳过文件
+	if cr.rule.IsDir && !isDir {
+		// 但仍需检查路径中是否包含该目录
+		return m.pathContainsDir(path, cr)
+	}
+
+	// 对于目录规则，检查路径中的每个部分
+	if cr.rule.IsDir {
+		return m.matchDirs(strings.Split(path, "/"), cr)
+	}
+
+	// 对于文件规则，匹配文件名或完整路径（根锚定的规则仅匹配完整路径）
+	if cr.regex.MatchString(path) {
+		return true
+	}
+	if !cr.anchored && cr.regex.MatchString(filepath.Base(path)) {
+		return true
+	}
+
+	// 来自忽略文件的规则与 gitignore 一致，同样匹配上级目录（如 *.log 排除 foo.log/ 下的文件）
+	return cr.rule.FromIgnore && m.pathContainsDir(path, cr)
 }
 
 // pathContainsDir 检查路径中是否包含匹配的目录
 func (m *ExcludeMatcher) pathContainsDir(path string, cr compiledRule) bool {
 	parts := strings.Split(path, "/")
-	for _, part := range parts[:len(parts)-1] { // 排除最后一个（文件名）
+	return m.matchDirs(parts[:len(parts)-1], cr) // 排除最后一个（文件名）
+}
+
+// matchDirs 检查目录层级中是否有匹配规则的目录
+// 模式中不含 / 且未根锚定时逐段匹配目录名，否则匹配从根开始的目录路径（如 src/**/obj、/bin）
+func (m *ExcludeMatcher) matchDirs(parts []string, cr compiledRule) bool {
+	multiSegment := cr.anchored || (cr.rule.Type != "regex" && strings.Contains(cr.pattern, "/"))
+	for i, part := range parts {
+		if multiSegment {
+			part = strings.Join(parts[:i+1], "/")
+		}
 		if cr.regex.MatchString(part) {
 			return true
 		}
@@ -132,7 +201,32 @@ type Comparer struct {
 	workDir        string
 	zipReader      *ZipReader
 	excludeMatcher *ExcludeMatcher
+	gitignore      *nestedIgnore // 工作目录中各层 .gitignore 的规则，未启用时为 nil
+	textDiffer     *TextDiffer
+	normalizer     *Normalizer     // 规范化规则，未设置时为 nil
+	keywords       *KeywordScanner // 关键字扫描规则，未设置时为 nil
+	nameEncoding   string          // ZIP 文件名编码，空表示自动识别
+	compareStreams bool            // 是否比较 NTFS 备用数据流
+	largestCount   int             // 结果中列出的大小增加最多的文件数，0 表示默认值
+	readRetries    int             // 读取工作目录文件失败时的重试次数，0 表示默认值，负数不重试
+	readBackoff    time.Duration   // 首次重试前的等待时间
+	subPath        string          // 限定比较的子目录（正斜杠形式），空表示比较全部
+	maxDepth       int             // 扫描的最大目录层数（从子目录开始计算），0 表示不限制
+	listUnchanged  bool            // 是否在结果中列出内容相同的文件
+	ignoreBOM      bool            // 是否将只差 UTF-8 BOM 的文件视为相同
+	ignoreEncoding bool            // 是否将只在 UTF-16 与 UTF-8 之间转换了编码的文件视为相同
+	detectComments bool            // 是否分析修改的代码文件是否只改了注释
+	hashAlgo       string          // 大小相同的文件比较内容的算法，空表示 crc32
+	fastMode       bool            // 大小和修改时间都相同的文件不读取内容
+	concurrency    int             // 扫描工作目录的并发数，0 表示默认值
+	ignoreCase     bool            // 路径是否不区分大小写
+	stats          models.CompareStats
 	OnProgress     func(current, total int, message string)
+	OnHeartbeat    func(phase string, count int, message string) // 读取 ZIP、扫描工作目录等没有逐文件进度的阶段定期触发
+	OnTrace        func(message string)                          // 调试跟踪：记录排除判断、哈希计算和路径规范化，为 nil 时不记录
+
+	checkpointPath     string // 检查点文件，为空时不保存
+	checkpointInterval time.Duration
 }
 
 // NewComparer 创建新的比较器
@@ -143,50 +237,131 @@ func NewComparer(zipPath, workDir string) *Comparer {
 	}
 }
 
-// SetExcludeRules 设置排除规则
+// SetExcludeRules 设置排除规则；基准是 APK、IPA、VSIX 等容器时，容器的默认排除规则排在最前，可被包含规则覆盖
 func (c *Comparer) SetExcludeRules(rules []models.ExcludeRule) {
-	c.excludeMatcher = NewExcludeMatcher(rules)
+	c.excludeMatcher = NewExcludeMatcher(append(ContainerExcludeRules(ContainerKind(c.zipPath)), rules...))
+}
+
+// SetNormalizer 设置规范化器，哈希不同的文件在规范化后相同时视为未修改
+func (c *Comparer) SetNormalizer(n *Normalizer) {
+	c.normalizer = n
+}
+
+// Stats 返回最近一次比较的统计信息
+func (c *Comparer) Stats() models.CompareStats {
+	return c.stats
+}
+
+// SetFilenameEncoding 设置 ZIP 中未标记 UTF-8 的文件名所用的编码
+func (c *Comparer) SetFilenameEncoding(encoding string) {
+	c.nameEncoding = encoding
+}
+
+// SetRespectGitignore 设置是否遵循工作目录中各层的 .gitignore 文件
+func (c *Comparer) SetRespectGitignore(enabled bool) {
+	if enabled {
+		c.gitignore = newNestedIgnore()
+	} else {
+		c.gitignore = nil
+	}
 }
 
 // Compare 执行比较并返回差异结果
 func (c *Comparer) Compare() (*models.CompareResult, error) {
+	c.stats = models.CompareStats{}
+
 	// 打开 ZIP 文件
+	stop := c.startHeartbeat(PhaseOpen, func() (int, string) {
+		return 0, "正在读取 ZIP 文件…"
+	})
 	var err error
-	c.zipReader, err = NewZipReader(c.zipPath)
+	c.zipReader, err = NewZipReaderWithEncoding(c.zipPath, c.nameEncoding)
 	if err != nil {
+		stop()
 		return nil, fmt.Errorf("failed to open zip file: %w", err)
 	}
 	defer c.zipReader.Close()
+	if c.maxDepth > 0 {
+		c.zipReader.SetMaxDepth(pathDepth(c.subPath) + c.maxDepth)
+	}
 
 	// 获取 ZIP 中的文件列表
 	zipFiles, err := c.zipReader.ListFiles()
+	stop()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list zip files: %w", err)
 	}
+	zipFiles = c.scopeZipFiles(zipFiles)
 
 	// 获取工作目录的文件列表
-	workFiles, _, err := getAllFilesAndDirs(c.workDir)
+	var found atomic.Int64
+	stop = c.startHeartbeat(PhaseScan, func() (int, string) {
+		n := int(found.Load())
+		return n, fmt.Sprintf("扫描工作目录… 已发现 %d 个文件", n)
+	})
+	workFiles, _, skipped, err := getAllFilesAndDirs(c.workDir, c.subPath, c.maxDepth, c.concurrency, c.gitignore, c.tracef, &found)
+	stop()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list work directory files: %w", err)
 	}
+	if c.ignoreCase {
+		workFiles = c.foldCase(zipFiles, workFiles)
+	}
+	c.stats.FastMode = c.fastMode
 
 	result := &models.CompareResult{
-		Items: make([]models.DiffItem, 0),
+		Items:     make([]models.DiffItem, 0),
+		Skipped:   make([]models.PathIssue, 0),
+		MaxDepth:  c.maxDepth,
+		Container: ContainerKind(c.zipPath),
+	}
+	skippedPaths := make(map[string]bool, len(skipped))
+	for _, issue := range skipped {
+		if !c.shouldExclude(issue.Path, false) {
+			result.Skipped = append(result.Skipped, issue)
+			skippedPaths[issue.Path] = true
+		}
 	}
 
 	totalFiles := len(zipFiles) + len(workFiles)
 	processed := 0
 
+	// 从检查点恢复上次中断前已比较的文件
+	cp, checked := c.resumeCheckpoint(result)
+	restored := make(map[string]bool, len(result.Items))
+	for _, item := range result.Items {
+		restored[item.RelPath] = true
+	}
+	lastSave := time.Now()
+
 	// 比较 ZIP 中的文件与工作目录
 	for relPath, zipFile := range zipFiles {
+		if zipFile.Name != relPath {
+			c.tracef("normalize zip %q -> %q", zipFile.Name, relPath)
+		}
 		if c.shouldExclude(relPath, false) {
+			result.Excluded++
 			continue
 		}
 
 		processed++
+		if checked[relPath] {
+			c.stats.ResumedFiles++
+			if !restored[relPath] {
+				// 检查点中没有差异项的文件上次已确认相同
+				c.addUnchanged(result, relPath, workFiles[relPath], zipFile, int64(zipFile.UncompressedSize64))
+			}
+			continue
+		}
+		c.stats.FilesScanned++
+		found := len(result.Items)
 		c.emitProgress(processed, totalFiles, fmt.Sprintf("检查: %s", relPath))
 
 		workFilePath, exists := workFiles[relPath]
+		if !exists && skippedPaths[relPath] {
+			// 工作目录中同名的是特殊文件，已列为跳过，不视为删除
+			continue
+		}
 		if !exists {
 			// 文件在工作目录中不存在（已删除）
 			result.Items = append(result.Items, models.DiffItem{
@@ -194,28 +369,104 @@ func (c *Comparer) Compare() (*models.CompareResult, error) {
 				Type:       "deleted",
 				Selected:   true,
 				SourcePath: "",
+				OldSize:    int64(zipFile.UncompressedSize64),
 			})
 			result.Deleted++
 		} else {
-			// 比较文件内容
-			zipHash, err := c.getZipFileHash(zipFile)
-			if err != nil {
-				continue
-			}
-			workHash, err := fileHash(workFilePath)
-			if err != nil {
-				continue
+			// 比较文件内容：大小不同时一定已修改，不必读取；快速比较时大小和修改时间都相同视为相同；
+			// 否则比较两侧的校验和，默认使用 ZIP 中记录的 CRC32，不必解压基准
+			var (
+				n        int64
+				unstable bool
+				modified bool
+			)
+			info, statErr := os.Stat(workFilePath)
+			switch {
+			case statErr == nil && info.Size() != int64(zipFile.UncompressedSize64):
+				n = info.Size()
+				modified = true
+				c.stats.SizeDiffers++
+				c.tracef("size %s zip=%d work=%d", relPath, zipFile.UncompressedSize64, n)
+			case statErr == nil && c.fastMode && sameModTime(zipFile, info.ModTime()):
+				n = info.Size()
+				c.tracef("fast %s: same size and modification time", relPath)
+			default:
+				var workSum []byte
+				var err error
+				workSum, n, unstable, err = c.checksumWorkFile(workFilePath)
+				if err != nil {
+					c.tracef("checksum work %s failed: %v", workFilePath, err)
+					result.Items = append(result.Items, failedItem(relPath, workFilePath, "读取工作目录中的文件失败", err))
+					result.Failed++
+					continue
+				}
+				c.stats.BytesHashed += n
+				zipSum, err := c.zipChecksum(zipFile)
+				if err != nil {
+					c.tracef("checksum zip %s failed: %v", relPath, err)
+					result.Items = append(result.Items, failedItem(relPath, workFilePath, "读取基准中的文件失败", err))
+					result.Failed++
+					continue
+				}
+				modified = !bytes.Equal(zipSum, workSum) || n != int64(zipFile.UncompressedSize64)
+				c.tracef("%s %s zip=%x (%d bytes) work=%x (%d bytes) equal=%t",
+					c.hashName(), relPath, zipSum, zipFile.UncompressedSize64, workSum, n, !modified)
 			}
 
-			if !bytes.Equal(zipHash, workHash) {
+			bomOnly := modified && c.bomOnly(zipFile, workFilePath, n)
+			if bomOnly && c.ignoreBOM {
+				c.tracef("equal %s: differs only by BOM", relPath)
+				c.stats.BOMOnly++
+				modified = false
+			}
+			encodingOnly := modified && !bomOnly && c.encodingOnly(zipFile, workFilePath, n)
+			if encodingOnly && c.ignoreEncoding {
+				c.tracef("equal %s: differs only by encoding", relPath)
+				c.stats.EncodingOnly++
+				modified = false
+			}
+			if modified && c.normalizedEqual(relPath, zipFile, workFilePath) {
+				c.tracef("equal %s: identical after normalization", relPath)
+				c.stats.NormalizedEqual++
+				modified = false
+			}
+			if modified {
 				// 文件已修改
-				result.Items = append(result.Items, models.DiffItem{
-					RelPath:    relPath,
-					Type:       "modified",
-					Selected:   true,
-					SourcePath: workFilePath,
-				})
+				item := models.DiffItem{
+					RelPath:      relPath,
+					Type:         "modified",
+					Selected:     true,
+					SourcePath:   workFilePath,
+					OldSize:      int64(zipFile.UncompressedSize64),
+					NewSize:      n,
+					Unstable:     unstable,
+					BOMOnly:      bomOnly,
+					EncodingOnly: encodingOnly,
+				}
+				if unstable {
+					c.tracef("unstable %s: file changed while reading", relPath)
+				}
+				c.countLineChanges(&item, zipFile)
+				if c.detectComments {
+					c.markCommentsOnly(&item, zipFile)
+				}
+				c.scanKeywords(&item, zipContent(zipFile))
+				result.Items = append(result.Items, item)
 				result.Modified++
+			} else {
+				c.addUnchanged(result, relPath, workFilePath, zipFile, n)
+			}
+		}
+
+		// 定期保存检查点
+		if cp != nil {
+			cp.Checked = append(cp.Checked, relPath)
+			cp.Items = append(cp.Items, result.Items[found:]...)
+			if time.Since(lastSave) >= c.checkpointInterval {
+				if err := cp.save(c.checkpointPath); err != nil {
+					c.tracef("save checkpoint failed: %v", err)
+				}
+				lastSave = time.Now()
 			}
 		}
 	}
@@ -223,37 +474,70 @@ func (c *Comparer) Compare() (*models.CompareResult, error) {
 	// 查找工作目录中新增的文件
 	for relPath, workFilePath := range workFiles {
 		if c.shouldExclude(relPath, false) {
+			if _, inZip := zipFiles[relPath]; !inZip {
+				result.Excluded++
+			}
 			continue
 		}
 
 		processed++
+		c.stats.FilesScanned++
 		c.emitProgress(processed, totalFiles, fmt.Sprintf("检查: %s", relPath))
 
 		// 统一路径分隔符
 		normalizedPath := filepath.ToSlash(relPath)
 		if _, exists := zipFiles[normalizedPath]; !exists {
 			// 这是新文件
-			result.Items = append(result.Items, models.DiffItem{
+			item := models.DiffItem{
 				RelPath:    relPath,
 				Type:       "added",
 				Selected:   true,
 				SourcePath: workFilePath,
-			})
+			}
+			if info, err := os.Stat(workFilePath); err == nil {
+				item.NewSize = info.Size()
+			}
+			c.scanKeywords(&item, nil)
+			result.Items = append(result.Items, item)
 			result.Added++
 		}
 	}
 
-	result.TotalFiles = len(result.Items)
+	c.addStreamItems(result, workFiles)
+
+	c.markGenerated(result)
+	result.TotalFiles = countDiffs(result)
+	result.Flagged = countFlagged(result.Items)
+	result.Largest = c.largest(result.Items)
+	sumSizes(result)
+	if cp != nil {
+		os.Remove(c.checkpointPath)
+	}
 	return result, nil
 }
 
 // shouldExclude 检查路径是否应该被排除
 func (c *Comparer) shouldExclude(path string, isDir bool) bool {
+	if c.gitignore != nil && c.gitignore.ShouldExclude(path, isDir) {
+		c.tracef("exclude %s: .gitignore", path)
+		return true
+	}
 	if c.excludeMatcher != nil {
-		return c.excludeMatcher.ShouldExclude(path, isDir)
+		rule, ok := c.excludeMatcher.MatchingRule(path, isDir)
+		switch {
+		case !ok:
+			c.tracef("include %s: no rule matched", path)
+		case rule.Negate:
+			c.tracef("include %s: negated by %s rule %q", path, rule.Type, rule.Pattern)
+		default:
+			c.tracef("exclude %s: %s rule %q", path, rule.Type, rule.Pattern)
+		}
+		return ok && !rule.Negate
 	}
 	// 如果没有设置排除规则，使用默认逻辑
-	return defaultShouldExclude(path)
+	excluded := defaultShouldExclude(path)
+	c.tracef("default rules %s: excluded=%t", path, excluded)
+	return excluded
 }
 
 // defaultShouldExclude 默认排除逻辑（向后兼容）
@@ -263,7 +547,7 @@ func defaultShouldExclude(path string) bool {
 
 	for _, part := range pathParts {
 		switch part {
-		case "obj", "bin", ".idea", ".vs", "My Project", "Service References", "Properties":
+		case "obj", "bin", ".idea", ".vs", ".svn", "My Project", "Service References", "Properties":
 			return true
 		}
 	}
@@ -281,77 +565,123 @@ func defaultShouldExclude(path string) bool {
 	return false
 }
 
-// getZipFileHash 计算 ZIP 中文件的哈希
-func (c *Comparer) getZipFileHash(f *zip.File) ([]byte, error) {
+// normalizedEqual 判断两侧内容规范化后是否相同，过大的文件不做判断
+func (c *Comparer) normalizedEqual(relPath string, f *zip.File, workFilePath string) bool {
+	if !c.normalizer.Applies(relPath) || f.UncompressedSize64 > uint64(maxNormalizeSize) {
+		return false
+	}
+
 	rc, err := f.Open()
 	if err != nil {
-		return nil, err
+		return false
 	}
-	defer rc.Close()
-
-	hash := md5.New()
-	if _, err := io.Copy(hash, rc); err != nil {
-		return nil, err
+	oldContent, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return false
 	}
-	return hash.Sum(nil), nil
+	newContent, size, err := readFileHead(workFilePath, maxNormalizeSize+1)
+	if err != nil || size > maxNormalizeSize {
+		return false
+	}
+	return bytes.Equal(c.normalizer.Normalize(relPath, oldContent), c.normalizer.Normalize(relPath, newContent))
 }
 
-// emitProgress 发送进度事件
-func (c *Comparer) emitProgress(current, total int, message string) {
-	if c.OnProgress != nil {
-		c.OnProgress(current, total, message)
+// countLineChanges 统计修改的文本文件新增和删除的行数，过大或非文本文件跳过
+func (c *Comparer) countLineChanges(item *models.DiffItem, f *zip.File) {
+	if !IsTextFile(item.RelPath) || f.UncompressedSize64 > uint64(maxLineCountSize) {
+		return
 	}
-}
 
-// getAllFilesAndDirs 获取目录下的所有文件和子目录
-func getAllFilesAndDirs(root string) (map[string]string, map[string]bool, error) {
-	files := make(map[string]string)
-	dirs := make(map[string]bool)
+	rc, err := f.Open()
+	if err != nil {
+		return
+	}
+	oldContent, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return
+	}
 
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	newContent, size, err := readFileHead(item.SourcePath, maxLineCountSize+1)
+	if err != nil || size > maxLineCountSize {
+		return
+	}
 
-		relPath, _ := filepath.Rel(root, path)
-		if relPath == "." {
-			return nil
-		}
+	if c.textDiffer == nil {
+		c.textDiffer = NewTextDiffer()
+	}
+	oldContent = c.normalizer.Normalize(item.RelPath, oldContent)
+	newContent = c.normalizer.Normalize(item.RelPath, newContent)
+	item.LinesAdded, item.LinesRemoved = c.textDiffer.CountLineChanges(string(oldContent), string(newContent))
+}
 
-		// 统一使用正斜杠
-		relPath = filepath.ToSlash(relPath)
+// failedItem 创建无法比较的差异项：列为修改但不选中，Error 说明原因
+func failedItem(relPath, sourcePath, reason string, err error) models.DiffItem {
+	return models.DiffItem{
+		RelPath:    relPath,
+		Type:       "modified",
+		Selected:   false,
+		SourcePath: sourcePath,
+		Error:      fmt.Sprintf("%s: %v", reason, err),
+	}
+}
 
-		if info.IsDir() {
-			dirs[relPath] = true
-		} else {
-			files[relPath] = path
+// MarkUnversioned 标记未纳入版本控制的差异项并统计数量
+func MarkUnversioned(result *models.CompareResult, isUnversioned func(relPath string) bool) {
+	result.Unversioned = 0
+	for i := range result.Items {
+		item := &result.Items[i]
+		if item.Type != "deleted" && isUnversioned(item.RelPath) {
+			item.Unversioned = true
+			result.Unversioned++
 		}
-		return nil
-	})
+	}
+}
 
-	return files, dirs, err
+// tracef 输出调试跟踪信息，未设置 OnTrace 时不做格式化
+func (c *Comparer) tracef(format string, args ...any) {
+	if c.OnTrace != nil {
+		c.OnTrace(fmt.Sprintf(format, args...))
+	}
 }
 
-// fileHash 计算文件的 MD5 哈希值
-func fileHash(filePath string) ([]byte, error) {
+// emitProgress 发送进度事件
+func (c *Comparer) emitProgress(current, total int, message string) {
+	if c.OnProgress != nil {
+		c.OnProgress(current, total, message)
+	}
+}
+
+// fileHash 计算文件的 MD5 哈希值，同时返回读取的字节数
+func fileHash(filePath string) ([]byte, int64, error) {
+	return fileChecksum(filePath, md5.New())
+}
+
+// fileChecksum 用 h 计算文件的校验和，同时返回读取的字节数
+func fileChecksum(filePath string, h hash.Hash) ([]byte, int64, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer file.Close()
 
-	hash := md5.New()
-	_, err = io.Copy(hash, file)
+	n, err := io.Copy(h, file)
 	if err != nil {
-		return nil, err
+		return nil, n, err
 	}
 
-	return hash.Sum(nil), nil
+	return h.Sum(nil), n, nil
 }
 
-// ExportDiffs 导出差异文件到输出目录
-func ExportDiffs(items []models.DiffItem, outputDir string, onProgress func(current, total int, message string)) error {
+// ExportDiffs 导出差异文件到输出目录，按 opts 在输出目录中写入校验和文件，保存基准 ZIP 和比较结果
+// 设置 opts.Journal 时记录导出进度，上次导出到同一目录中途失败时只导出剩余的文件
+// 设置 opts.Verify 时在写入校验和文件之前重新读取每个导出的文件校验内容（不含备用数据流）
+func ExportDiffs(items []models.DiffItem, outputDir string, opts ExportOptions, onProgress func(current, total int, message string)) error {
 	// 创建输出目录
+	if err := ValidateEOL(opts.EOL); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
@@ -363,31 +693,122 @@ func ExportDiffs(items []models.DiffItem, outputDir string, onProgress func(curr
 		}
 	}
 
+	if err := checkChangelog(selectedItems, opts); err != nil {
+		return err
+	}
+	if err := checkEvidence(itemPaths(selectedItems), opts); err != nil {
+		return err
+	}
+
+	journal := openJournal(opts.Journal, outputDir, opts)
+	defer journal.save()
+
+	sums := newChecksumSet(opts)
+	written := make([]exportedFile, 0, len(selectedItems))
 	for i, item := range selectedItems {
+		destPath := filepath.Join(outputDir, item.RelPath)
+		if entry, ok := journal.completed(item, destPath); ok {
+			// 上次中断的导出中已完成，跳过复制
+			if onProgress != nil {
+				onProgress(i+1, len(selectedItems), fmt.Sprintf("已导出，跳过: %s", item.RelPath))
+			}
+			sums.addSums(filepath.ToSlash(item.RelPath), entry.SHA256, entry.MD5)
+			written = append(written, exportedFile{relPath: item.RelPath, path: destPath, sha256: entry.SHA256})
+			continue
+		}
 		if onProgress != nil {
 			onProgress(i+1, len(selectedItems), fmt.Sprintf("导出: %s", item.RelPath))
 		}
 
-		destPath := filepath.Join(outputDir, item.RelPath)
-		if err := copyFile(item.SourcePath, destPath); err != nil {
-			return fmt.Errorf("failed to copy file %s: %w", item.RelPath, err)
+		src, err := openExport(item.SourcePath, item.RelPath, opts.EOL)
+		if err != nil {
+			return &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to copy file: %w", err)}
 		}
+		digest := newFileDigest()
+		err = writeFile(src, destPath, digest)
+		src.Close()
+		if err != nil {
+			return &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to copy file: %w", err)}
+		}
+
+		// 备用数据流写入目标文件的同名数据流（仅 NTFS）
+		for _, stream := range item.Streams {
+			if !stream.Selected {
+				continue
+			}
+			if err := copyFile(stream.SourcePath, destPath+":"+stream.Stream); err != nil {
+				return &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to copy stream %s: %w", stream.Stream, err)}
+			}
+		}
+
+		sha256Hex, md5Hex := digest.sums()
+		sums.addSums(filepath.ToSlash(item.RelPath), sha256Hex, md5Hex)
+		journal.record(item, destPath, sha256Hex, md5Hex)
+		written = append(written, exportedFile{relPath: item.RelPath, path: destPath, sha256: sha256Hex})
+	}
+
+	// 写入校验和和签名之前校验，损坏的导出不会被签名；不一致的文件从进度记录中删除，再次导出时重新复制
+	if opts.Verify {
+		if mismatched := verifyExported(written, onProgress); len(mismatched) > 0 {
+			for _, relPath := range mismatched {
+				journal.forget(relPath)
+			}
+			return &VerifyError{Files: mismatched}
+		}
+	}
+
+	if err := archiveEvidence(outputDir, opts, sums, onProgress); err != nil {
+		return err
+	}
+	if opts.Changelog != "" {
+		if err := os.WriteFile(filepath.Join(outputDir, ChangelogName), []byte(opts.Changelog), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", ChangelogName, err)
+		}
+		io.WriteString(sums.add(ChangelogName), opts.Changelog)
 	}
 
+	for name, content := range sums.contents() {
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if opts.SigningKey != "" {
+		if _, err := signing.SignFile(filepath.Join(outputDir, SHA256SumsName), opts.SigningKey); err != nil {
+			return fmt.Errorf("failed to sign %s: %w", SHA256SumsName, err)
+		}
+	}
+	journal.finish()
 	return nil
 }
 
-// copyFile 复制文件到目标路径
-func copyFile(src, dest string) error {
-	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
-		return err
+// checkChangelog 写入变更日志时，确认导出的文件中没有同名文件
+func checkChangelog(items []models.DiffItem, opts ExportOptions) error {
+	if opts.Changelog == "" {
+		return nil
+	}
+	for _, item := range items {
+		if strings.EqualFold(filepath.ToSlash(item.RelPath), ChangelogName) {
+			return ErrChangelogConflict
+		}
 	}
+	return nil
+}
 
+// copyFile 复制文件到目标路径，内容同时写入 extra（如用于计算校验和）
+func copyFile(src, dest string, extra ...io.Writer) error {
 	srcFile, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer srcFile.Close()
+	return writeFile(srcFile, dest, extra...)
+}
+
+// writeFile 将 r 的内容写入目标路径，同时写入 extra
+func writeFile(r io.Reader, dest string, extra ...io.Writer) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
 
 	destFile, err := os.Create(dest)
 	if err != nil {
@@ -395,7 +816,7 @@ func copyFile(src, dest string) error {
 	}
 	defer destFile.Close()
 
-	_, err = io.Copy(destFile, srcFile)
+	_, err = io.Copy(io.MultiWriter(append([]io.Writer{destFile}, extra...)...), r)
 	return err
 }
 
@@ -457,14 +878,59 @@ func CreateZip(sourceDir, zipPath string) error {
 	})
 }
 
-// GenerateZipName 生成 ZIP 文件名
-func GenerateZipName(baseName string) string {
-	currentTime := time.Now()
-	return fmt.Sprintf("%s_差分_%s.zip", baseName, currentTime.Format("2006年01月02日"))
+// ExportDiffsToZip 直接将差异文件导出为 ZIP（不创建中间文件夹）
+// 按 opts 在 ZIP 根目录写入校验和文件；指定收件人时整个 ZIP 以 age 格式加密写入 zipPath
+// 设置 opts.Verify 时在签名之前重新读取写入的 ZIP 文件，与写入的内容比较；基准 ZIP 和比较结果保存在 ZIP 所在目录
+func ExportDiffsToZip(items []models.DiffItem, zipPath string, opts ExportOptions, onProgress func(current, total int, message string)) error {
+	selectedItems, err := zipExportItems(items, opts)
+	if err != nil {
+		return err
+	}
+	if err := checkEvidence([]string{filepath.Base(zipPath)}, opts); err != nil {
+		return err
+	}
+
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to create zip file: %w", err)
+	}
+	defer zipFile.Close()
+
+	// 导出后校验时，边写边计算实际写入文件的内容（加密时为密文）的哈希
+	written := sha256.New()
+	var dest io.Writer = zipFile
+	if opts.Verify {
+		dest = io.MultiWriter(zipFile, written)
+	}
+
+	digest, err := writeExportZip(dest, selectedItems, opts, onProgress)
+	if err != nil {
+		return err
+	}
+	if err := zipFile.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip file: %w", err)
+	}
+	if opts.Verify {
+		exported := exportedFile{relPath: filepath.Base(zipPath), path: zipPath, sha256: hex.EncodeToString(written.Sum(nil))}
+		if mismatched := verifyExported([]exportedFile{exported}, onProgress); len(mismatched) > 0 {
+			return &VerifyError{Files: mismatched}
+		}
+	}
+	if err := archiveEvidence(filepath.Dir(zipPath), opts, nil, onProgress); err != nil {
+		return err
+	}
+
+	if opts.SigningKey != "" {
+		sigPath := strings.TrimSuffix(zipPath, encrypt.FileExt) + signing.SignatureExt
+		if err := signing.SignDigest(digest, opts.SigningKey, sigPath); err != nil {
+			return fmt.Errorf("failed to sign zip file: %w", err)
+		}
+	}
+	return nil
 }
 
-// ExportDiffsToZip 直接将差异文件导出为 ZIP（不创建中间文件夹）
-func ExportDiffsToZip(items []models.DiffItem, zipPath string, onProgress func(current, total int, message string)) error {
+// zipExportItems 返回打包为 ZIP 的差异项（选中的、未删除的），并检查导出选项
+func zipExportItems(items []models.DiffItem, opts ExportOptions) ([]models.DiffItem, error) {
 	selectedItems := make([]models.DiffItem, 0)
 	for _, item := range items {
 		if item.Selected && item.Type != "deleted" {
@@ -473,40 +939,61 @@ func ExportDiffsToZip(items []models.DiffItem, zipPath string, onProgress func(c
 	}
 
 	if len(selectedItems) == 0 {
-		return fmt.Errorf("没有选中的文件")
+		return nil, ErrNothingSelected
+	}
+	if err := checkChangelog(selectedItems, opts); err != nil {
+		return nil, err
 	}
+	if err := ValidateEOL(opts.EOL); err != nil {
+		return nil, err
+	}
+	return selectedItems, nil
+}
 
-	zipFile, err := os.Create(zipPath)
-	if err != nil {
-		return fmt.Errorf("failed to create zip file: %w", err)
+// writeExportZip 将差异文件、变更日志和校验和文件打包写入 dest，指定收件人时加密
+// 设置签名密钥时返回明文 ZIP 的 SHA-512 摘要，供调用方生成签名
+func writeExportZip(dest io.Writer, selectedItems []models.DiffItem, opts ExportOptions, onProgress func(current, total int, message string)) ([]byte, error) {
+	// 加密时 ZIP 直接写入加密流，明文不落盘；签名针对明文 ZIP，边写边计算摘要
+	var out io.Writer = dest
+	var encrypted io.WriteCloser
+	var err error
+	if len(opts.Recipients) > 0 {
+		if encrypted, err = encrypt.Encrypt(dest, opts.Recipients); err != nil {
+			return nil, fmt.Errorf("failed to encrypt zip file: %w", err)
+		}
+		out = encrypted
+	}
+	digest := sha512.New()
+	if opts.SigningKey != "" {
+		out = io.MultiWriter(out, digest)
 	}
-	defer zipFile.Close()
 
-	writer := zip.NewWriter(zipFile)
+	writer := zip.NewWriter(out)
 	defer writer.Close()
 
+	sums := newChecksumSet(opts)
 	for i, item := range selectedItems {
 		if onProgress != nil {
 			onProgress(i+1, len(selectedItems), fmt.Sprintf("打包: %s", item.RelPath))
 		}
 
-		// 读取源文件
-		file, err := os.Open(item.SourcePath)
+		// 读取源文件（按 opts.EOL 转换换行符）
+		file, err := openExport(item.SourcePath, item.RelPath, opts.EOL)
 		if err != nil {
-			return fmt.Errorf("failed to open file %s: %w", item.RelPath, err)
+			return nil, &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to open file: %w", err)}
 		}
 
-		info, err := file.Stat()
+		info, err := os.Stat(item.SourcePath)
 		if err != nil {
 			file.Close()
-			return fmt.Errorf("failed to stat file %s: %w", item.RelPath, err)
+			return nil, &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to stat file: %w", err)}
 		}
 
 		// 创建 ZIP 条目
 		header, err := zip.FileInfoHeader(info)
 		if err != nil {
 			file.Close()
-			return fmt.Errorf("failed to create header for %s: %w", item.RelPath, err)
+			return nil, fmt.Errorf("failed to create header for %s: %w", item.RelPath, err)
 		}
 		header.Name = filepath.ToSlash(item.RelPath)
 		header.Method = zip.Deflate
@@ -514,15 +1001,43 @@ func ExportDiffsToZip(items []models.DiffItem, zipPath string, onProgress func(c
 		w, err := writer.CreateHeader(header)
 		if err != nil {
 			file.Close()
-			return fmt.Errorf("failed to create zip entry for %s: %w", item.RelPath, err)
+			return nil, fmt.Errorf("failed to create zip entry for %s: %w", item.RelPath, err)
 		}
 
-		_, err = io.Copy(w, file)
+		_, err = io.Copy(io.MultiWriter(w, sums.add(header.Name)), file)
 		file.Close()
 		if err != nil {
-			return fmt.Errorf("failed to write file %s to zip: %w", item.RelPath, err)
+			return nil, &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to write file to zip: %w", err)}
 		}
 	}
 
-	return nil
+	if opts.Changelog != "" {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: ChangelogName, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zip entry for %s: %w", ChangelogName, err)
+		}
+		if _, err := io.WriteString(io.MultiWriter(w, sums.add(ChangelogName)), opts.Changelog); err != nil {
+			return nil, fmt.Errorf("failed to write %s to zip: %w", ChangelogName, err)
+		}
+	}
+
+	for name, content := range sums.contents() {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zip entry for %s: %w", name, err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			return nil, fmt.Errorf("failed to write %s to zip: %w", name, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish zip file: %w", err)
+	}
+	if encrypted != nil {
+		if err := encrypted.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encrypt zip file: %w", err)
+		}
+	}
+	return digest.Sum(nil), nil
 }
@@ -0,0 +1,58 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"path/filepath"
+	"strings"
+)
+
+// 以 ZIP 格式打包的应用容器类型，可直接作为基准
+const (
+	ContainerAPK  = "apk"
+	ContainerIPA  = "ipa"
+	ContainerVSIX = "vsix"
+)
+
+// containerExtensions 按扩展名识别的容器类型
+var containerExtensions = map[string]string{
+	".apk":  ContainerAPK,
+	".ipa":  ContainerIPA,
+	".vsix": ContainerVSIX,
+}
+
+// containerExcludeRules 各类容器的默认排除规则：签名和打包时生成、构建输出中没有的文件
+var containerExcludeRules = map[string][]models.ExcludeRule{
+	ContainerAPK: {
+		{Pattern: "/META-INF", Type: "glob", IsDir: true, Enabled: true, Comment: "APK 签名（MANIFEST.MF、*.SF、*.RSA）"},
+		{Pattern: "/stamp-cert-sha256", Type: "glob", IsDir: false, Enabled: true, Comment: "APK 源戳签名"},
+	},
+	ContainerIPA: {
+		{Pattern: "_CodeSignature", Type: "glob", IsDir: true, Enabled: true, Comment: "代码签名"},
+		{Pattern: "SC_Info", Type: "glob", IsDir: true, Enabled: true, Comment: "App Store 加密信息"},
+		{Pattern: "embedded.mobileprovision", Type: "glob", IsDir: false, Enabled: true, Comment: "描述文件"},
+		{Pattern: "/META-INF", Type: "glob", IsDir: true, Enabled: true, Comment: "IPA 打包元数据"},
+		{Pattern: "/iTunesMetadata.plist", Type: "glob", IsDir: false, Enabled: true, Comment: "App Store 元数据"},
+		{Pattern: "/iTunesArtwork", Type: "glob", IsDir: false, Enabled: true, Comment: "App Store 图标"},
+	},
+	ContainerVSIX: {
+		{Pattern: `^\[Content_Types\]\.xml$`, Type: "regex", IsDir: false, Enabled: true, Comment: "OPC 内容类型清单"},
+		{Pattern: "/_rels", Type: "glob", IsDir: true, Enabled: true, Comment: "OPC 关系"},
+		{Pattern: "/package/services/digital-signature", Type: "glob", IsDir: true, Enabled: true, Comment: "VSIX 签名"},
+	},
+}
+
+// ContainerKind 按扩展名返回基准的容器类型（apk、ipa、vsix），普通 ZIP 返回空字符串
+func ContainerKind(path string) string {
+	return containerExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// ContainerExcludeRules 返回容器类型的默认排除规则的副本，普通 ZIP 返回 nil
+func ContainerExcludeRules(kind string) []models.ExcludeRule {
+	rules := containerExcludeRules[kind]
+	if len(rules) == 0 {
+		return nil
+	}
+	copied := make([]models.ExcludeRule, len(rules))
+	copy(copied, rules)
+	return copied
+}
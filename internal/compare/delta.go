@@ -0,0 +1,470 @@
+package compare
+
+import (
+	"Discrepancies/internal/bindiff"
+	"Discrepancies/internal/models"
+	"archive/zip"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeltaManifestName 差分包中记录已删除文件的清单（每行一个相对路径）
+const DeltaManifestName = ".discrepancies-deleted.txt"
+
+// DeltaIndexName 差分包索引，记录每个文件的处理方式和新旧版本的 MD5，供应用时校验
+const DeltaIndexName = ".discrepancies-delta.json"
+
+// DeltaPatchDir 差分包中存放二进制补丁的目录，补丁名为相对路径加 .patch
+const DeltaPatchDir = ".discrepancies-patches"
+
+// DefaultPatchMinSize 默认的补丁大小下限，小于该大小的修改文件直接保存完整内容
+const DefaultPatchMinSize = 1 << 20
+
+// DeltaOptions 生成差分包的选项
+type DeltaOptions struct {
+	PatchMinSize int64 // 不小于该大小（字节）的修改文件保存为二进制补丁，0 表示不使用补丁
+}
+
+// 差分包中文件的处理方式
+const (
+	deltaAdd     = "add"     // 新增，条目为完整内容
+	deltaReplace = "replace" // 修改，条目为完整内容
+	deltaPatch   = "patch"   // 修改，条目为二进制补丁
+	deltaDelete  = "delete"  // 删除
+)
+
+// deltaIndex 差分包索引
+type deltaIndex struct {
+	Files []deltaFile `json:"files"` // 差异文件
+}
+
+// deltaFile 差分包中的一个差异文件
+type deltaFile struct {
+	Path    string `json:"path"`    // 相对路径
+	Action  string `json:"action"`  // add | replace | patch | delete
+	Entry   string `json:"entry"`   // 内容在差分包中的条目名（delete 为空）
+	OldHash string `json:"oldHash"` // 旧版本的 MD5（add 为空）
+	NewHash string `json:"newHash"` // 新版本的 MD5（delete 为空）
+	Size    int64  `json:"size"`    // 新版本的大小
+}
+
+// ErrNoDifferences 两个 ZIP 的内容相同，没有可打包的差异
+var ErrNoDifferences = errors.New("archives have no differences")
+
+// CompareArchives 比较旧 ZIP 和新 ZIP，返回新 ZIP 相对旧 ZIP 的差异
+// 大小和 CRC32 均相同的文件视为未修改；差异项没有 SourcePath，内容在新 ZIP 中
+func CompareArchives(oldZipPath, newZipPath string, rules []models.ExcludeRule) (*models.CompareResult, error) {
+	oldReader, err := NewZipReader(oldZipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer oldReader.Close()
+
+	newReader, err := NewZipReader(newZipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer newReader.Close()
+
+	oldFiles, err := oldReader.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := newReader.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := NewExcludeMatcher(rules)
+	result := &models.CompareResult{Items: make([]models.DiffItem, 0)}
+
+	for relPath, f := range newFiles {
+		if matcher.ShouldExclude(relPath, false) {
+			continue
+		}
+		result.TotalFiles++
+
+		old, exists := oldFiles[relPath]
+		switch {
+		case !exists:
+			result.Items = append(result.Items, models.DiffItem{RelPath: relPath, Type: "added", Selected: true, NewSize: int64(f.UncompressedSize64)})
+			result.Added++
+		case old.UncompressedSize64 != f.UncompressedSize64 || old.CRC32 != f.CRC32:
+			result.Items = append(result.Items, models.DiffItem{RelPath: relPath, Type: "modified", Selected: true,
+				OldSize: int64(old.UncompressedSize64), NewSize: int64(f.UncompressedSize64)})
+			result.Modified++
+		}
+	}
+
+	for relPath, old := range oldFiles {
+		if _, exists := newFiles[relPath]; exists || matcher.ShouldExclude(relPath, false) {
+			continue
+		}
+		result.Items = append(result.Items, models.DiffItem{RelPath: relPath, Type: "deleted", Selected: true, OldSize: int64(old.UncompressedSize64)})
+		result.Deleted++
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].RelPath < result.Items[j].RelPath
+	})
+	sumSizes(result)
+	return result, nil
+}
+
+// CreateDelta 比较两个 ZIP，将新增和修改的文件及删除清单打包为差分 ZIP
+// 按 opts 将较大的修改文件保存为相对旧版本的二进制补丁，补丁不小于完整内容时仍保存完整内容
+func CreateDelta(oldZipPath, newZipPath, deltaPath string, rules []models.ExcludeRule, opts DeltaOptions, onProgress func(current, total int, message string)) (*models.CompareResult, error) {
+	result, err := CompareArchives(oldZipPath, newZipPath, rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Items) == 0 {
+		return result, ErrNoDifferences
+	}
+
+	oldReader, err := NewZipReader(oldZipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer oldReader.Close()
+
+	newReader, err := NewZipReader(newZipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer newReader.Close()
+
+	oldFiles, err := oldReader.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := newReader.ListFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []deltaChange
+	for _, item := range result.Items {
+		var newSource deltaSource
+		if f := newFiles[item.RelPath]; f != nil {
+			newSource = zipSource{f}
+		}
+		changes = append(changes, deltaChange{item.RelPath, item.Type, oldFiles[item.RelPath], newSource})
+	}
+	if err := writeDelta(deltaPath, changes, opts, onProgress); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExportDelta 将基准 ZIP 与工作目录比较得到的差异项打包为差分 ZIP，可用 ApplyDelta 应用到与基准一致的目录
+// 按 opts 将较大的修改文件保存为相对基准版本的二进制补丁；重命名记录为删除旧路径和新增新路径，未选中和读取失败的文件不打包
+func ExportDelta(baseline *ZipReader, items []models.DiffItem, deltaPath string, opts DeltaOptions, onProgress func(current, total int, message string)) error {
+	oldFiles, err := baseline.ListFiles()
+	if err != nil {
+		return err
+	}
+
+	var changes []deltaChange
+	for _, item := range items {
+		if !item.Selected || item.Error != "" || item.Stream != "" {
+			continue
+		}
+		relPath := filepath.ToSlash(item.RelPath)
+		switch item.Type {
+		case "added", "modified":
+			changes = append(changes, deltaChange{relPath, item.Type, oldFiles[relPath], fileSource(item.SourcePath)})
+		case "deleted":
+			changes = append(changes, deltaChange{relPath, item.Type, oldFiles[relPath], nil})
+		case "renamed":
+			oldPath := filepath.ToSlash(item.OldPath)
+			changes = append(changes,
+				deltaChange{oldPath, "deleted", oldFiles[oldPath], nil},
+				deltaChange{relPath, "added", nil, fileSource(item.SourcePath)})
+		}
+	}
+	if len(changes) == 0 {
+		return ErrNothingSelected
+	}
+	return writeDelta(deltaPath, changes, opts, onProgress)
+}
+
+// deltaChange 写入差分包的一个差异文件
+type deltaChange struct {
+	relPath string
+	typ     string      // added | modified | deleted
+	old     *zip.File   // 旧版本（新增时为 nil）
+	new     deltaSource // 新版本（删除时为 nil）
+}
+
+// writeDelta 将差异文件、索引和删除清单写入差分 ZIP
+func writeDelta(deltaPath string, changes []deltaChange, opts DeltaOptions, onProgress func(current, total int, message string)) error {
+	zipFile, err := os.Create(deltaPath)
+	if err != nil {
+		return fmt.Errorf("failed to create zip file: %w", err)
+	}
+	defer zipFile.Close()
+
+	writer := zip.NewWriter(zipFile)
+	defer writer.Close()
+
+	var deleted []string
+	var index deltaIndex
+	for i, change := range changes {
+		if onProgress != nil {
+			onProgress(i+1, len(changes), fmt.Sprintf("打包: %s", change.relPath))
+		}
+		entry, err := writeDeltaEntry(writer, change, opts)
+		if err != nil {
+			return fmt.Errorf("failed to write file %s to zip: %w", change.relPath, err)
+		}
+		if entry.Action == deltaDelete {
+			deleted = append(deleted, change.relPath)
+		}
+		index.Files = append(index.Files, entry)
+	}
+
+	w, err := writer.CreateHeader(&zip.FileHeader{Name: DeltaIndexName, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to create delta index: %w", err)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(index); err != nil {
+		return fmt.Errorf("failed to write delta index: %w", err)
+	}
+
+	if len(deleted) > 0 {
+		w, err := writer.CreateHeader(&zip.FileHeader{Name: DeltaManifestName, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return fmt.Errorf("failed to create deletion manifest: %w", err)
+		}
+		if _, err := io.WriteString(w, strings.Join(deleted, "\n")+"\n"); err != nil {
+			return fmt.Errorf("failed to write deletion manifest: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip file: %w", err)
+	}
+	return nil
+}
+
+// writeDeltaEntry 将一个差异文件写入差分包，返回其索引记录
+func writeDeltaEntry(writer *zip.Writer, change deltaChange, opts DeltaOptions) (deltaFile, error) {
+	entry := deltaFile{Path: change.relPath}
+	var err error
+	if change.old != nil {
+		if entry.OldHash, err = zipFileHash(change.old); err != nil {
+			return entry, err
+		}
+	}
+
+	switch change.typ {
+	case "deleted":
+		entry.Action = deltaDelete
+		return entry, nil
+	case "added":
+		entry.Action = deltaAdd
+	default:
+		entry.Action = deltaReplace
+		if usePatch(opts, change.old, change.new) {
+			patched, ok, err := writePatch(writer, change.old, change.new, change.relPath)
+			if err != nil || ok {
+				return patched, err
+			}
+		}
+	}
+
+	entry.Entry = change.relPath
+	entry.NewHash, entry.Size, err = change.new.write(writer, change.relPath)
+	return entry, err
+}
+
+// usePatch 判断修改的文件是否尝试保存为二进制补丁
+func usePatch(opts DeltaOptions, oldFile *zip.File, newSource deltaSource) bool {
+	if opts.PatchMinSize <= 0 || oldFile == nil {
+		return false
+	}
+	size, err := newSource.size()
+	return err == nil && size >= opts.PatchMinSize &&
+		oldFile.UncompressedSize64 <= bindiff.MaxFileSize && size <= bindiff.MaxFileSize
+}
+
+// writePatch 生成二进制补丁写入差分包，补丁不小于新版本在差分包中保存完整内容的大小时不写入并返回 false
+func writePatch(writer *zip.Writer, oldFile *zip.File, newSource deltaSource, relPath string) (deltaFile, bool, error) {
+	oldData, err := readZipFile(oldFile)
+	if err != nil {
+		return deltaFile{}, false, err
+	}
+	newData, modified, err := newSource.read()
+	if err != nil {
+		return deltaFile{}, false, err
+	}
+
+	var patch bytes.Buffer
+	if err := bindiff.Diff(oldData, newData, &patch); err != nil {
+		return deltaFile{}, false, err
+	}
+	if int64(patch.Len()) >= newSource.storedSize(int64(len(newData))) {
+		return deltaFile{}, false, nil
+	}
+
+	name := path.Join(DeltaPatchDir, relPath+".patch")
+	// 补丁已压缩，不再压缩
+	w, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: modified})
+	if err != nil {
+		return deltaFile{}, false, err
+	}
+	if _, err := w.Write(patch.Bytes()); err != nil {
+		return deltaFile{}, false, err
+	}
+
+	oldHash, newHash := md5.Sum(oldData), md5.Sum(newData)
+	return deltaFile{
+		Path:    relPath,
+		Action:  deltaPatch,
+		Entry:   name,
+		OldHash: hex.EncodeToString(oldHash[:]),
+		NewHash: hex.EncodeToString(newHash[:]),
+		Size:    int64(len(newData)),
+	}, true, nil
+}
+
+// deltaSource 差分包中新版本的内容：新基准 ZIP 中的条目或工作目录中的文件
+type deltaSource interface {
+	size() (int64, error)
+	// read 读取全部内容和修改时间，用于生成补丁
+	read() ([]byte, time.Time, error)
+	// storedSize 不使用补丁时在差分包中占用的大小，n 为内容的大小
+	storedSize(n int64) int64
+	// write 将完整内容写入差分包的 relPath 条目，返回写入内容的 MD5（十六进制）和大小
+	write(writer *zip.Writer, relPath string) (string, int64, error)
+}
+
+// zipSource 新基准 ZIP 中的条目，不解压直接复制
+type zipSource struct {
+	f *zip.File
+}
+
+func (s zipSource) size() (int64, error) {
+	return int64(s.f.UncompressedSize64), nil
+}
+
+func (s zipSource) read() ([]byte, time.Time, error) {
+	data, err := readZipFile(s.f)
+	return data, s.f.Modified, err
+}
+
+func (s zipSource) storedSize(int64) int64 {
+	return int64(s.f.CompressedSize64)
+}
+
+func (s zipSource) write(writer *zip.Writer, relPath string) (string, int64, error) {
+	hash, err := zipFileHash(s.f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hash, int64(s.f.UncompressedSize64), copyZipEntry(writer, s.f, relPath)
+}
+
+// fileSource 工作目录中的文件，写入时同时计算 MD5，使索引与写入的内容一致
+type fileSource string
+
+func (s fileSource) size() (int64, error) {
+	info, err := os.Stat(string(s))
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (s fileSource) read() ([]byte, time.Time, error) {
+	info, err := os.Stat(string(s))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(string(s))
+	return data, info.ModTime(), err
+}
+
+// storedSize 压缩前的大小，压缩后的大小要写入时才知道
+func (s fileSource) storedSize(n int64) int64 {
+	return n
+}
+
+func (s fileSource) write(writer *zip.Writer, relPath string) (string, int64, error) {
+	file, err := os.Open(string(s))
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+
+	w, err := writer.CreateHeader(&zip.FileHeader{Name: relPath, Method: zip.Deflate, Modified: info.ModTime()})
+	if err != nil {
+		return "", 0, err
+	}
+	hash := md5.New()
+	n, err := io.Copy(io.MultiWriter(w, hash), file)
+	return hex.EncodeToString(hash.Sum(nil)), n, err
+}
+
+// readZipFile 读取 ZIP 条目的全部内容
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// zipFileHash 计算 ZIP 条目内容的 MD5（十六进制）
+func zipFileHash(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyZipEntry 不解压直接复制 ZIP 条目的压缩数据，使用去除根目录后的路径作为条目名
+func copyZipEntry(writer *zip.Writer, f *zip.File, relPath string) error {
+	header := f.FileHeader
+	header.Name = relPath
+	header.Extra = nil
+	header.NonUTF8 = false
+	header.Flags |= utf8Flag
+
+	r, err := f.OpenRaw()
+	if err != nil {
+		return err
+	}
+	w, err := writer.CreateRaw(&header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
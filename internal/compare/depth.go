@@ -0,0 +1,32 @@
+package compare
+
+import "strings"
+
+// SetMaxDepth 限制扫描的目录层数，只比较前 depth 层中的文件（1 表示只比较最上层的文件），0 或负数表示不限制
+// 设置了子目录时从子目录开始计算层数；ZIP 和工作目录按相同的层数截断，更深的文件不会被视为删除或新增
+func (c *Comparer) SetMaxDepth(depth int) {
+	c.maxDepth = max(depth, 0)
+}
+
+// withinMaxDepth 判断相对于工作目录的路径是否在 SetMaxDepth 限制的层数内（从子目录开始计算）
+func (c *Comparer) withinMaxDepth(relPath string) bool {
+	return c.maxDepth == 0 || pathDepth(relPath) <= pathDepth(c.subPath)+c.maxDepth
+}
+
+// SetMaxDepth 限制 ListFiles 和 ListDirs 列出的层数（相对于根目录），0 或负数表示不限制
+func (z *ZipReader) SetMaxDepth(depth int) {
+	z.maxDepth = max(depth, 0)
+}
+
+// withinDepth 判断相对于根目录的路径是否在限制的层数内
+func (z *ZipReader) withinDepth(relPath string) bool {
+	return z.maxDepth == 0 || pathDepth(relPath) <= z.maxDepth
+}
+
+// pathDepth 返回正斜杠形式的相对路径所在的层数，最上层的条目为 1，空路径为 0
+func pathDepth(relPath string) int {
+	if relPath == "" {
+		return 0
+	}
+	return strings.Count(relPath, "/") + 1
+}
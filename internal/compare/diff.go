@@ -2,24 +2,55 @@ package compare
 
 import (
 	"Discrepancies/internal/models"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
 	"os"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
+// DefaultMaxPreviewSize 默认的预览大小上限（1 MB）
+const DefaultMaxPreviewSize int64 = 1 << 20
+
 // TextDiffer 文本差异比较器
 type TextDiffer struct {
-	dmp *diffmatchpatch.DiffMatchPatch
+	dmp            *diffmatchpatch.DiffMatchPatch
+	maxPreviewSize int64
+	normalizer     *Normalizer // 规范化规则，预览与比较使用相同的规范化
+	cache          *DiffCache  // 差异缓存，为 nil 时不缓存
+	cacheOptions   string      // 影响差异结果的其他选项（如规范化规则）的摘要，作为缓存键的一部分
 }
 
 // NewTextDiffer 创建新的文本差异比较器
 func NewTextDiffer() *TextDiffer {
 	return &TextDiffer{
-		dmp: diffmatchpatch.New(),
+		dmp:            diffmatchpatch.New(),
+		maxPreviewSize: DefaultMaxPreviewSize,
+	}
+}
+
+// SetMaxPreviewSize 设置预览大小上限（字节），超过上限的文件只比较开头部分
+func (d *TextDiffer) SetMaxPreviewSize(size int64) {
+	if size > 0 {
+		d.maxPreviewSize = size
 	}
 }
 
+// SetNormalizer 设置规范化器，CompareFiles 比较前对两侧内容执行规范化
+func (d *TextDiffer) SetNormalizer(n *Normalizer) {
+	d.normalizer = n
+}
+
+// SetCache 设置差异缓存；options 为规范化规则等影响结果的选项的摘要，选项不同的结果不会互相命中
+func (d *TextDiffer) SetCache(cache *DiffCache, options string) {
+	d.cache = cache
+	d.cacheOptions = options
+}
+
 // CompareTexts 比较两段文本并返回差异结果
 func (d *TextDiffer) CompareTexts(oldText, newText string) *models.TextDiff {
 	diffs := d.dmp.DiffMain(oldText, newText, true)
@@ -32,6 +63,7 @@ func (d *TextDiffer) CompareTexts(oldText, newText string) *models.TextDiff {
 	}
 
 	// 转换为行级别的差异
+	newLine := 1
 	for _, diff := range diffs {
 		lines := strings.Split(diff.Text, "\n")
 		for i, line := range lines {
@@ -50,31 +82,134 @@ func (d *TextDiffer) CompareTexts(oldText, newText string) *models.TextDiff {
 				diffType = "equal"
 			}
 
+			lineNo := 0
+			if diff.Type != diffmatchpatch.DiffDelete {
+				lineNo = newLine + i
+			}
+
 			result.Lines = append(result.Lines, models.DiffLine{
 				Type:    diffType,
 				Content: line,
+				NewLine: lineNo,
 			})
 		}
+
+		if diff.Type != diffmatchpatch.DiffDelete {
+			newLine += len(lines) - 1
+		}
 	}
 
 	return result
 }
 
 // CompareFiles 比较 ZIP 中的文件和工作目录中的文件
+// 任一侧超过预览大小上限时，只比较两侧开头的部分并设置 Truncated 标记
+// 设置了缓存时，基准一侧按 ZIP 中记录的 CRC32 查找，命中时不读取基准也不重新比较
 func (d *TextDiffer) CompareFiles(zipReader *ZipReader, relPath, workFilePath string) (*models.TextDiff, error) {
-	// 读取 ZIP 中的文件内容
-	oldContent, err := zipReader.ReadFileContent(relPath)
+	// 读取工作目录中的文件内容
+	newContent, newSize, err := readFileHead(workFilePath, d.maxPreviewSize)
 	if err != nil {
 		return nil, err
 	}
 
-	// 读取工作目录中的文件内容
-	newContent, err := os.ReadFile(workFilePath)
+	var cacheKey string
+	if d.cache != nil {
+		if files, err := zipReader.ListFiles(); err == nil && files[relPath] != nil {
+			f := files[relPath]
+			newSum := sha256.Sum256(newContent)
+			cacheKey = diffCacheKey(relPath,
+				fmt.Sprintf("%08x:%d", f.CRC32, f.UncompressedSize64),
+				fmt.Sprintf("%x:%d", newSum, newSize),
+				fmt.Sprintf("%d:%s", d.maxPreviewSize, d.cacheOptions))
+			if diff, ok := d.cache.get(cacheKey); ok {
+				return diff, nil
+			}
+		}
+	}
+
+	// 读取 ZIP 中的文件内容
+	oldContent, oldSize, err := zipReader.ReadFileHead(relPath, d.maxPreviewSize)
 	if err != nil {
 		return nil, err
 	}
 
-	return d.CompareTexts(string(oldContent), string(newContent)), nil
+	truncated := oldSize > d.maxPreviewSize || newSize > d.maxPreviewSize
+	if truncated {
+		// 截断到最后一个完整行，避免末尾出现半行差异
+		oldContent = trimToLastLine(oldContent, oldSize > d.maxPreviewSize)
+		newContent = trimToLastLine(newContent, newSize > d.maxPreviewSize)
+	}
+
+	oldContent = d.normalizer.Normalize(relPath, oldContent)
+	newContent = d.normalizer.Normalize(relPath, newContent)
+	result := d.CompareTexts(string(oldContent), string(newContent))
+	result.Truncated = truncated
+	if cacheKey != "" {
+		d.cache.put(cacheKey, result)
+	}
+	return result, nil
+}
+
+// readFileHead 读取文件开头最多 limit 字节的内容，同时返回文件的完整大小
+func readFileHead(path string, limit int64) ([]byte, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	return content, info.Size(), nil
+}
+
+// trimToLastLine 在内容被截断时去掉最后一个不完整的行
+func trimToLastLine(content []byte, cut bool) []byte {
+	if !cut {
+		return content
+	}
+	if i := bytes.LastIndexByte(content, '\n'); i >= 0 {
+		return content[:i+1]
+	}
+	return content
+}
+
+// AnnotateBlame 为新增的行附加 git blame 提交信息
+func AnnotateBlame(diff *models.TextDiff, blame map[int]models.LineBlame) {
+	for i := range diff.Lines {
+		line := &diff.Lines[i]
+		if line.Type != "insert" || line.NewLine == 0 {
+			continue
+		}
+		if info, ok := blame[line.NewLine]; ok {
+			line.Blame = &info
+		}
+	}
+}
+
+// CountLineChanges 按行比较两段文本，返回新增和删除的行数
+func (d *TextDiffer) CountLineChanges(oldText, newText string) (added, removed int) {
+	oldRunes, newRunes, _ := d.dmp.DiffLinesToRunes(oldText, newText)
+	diffs := d.dmp.DiffMainRunes(oldRunes, newRunes, false)
+
+	for _, diff := range diffs {
+		// 行模式下每个字符代表一行
+		lines := utf8.RuneCountInString(diff.Text)
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			added += lines
+		case diffmatchpatch.DiffDelete:
+			removed += lines
+		}
+	}
+	return added, removed
 }
 
 // GetPrettyDiff 获取格式化的差异文本（用于终端显示）
@@ -103,6 +238,7 @@ func IsTextFile(filename string) bool {
 		".cpp":   true,
 		".h":     true,
 		".hpp":   true,
+		".rc":    true,
 		".cs":    true,
 		".vb":    true,
 		".sql":   true,
@@ -0,0 +1,108 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// DefaultDiffCacheSize 差异预览缓存默认占用的内存上限（按两侧内容大小估算）
+const DefaultDiffCacheSize = 64 << 20
+
+// DiffCache 缓存本次运行中计算过的文本差异，按两侧内容的哈希和比较选项查找
+// 再次预览同一文件时不必重新读取基准和重新比较
+type DiffCache struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	order    *list.List // 最近使用的在前
+	entries  map[string]*list.Element
+}
+
+// diffCacheEntry 缓存的一个差异结果
+type diffCacheEntry struct {
+	key  string
+	diff *models.TextDiff
+	size int64
+}
+
+// NewDiffCache 创建差异缓存，capacity 为内存上限（字节），不大于 0 时使用默认值
+func NewDiffCache(capacity int64) *DiffCache {
+	if capacity <= 0 {
+		capacity = DefaultDiffCacheSize
+	}
+	return &DiffCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// diffCacheKey 由路径（规范化规则按路径匹配）、两侧内容的哈希和比较选项组成缓存键
+func diffCacheKey(relPath, oldHash, newHash, options string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s", relPath, oldHash, newHash, options)))
+	return hex.EncodeToString(sum[:])
+}
+
+// get 返回缓存的差异的副本，调用方可修改（如标注 blame）而不影响缓存
+func (c *DiffCache) get(key string) (*models.TextDiff, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return copyTextDiff(elem.Value.(*diffCacheEntry).diff), true
+}
+
+// put 缓存差异结果的副本，超过内存上限时淘汰最久未使用的结果；单个结果超过上限时不缓存
+func (c *DiffCache) put(key string, diff *models.TextDiff) {
+	if c == nil {
+		return
+	}
+	size := int64(len(diff.OldContent)+len(diff.NewContent)) * 2
+	if size > c.capacity {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&diffCacheEntry{key: key, diff: copyTextDiff(diff), size: size})
+	c.size += size
+	for c.size > c.capacity {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*diffCacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= entry.size
+	}
+}
+
+// Clear 清空缓存
+func (c *DiffCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.size = 0
+}
+
+// copyTextDiff 复制差异结果，行切片单独复制
+func copyTextDiff(diff *models.TextDiff) *models.TextDiff {
+	copied := *diff
+	copied.Lines = make([]models.DiffLine, len(diff.Lines))
+	copy(copied.Lines, diff.Lines)
+	return &copied
+}
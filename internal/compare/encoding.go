@@ -0,0 +1,142 @@
+package compare
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// ZIP 文件名编码
+const (
+	FilenameEncodingAuto     = "auto"
+	FilenameEncodingUTF8     = "utf-8"
+	FilenameEncodingShiftJIS = "shift-jis"
+	FilenameEncodingGBK      = "gbk"
+	FilenameEncodingCP437    = "cp437"
+)
+
+// utf8Flag 通用标志位第 11 位，表示文件名使用 UTF-8 编码
+const utf8Flag = 0x800
+
+// nameEncodings 非 UTF-8 文件名编码对应的解码器
+var nameEncodings = map[string]encoding.Encoding{
+	FilenameEncodingShiftJIS: japanese.ShiftJIS,
+	FilenameEncodingGBK:      simplifiedchinese.GBK,
+	FilenameEncodingCP437:    charmap.CodePage437,
+}
+
+// FilenameEncodings 返回可选的 ZIP 文件名编码
+func FilenameEncodings() []string {
+	return []string{
+		FilenameEncodingAuto,
+		FilenameEncodingUTF8,
+		FilenameEncodingShiftJIS,
+		FilenameEncodingGBK,
+		FilenameEncodingCP437,
+	}
+}
+
+// decodeZipNames 将未设置 UTF-8 标志的条目名称解码为 UTF-8，返回实际使用的编码
+// enc 为 auto 时根据所有此类条目的名称推测编码
+func decodeZipNames(files []*zip.File, enc string) (string, error) {
+	enc = strings.ToLower(strings.TrimSpace(enc))
+	if enc == "" {
+		enc = FilenameEncodingAuto
+	}
+
+	legacy := make([]*zip.File, 0)
+	for _, f := range files {
+		if f.Flags&utf8Flag == 0 {
+			legacy = append(legacy, f)
+		}
+	}
+	if len(legacy) == 0 || enc == FilenameEncodingUTF8 {
+		return FilenameEncodingUTF8, nil
+	}
+
+	if enc == FilenameEncodingAuto {
+		names := make([]string, len(legacy))
+		for i, f := range legacy {
+			names[i] = f.Name
+		}
+		enc = detectNameEncoding(names)
+		if enc == FilenameEncodingUTF8 {
+			return enc, nil
+		}
+	}
+
+	codec, ok := nameEncodings[enc]
+	if !ok {
+		return "", fmt.Errorf("unsupported filename encoding: %s", enc)
+	}
+
+	decoder := codec.NewDecoder()
+	for _, f := range legacy {
+		if name, err := decoder.String(f.Name); err == nil {
+			f.Name = name
+		}
+	}
+	return enc, nil
+}
+
+// detectNameEncoding 推测一组原始文件名的编码
+// 全部是有效 UTF-8 时认为是 UTF-8（很多工具写入 UTF-8 但不设置标志）；
+// 否则比较 Shift-JIS 与 GBK 的解码结果，都不合适时回退到 ZIP 规范默认的 CP437
+func detectNameEncoding(names []string) string {
+	allUTF8 := true
+	for _, name := range names {
+		if !utf8.ValidString(name) {
+			allUTF8 = false
+			break
+		}
+	}
+	if allUTF8 {
+		return FilenameEncodingUTF8
+	}
+
+	best, bestScore := FilenameEncodingCP437, 0
+	for _, enc := range []string{FilenameEncodingShiftJIS, FilenameEncodingGBK} {
+		if score, ok := scoreDecoding(nameEncodings[enc], names); ok && score > bestScore {
+			best, bestScore = enc, score
+		}
+	}
+	return best
+}
+
+// scoreDecoding 用指定编码解码所有名称并打分，出现无法解码的字节时返回 false
+// 平假名、片假名对 Shift-JIS 是强信号，常用汉字对两种编码都加分，罕见符号扣分
+func scoreDecoding(codec encoding.Encoding, names []string) (int, bool) {
+	decoder := codec.NewDecoder()
+	score := 0
+
+	for _, name := range names {
+		decoded, err := decoder.String(name)
+		if err != nil || strings.ContainsRune(decoded, utf8.RuneError) {
+			return 0, false
+		}
+		for _, r := range decoded {
+			switch {
+			case r < utf8.RuneSelf:
+				// ASCII 对两种编码相同
+			case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+				score += 3
+			case r >= 0x4E00 && r <= 0x9FFF:
+				score += 2
+			case r >= 0xFF01 && r <= 0xFF9F:
+				// 全角 ASCII 与半角片假名
+				score++
+			default:
+				score--
+			}
+		}
+	}
+
+	return score + 1, true
+}
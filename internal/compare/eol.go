@@ -0,0 +1,49 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrInvalidEOL 导出时转换的换行符不是 lf 或 crlf
+var ErrInvalidEOL = errors.New("eol must be lf or crlf")
+
+// ValidateEOL 检查导出时转换的换行符，空字符串表示不转换
+func ValidateEOL(eol string) error {
+	switch eol {
+	case "", models.EOLLF, models.EOLCRLF:
+		return nil
+	}
+	return ErrInvalidEOL
+}
+
+// openExport 打开导出的源文件；eol 不为空且是文本文件时返回换行符转换后的内容
+func openExport(src, relPath, eol string) (io.ReadCloser, error) {
+	file, err := os.Open(src)
+	if err != nil || eol == "" || !IsTextFile(relPath) {
+		return file, err
+	}
+	content, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(convertEOL(content, eol))), nil
+}
+
+// convertEOL 将 CRLF、CR、LF 换行统一为 eol；UTF-16 和无法识别编码的内容原样返回
+// Shift-JIS、GBK 的多字节字符不包含 CR、LF 字节，可直接按字节转换
+func convertEOL(content []byte, eol string) []byte {
+	switch DetectEncoding(content) {
+	case models.EncodingUTF16LE, models.EncodingUTF16BE, models.EncodingUnknown:
+		return content
+	}
+	content = normalizeEOL(content)
+	if eol == models.EOLCRLF {
+		content = bytes.ReplaceAll(content, []byte("\n"), []byte("\r\n"))
+	}
+	return content
+}
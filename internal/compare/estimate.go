@@ -0,0 +1,89 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Estimate 只列出两侧的文件、不读取内容，按排除规则和比较范围统计参与比较的文件数和总大小，用于比较前预估耗时
+func (c *Comparer) Estimate() (models.CompareEstimate, error) {
+	var estimate models.CompareEstimate
+
+	zipReader, err := NewZipReaderWithEncoding(c.zipPath, c.nameEncoding)
+	if err != nil {
+		return estimate, fmt.Errorf("failed to open zip file: %w", err)
+	}
+	defer zipReader.Close()
+	if c.maxDepth > 0 {
+		zipReader.SetMaxDepth(pathDepth(c.subPath) + c.maxDepth)
+	}
+	zipFiles, err := zipReader.ListFiles()
+	if err != nil {
+		return estimate, fmt.Errorf("failed to list zip files: %w", err)
+	}
+	zipFiles = c.scopeZipFiles(zipFiles)
+
+	workFiles, _, _, err := getAllFilesAndDirs(c.workDir, c.subPath, c.maxDepth, c.concurrency, c.gitignore, c.tracef, nil)
+	if err != nil {
+		return estimate, fmt.Errorf("failed to list work directory files: %w", err)
+	}
+	if c.ignoreCase {
+		workFiles = c.foldCase(zipFiles, workFiles)
+	}
+
+	for relPath, f := range zipFiles {
+		if c.shouldExclude(relPath, false) {
+			estimate.Excluded++
+			continue
+		}
+		estimate.BaselineFiles++
+		estimate.BaselineBytes += int64(f.UncompressedSize64)
+	}
+	for relPath, workFilePath := range workFiles {
+		f, inZip := zipFiles[relPath]
+		if c.shouldExclude(relPath, false) {
+			if !inZip {
+				estimate.Excluded++
+			}
+			continue
+		}
+		info, err := os.Stat(workFilePath)
+		if err != nil {
+			continue
+		}
+		estimate.WorkFiles++
+		estimate.WorkBytes += info.Size()
+		if inZip && info.Size() == int64(f.UncompressedSize64) && !(c.fastMode && sameModTime(f, info.ModTime())) {
+			// 大小相同的文件需要读取内容比较校验和，crc32 以外的算法还需要读取基准
+			estimate.HashBytes += info.Size()
+			if c.hashName() != models.HashCRC32 {
+				estimate.HashBytes += info.Size()
+			}
+		}
+	}
+	return estimate, nil
+}
+
+// PredictDuration 按历史比较的平均吞吐量预计比较耗时，取按文件数和按读取字节数估算的较大值；没有历史记录时返回 0
+func PredictDuration(estimate models.CompareEstimate, samples []models.ThroughputSample) time.Duration {
+	var files, bytes, ms int64
+	for _, s := range samples {
+		files += int64(s.Files)
+		bytes += s.Bytes
+		ms += s.DurationMs
+	}
+	if ms <= 0 || files <= 0 {
+		return 0
+	}
+
+	byFiles := float64(estimate.BaselineFiles+estimate.WorkFiles) * float64(ms) / float64(files)
+	predicted := byFiles
+	if bytes > 0 {
+		if byBytes := float64(estimate.HashBytes) * float64(ms) / float64(bytes); byBytes > predicted {
+			predicted = byBytes
+		}
+	}
+	return time.Duration(predicted * float64(time.Millisecond))
+}
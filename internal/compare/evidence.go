@@ -0,0 +1,110 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResultName 导出时一并保存的比较结果文件名
+const ResultName = "compare-result.json"
+
+// ErrEvidenceConflict 导出的文件中已有与基准 ZIP 或比较结果同名的文件，保存时会覆盖它
+var ErrEvidenceConflict = errors.New("exported files conflict with archived baseline or compare result")
+
+// evidenceNames 返回按 opts 一并保存的基准 ZIP 和比较结果的文件名
+func evidenceNames(opts ExportOptions) []string {
+	names := make([]string, 0, 2)
+	if opts.Baseline != "" {
+		names = append(names, filepath.Base(opts.Baseline))
+	}
+	if len(opts.Result) > 0 {
+		names = append(names, ResultName)
+	}
+	return names
+}
+
+// checkEvidence 保存基准 ZIP 和比较结果时，确认导出的文件（或 ZIP 本身）中没有同名文件
+func checkEvidence(relPaths []string, opts ExportOptions) error {
+	for _, name := range evidenceNames(opts) {
+		for _, relPath := range relPaths {
+			if strings.EqualFold(filepath.ToSlash(relPath), name) {
+				return fmt.Errorf("%w: %s", ErrEvidenceConflict, name)
+			}
+		}
+	}
+	return nil
+}
+
+// itemPaths 返回差异项的相对路径
+func itemPaths(items []models.DiffItem) []string {
+	paths := make([]string, 0, len(items))
+	for _, item := range items {
+		paths = append(paths, item.RelPath)
+	}
+	return paths
+}
+
+// archiveEvidence 将基准 ZIP 和比较结果保存到 dir，内容同时计入校验和
+// 设置 opts.HardLink 时基准 ZIP 优先创建硬链接，不在同一卷等无法链接时复制
+func archiveEvidence(dir string, opts ExportOptions, sums *checksumSet, onProgress func(current, total int, message string)) error {
+	if opts.Baseline != "" {
+		name := filepath.Base(opts.Baseline)
+		if onProgress != nil {
+			onProgress(1, 1, fmt.Sprintf("保存基准: %s", name))
+		}
+		dest := filepath.Join(dir, name)
+		if err := archiveBaseline(opts.Baseline, dest, opts.HardLink); err != nil {
+			return fmt.Errorf("failed to archive baseline: %w", err)
+		}
+		if err := hashInto(dest, sums.add(name)); err != nil {
+			return fmt.Errorf("failed to archive baseline: %w", err)
+		}
+	}
+	if len(opts.Result) > 0 {
+		if err := writeFile(bytes.NewReader(opts.Result), filepath.Join(dir, ResultName), sums.add(ResultName)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", ResultName, err)
+		}
+	}
+	return nil
+}
+
+// archiveBaseline 复制或硬链接基准 ZIP；目标已是同一个文件（如基准就在输出目录中）时不做处理
+// 写入前先删除已有的目标，避免通过旧的硬链接改写其他文件
+func archiveBaseline(src, dest string, link bool) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if destInfo, err := os.Stat(dest); err == nil && os.SameFile(srcInfo, destInfo) {
+		return nil
+	}
+	if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if link {
+		if err := os.Link(src, dest); err == nil {
+			return nil
+		}
+	}
+	return copyFile(src, dest)
+}
+
+// hashInto 将文件内容写入 w（用于计算校验和），w 为 io.Discard 时不读取
+func hashInto(path string, w io.Writer) error {
+	if w == io.Discard {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,93 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// sniffSize 内容嗅探读取的字节数
+const sniffSize = 8 * 1024
+
+// 文件类别
+const (
+	FileCategoryText   = "text"
+	FileCategoryBinary = "binary"
+	FileCategoryImage  = "image"
+)
+
+// DetectFile 检测文件类型，优先读取工作目录中的文件，不存在时读取 ZIP 中的文件
+func DetectFile(zipReader *ZipReader, relPath, workFilePath string) (models.FileType, error) {
+	head, _, err := readFileHead(workFilePath, sniffSize)
+	if err != nil {
+		if !os.IsNotExist(err) || zipReader == nil {
+			return models.FileType{}, err
+		}
+		head, _, err = zipReader.ReadFileHead(relPath, sniffSize)
+		if err != nil {
+			return models.FileType{}, err
+		}
+	}
+	return DetectFileType(relPath, head), nil
+}
+
+// DetectFileType 根据文件名和开头的内容检测 MIME 类型与类别
+func DetectFileType(name string, head []byte) models.FileType {
+	ext := strings.ToLower(getFileExt(name))
+
+	mimeType := ""
+	if ext != "" {
+		mimeType = mime.TypeByExtension(ext)
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+
+	category := FileCategoryBinary
+	switch {
+	case strings.HasPrefix(mimeType, "image/") && ext != ".svg":
+		category = FileCategoryImage
+	case IsTextFile(name) || looksLikeText(head):
+		category = FileCategoryText
+		if mimeType == "application/octet-stream" {
+			mimeType = "text/plain; charset=utf-8"
+		}
+	}
+
+	return models.FileType{MimeType: mimeType, Category: category}
+}
+
+// looksLikeText 根据内容判断是否是文本
+// 有效的 UTF-8（含 UTF-16 BOM）视为文本；其他编码（如 Shift-JIS、GBK）要求不含 NUL 且控制字符极少
+func looksLikeText(head []byte) bool {
+	if len(head) == 0 {
+		return true
+	}
+	if bytes.HasPrefix(head, []byte{0xFF, 0xFE}) || bytes.HasPrefix(head, []byte{0xFE, 0xFF}) {
+		return true
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+
+	// 截断可能发生在多字节字符中间，去掉末尾不完整的字符后再校验
+	sample := head
+	for i := 0; i < utf8.UTFMax-1 && len(sample) > 0 && !utf8.Valid(sample); i++ {
+		sample = sample[:len(sample)-1]
+	}
+	if utf8.Valid(sample) {
+		return true
+	}
+
+	control := 0
+	for _, b := range head {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != 0x1b {
+			control++
+		}
+	}
+	return control*100 < len(head)
+}
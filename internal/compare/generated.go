@@ -0,0 +1,101 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RuleTypeGenerated 生成文件规则的类型：Pattern 为生成文件的文件名通配符（如 *.Designer.vb），Source 为同一目录中的源文件
+// 规则不排除文件；生成文件和它的源文件都有差异时，生成文件的修改由源文件的修改引起，标记为生成文件以便在审阅时折叠
+const RuleTypeGenerated = "generated"
+
+// generatedRule 编译后的生成文件规则
+type generatedRule struct {
+	name    *regexp.Regexp // 匹配生成文件的文件名，第一个 * 为捕获组
+	sources []string       // 源文件名模板，* 替换为捕获的部分
+}
+
+// compileGenerated 编译生成文件规则，模式无效或没有 * 的规则忽略
+func compileGenerated(rule models.ExcludeRule) (generatedRule, bool) {
+	if !strings.Contains(rule.Pattern, "*") || strings.Contains(rule.Pattern, "/") {
+		return generatedRule{}, false
+	}
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	captured := false
+	for _, c := range rule.Pattern {
+		switch {
+		case c == '*' && !captured:
+			sb.WriteString("(.+?)")
+			captured = true
+		case c == '*':
+			sb.WriteString(".*")
+		case c == '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return generatedRule{}, false
+	}
+
+	gr := generatedRule{name: re}
+	for _, source := range strings.Split(rule.Source, ",") {
+		if source = strings.TrimSpace(source); source != "" && !strings.Contains(source, "/") {
+			gr.sources = append(gr.sources, source)
+		}
+	}
+	return gr, len(gr.sources) > 0
+}
+
+// markGenerated 为源文件同样有差异的生成文件设置 GeneratedFrom，并统计数量；路径不区分大小写
+func (c *Comparer) markGenerated(result *models.CompareResult) {
+	result.Generated = 0
+	if c.excludeMatcher == nil || len(c.excludeMatcher.generated) == 0 {
+		return
+	}
+
+	changed := make(map[string]string, len(result.Items))
+	for _, item := range result.Items {
+		if item.Type != TypeUnchanged && item.Stream == "" {
+			changed[strings.ToLower(item.RelPath)] = item.RelPath
+		}
+	}
+	for i := range result.Items {
+		item := &result.Items[i]
+		if item.Type == TypeUnchanged {
+			continue
+		}
+		if source, ok := c.excludeMatcher.generatedSource(item.RelPath, changed); ok {
+			item.GeneratedFrom = source
+			result.Generated++
+			c.tracef("generated %s: source %s also changed", item.RelPath, source)
+		}
+	}
+}
+
+// generatedSource 按生成文件规则查找 relPath 在 changed（小写路径到原路径）中的源文件
+func (m *ExcludeMatcher) generatedSource(relPath string, changed map[string]string) (string, bool) {
+	dir, name := path.Split(relPath)
+	for _, rule := range m.generated {
+		match := rule.name.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		for _, source := range rule.sources {
+			sourcePath := dir + strings.ReplaceAll(source, "*", match[1])
+			if strings.EqualFold(sourcePath, relPath) {
+				continue
+			}
+			if original, ok := changed[strings.ToLower(sourcePath)]; ok {
+				return original, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,187 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/vcs"
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// CompareGit 以 git 引用（通常是发布标签）作为基准比较工作目录，由 git diff 生成差异列表
+// 适用于工作目录是 git 仓库的情况，速度快且能识别重命名
+// 与 ZIP 比较一样遵循子目录、最大层数、忽略 BOM 和编码、规范化规则和列出相同文件的设置
+func (c *Comparer) CompareGit(ref string) (*models.CompareResult, error) {
+	if !vcs.IsGitRepo(c.workDir) {
+		return nil, fmt.Errorf("work directory is not a git repository: %s", c.workDir)
+	}
+	if _, err := vcs.ResolveRef(c.workDir, ref); err != nil {
+		return nil, err
+	}
+
+	stop := c.startHeartbeat(PhaseScan, func() (int, string) {
+		return 0, fmt.Sprintf("正在读取与 %s 的差异…", ref)
+	})
+	changes, err := vcs.DiffWorkTree(c.workDir, ref)
+	stop()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", ref, err)
+	}
+
+	result := &models.CompareResult{
+		Items: make([]models.DiffItem, 0),
+	}
+	c.stats = models.CompareStats{}
+
+	// 基准中的文件大小，失败时只缺少大小信息
+	oldSizes, err := vcs.TreeSizes(c.workDir, ref)
+	if err != nil {
+		c.tracef("read tree sizes of %s failed: %v", ref, err)
+	}
+
+	// 基准中未出现在差异列表中的文件即内容相同的文件
+	unchanged := make(map[string]bool, len(oldSizes))
+	for relPath := range oldSizes {
+		unchanged[relPath] = true
+	}
+
+	for i, change := range changes {
+		delete(unchanged, change.Path)
+		delete(unchanged, change.OldPath)
+		if !c.gitInScope(change.Path) && (change.OldPath == "" || !c.gitInScope(change.OldPath)) {
+			continue
+		}
+		c.stats.FilesScanned++
+		c.emitProgress(i+1, len(changes), fmt.Sprintf("检查: %s", change.Path))
+
+		if c.shouldExclude(change.Path, false) {
+			continue
+		}
+
+		item := models.DiffItem{
+			RelPath:      change.Path,
+			Type:         change.Status,
+			Selected:     true,
+			OldPath:      change.OldPath,
+			LinesAdded:   change.LinesAdded,
+			LinesRemoved: change.LinesRemoved,
+		}
+		if change.Status != "deleted" {
+			item.SourcePath = filepath.Join(c.workDir, filepath.FromSlash(change.Path))
+			if info, err := os.Stat(item.SourcePath); err == nil {
+				item.NewSize = info.Size()
+			}
+		}
+		if change.Status != "added" {
+			item.OldSize = oldSizes[cmp.Or(change.OldPath, change.Path)]
+		}
+		// 基准中的内容只读取一次，判断是否相同和扫描关键字共用
+		var oldContent []byte
+		loadOld := func() ([]byte, error) {
+			if oldContent != nil {
+				return oldContent, nil
+			}
+			content, err := vcs.ShowFile(c.workDir, ref, cmp.Or(change.OldPath, change.Path))
+			oldContent = content
+			return content, err
+		}
+		if change.Status == "modified" && c.gitEquivalent(&item, loadOld) {
+			c.addGitUnchanged(result, change.Path, item.OldSize)
+			continue
+		}
+
+		switch change.Status {
+		case "added":
+			c.scanKeywords(&item, nil)
+		case "modified", "renamed":
+			c.scanKeywords(&item, loadOld)
+		}
+
+		switch change.Status {
+		case "added":
+			result.Added++
+		case "modified":
+			result.Modified++
+		case "deleted":
+			result.Deleted++
+		case "renamed":
+			result.Renamed++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	same := make([]string, 0, len(unchanged))
+	for relPath := range unchanged {
+		if c.gitInScope(relPath) && !c.shouldExclude(relPath, false) {
+			same = append(same, relPath)
+		}
+	}
+	sort.Strings(same)
+	for _, relPath := range same {
+		c.addGitUnchanged(result, relPath, oldSizes[relPath])
+	}
+
+	c.markGenerated(result)
+	result.TotalFiles = countDiffs(result)
+	result.Flagged = countFlagged(result.Items)
+	result.Largest = c.largest(result.Items)
+	sumSizes(result)
+	return result, nil
+}
+
+// gitInScope 判断差异列表中的路径是否在限定的子目录和最大层数内
+func (c *Comparer) gitInScope(relPath string) bool {
+	return c.inScope(relPath) && c.withinMaxDepth(relPath)
+}
+
+// gitEquivalent 按忽略 BOM、忽略编码和规范化规则判断 git 列为修改的文件是否视为相同，并标记只差 BOM 或编码的文件
+// 未启用这些设置或文件过大时不读取基准中的内容
+func (c *Comparer) gitEquivalent(item *models.DiffItem, loadOld func() ([]byte, error)) bool {
+	if !c.ignoreBOM && !c.ignoreEncoding && !c.normalizer.Applies(item.RelPath) || max(item.OldSize, item.NewSize) > maxNormalizeSize {
+		return false
+	}
+	oldContent, err := loadOld()
+	if err != nil {
+		return false
+	}
+	newContent, size, err := readFileHead(item.SourcePath, maxNormalizeSize+1)
+	if err != nil || size > maxNormalizeSize {
+		return false
+	}
+
+	item.BOMOnly = bomOnlyContent(oldContent, newContent)
+	item.EncodingOnly = !item.BOMOnly && encodingOnlyContent(oldContent, newContent)
+	switch {
+	case item.BOMOnly && c.ignoreBOM:
+		c.tracef("equal %s: differs only by BOM", item.RelPath)
+		c.stats.BOMOnly++
+	case item.EncodingOnly && c.ignoreEncoding:
+		c.tracef("equal %s: differs only by encoding", item.RelPath)
+		c.stats.EncodingOnly++
+	case c.normalizer.Applies(item.RelPath) &&
+		bytes.Equal(c.normalizer.Normalize(item.RelPath, oldContent), c.normalizer.Normalize(item.RelPath, newContent)):
+		c.tracef("equal %s: identical after normalization", item.RelPath)
+		c.stats.NormalizedEqual++
+	default:
+		return false
+	}
+	return true
+}
+
+// addGitUnchanged 记录一个与基准相同的文件，基准中的大小未知时为 0
+func (c *Comparer) addGitUnchanged(result *models.CompareResult, relPath string, size int64) {
+	result.Unchanged++
+	result.UnchangedBytes += size
+	if !c.listUnchanged {
+		return
+	}
+	workFilePath := filepath.Join(c.workDir, filepath.FromSlash(relPath))
+	item := models.DiffItem{RelPath: relPath, Type: TypeUnchanged, SourcePath: workFilePath, OldSize: size}
+	if info, err := os.Stat(workFilePath); err == nil {
+		item.NewSize = info.Size()
+	}
+	result.Items = append(result.Items, item)
+}
@@ -0,0 +1,198 @@
+package compare
+
+import (
+	"regexp"
+	"strings"
+)
+
+// globToRegex 将 glob 模式转换为正则表达式
+// 支持 *、?、[abc]/[0-9]/[!abc] 字符类以及可嵌套的 {a,b} 花括号展开；
+// 独立成段的 ** 与 gitignore 语义一致，例如 **/bin、src/**、a/**/b
+func globToRegex(pattern string) string {
+	return "^" + globBodyToRegex(pattern) + "$"
+}
+
+// globBodyToRegex 转换 glob 主体（不含首尾锚点），供花括号分支递归使用
+func globBodyToRegex(pattern string) string {
+	var sb strings.Builder
+	runes := []rune(pattern)
+	n := len(runes)
+
+	for i := 0; i < n; i++ {
+		c := runes[i]
+		switch c {
+		case '*':
+			if i+1 < n && runes[i+1] == '*' {
+				// ** 仅在独立成段时具有跨目录语义
+				segStart := i == 0 || runes[i-1] == '/'
+				j := i + 2
+				for j < n && runes[j] == '*' {
+					j++
+				}
+				segEnd := j == n || runes[j] == '/'
+				if segStart && segEnd {
+					switch {
+					case j == n && i == 0:
+						// 整个模式就是 **
+						sb.WriteString(`.*`)
+					case j == n:
+						// 末尾的 /** 匹配目录下的所有内容
+						sb.WriteString(`.*`)
+					default:
+						// **/ 匹配零个或多个目录
+						sb.WriteString(`(?:.*/)?`)
+						j++ // 跳过 /
+					}
+					i = j - 1
+					continue
+				}
+				// 非独立成段的 ** 退化为 *
+				sb.WriteString(`[^/]*`)
+				i = j - 1
+				continue
+			}
+			sb.WriteString(`[^/]*`)
+		case '?':
+			sb.WriteString(`[^/]`)
+		case '[':
+			if class, next, ok := parseCharClass(runes, i); ok {
+				sb.WriteString(class)
+				i = next - 1
+			} else {
+				sb.WriteString(`\[`)
+			}
+		case '{':
+			if alts, next, ok := splitBraces(runes, i); ok {
+				sb.WriteString("(?:")
+				for k, alt := range alts {
+					if k > 0 {
+						sb.WriteString("|")
+					}
+					sb.WriteString(globBodyToRegex(alt))
+				}
+				sb.WriteString(")")
+				i = next - 1
+			} else {
+				sb.WriteString(`\{`)
+			}
+		case '\\':
+			if i+1 < n && isGlobMeta(runes[i+1]) {
+				sb.WriteString(regexp.QuoteMeta(string(runes[i+1])))
+				i++
+			} else {
+				// Windows 风格的路径分隔符
+				sb.WriteString("/")
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
+
+// isGlobMeta 判断字符是否是 glob 元字符（可被 \ 转义）
+func isGlobMeta(c rune) bool {
+	return strings.ContainsRune(`*?[]{},!\`, c)
+}
+
+// escapeGlob 转义字符串中的 glob 元字符，使其按字面匹配
+func escapeGlob(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if isGlobMeta(c) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// parseCharClass 解析从 start 开始的 [...] 字符类
+// 返回对应的正则片段、结束位置（] 之后）以及是否解析成功
+func parseCharClass(runes []rune, start int) (string, int, bool) {
+	n := len(runes)
+	i := start + 1
+	var sb strings.Builder
+	sb.WriteString("[")
+
+	if i < n && (runes[i] == '!' || runes[i] == '^') {
+		sb.WriteString("^/")
+		i++
+	}
+
+	first := true
+	for i < n {
+		c := runes[i]
+		if c == ']' && !first {
+			sb.WriteString("]")
+			return sb.String(), i + 1, true
+		}
+		first = false
+
+		switch c {
+		case '\\':
+			if i+1 < n {
+				sb.WriteString(regexp.QuoteMeta(string(runes[i+1])))
+				i += 2
+				continue
+			}
+			sb.WriteString(`\\`)
+		case '-':
+			sb.WriteString("-")
+		case '[', ']', '^':
+			sb.WriteString(`\` + string(c))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+		i++
+	}
+
+	// 没有找到闭合的 ]
+	return "", start, false
+}
+
+// splitBraces 解析从 start 开始的 {a,b,...}，支持嵌套
+// 返回各分支、结束位置（} 之后）以及是否解析成功
+func splitBraces(runes []rune, start int) ([]string, int, bool) {
+	n := len(runes)
+	depth := 0
+	alts := make([]string, 0)
+	var cur strings.Builder
+
+	for i := start; i < n; i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < n:
+			if depth > 0 {
+				cur.WriteRune(c)
+				cur.WriteRune(runes[i+1])
+			}
+			i++
+			continue
+		case c == '{':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case c == '}':
+			depth--
+			if depth == 0 {
+				alts = append(alts, cur.String())
+				if len(alts) < 2 {
+					// {abc} 不是有效的展开，按字面处理
+					return nil, start, false
+				}
+				return alts, i + 1, true
+			}
+		case c == ',' && depth == 1:
+			alts = append(alts, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(c)
+	}
+
+	// 没有找到闭合的 }
+	return nil, start, false
+}
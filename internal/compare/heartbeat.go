@@ -0,0 +1,46 @@
+package compare
+
+import "time"
+
+// 比较的阶段
+const (
+	PhaseOpen    = "open"    // 读取 ZIP 文件
+	PhaseScan    = "scan"    // 扫描工作目录
+	PhaseCompare = "compare" // 逐文件比较
+)
+
+// heartbeatInterval 心跳事件的间隔
+const heartbeatInterval = 500 * time.Millisecond
+
+// startHeartbeat 在没有逐文件进度的阶段立即并定期触发 OnHeartbeat，返回停止函数
+// status 返回当前的计数和消息，会在后台 goroutine 中调用
+func (c *Comparer) startHeartbeat(phase string, status func() (int, string)) func() {
+	if c.OnHeartbeat == nil {
+		return func() {}
+	}
+
+	count, message := status()
+	c.OnHeartbeat(phase, count, message)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				count, message := status()
+				c.OnHeartbeat(phase, count, message)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
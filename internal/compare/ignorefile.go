@@ -0,0 +1,190 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ParseIgnoreFile 解析 .gitignore / .dockerignore 等 gitignore 语法的文件，转换为排除规则
+func ParseIgnoreFile(path string) ([]models.ExcludeRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer file.Close()
+
+	name := filepath.Base(path)
+	// .dockerignore 中的模式总是相对于构建上下文根目录
+	anchorAll := strings.EqualFold(name, ".dockerignore")
+
+	rules, err := ParseIgnorePatterns(file, anchorAll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	for i := range rules {
+		rules[i].Comment = fmt.Sprintf("导入自 %s", name)
+	}
+	return rules, nil
+}
+
+// ParseIgnorePatterns 逐行解析 gitignore 语法的模式
+// anchorAll 为 true 时所有模式都相对于根目录（.dockerignore 语义）
+func ParseIgnorePatterns(r io.Reader, anchorAll bool) ([]models.ExcludeRule, error) {
+	rules := make([]models.ExcludeRule, 0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(scanner.Text(), anchorAll); ok {
+			rules = append(rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// parseIgnoreLine 将单行 gitignore 模式转换为排除规则
+func parseIgnoreLine(line string, anchorAll bool) (models.ExcludeRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = strings.TrimPrefix(line, "\ufeff")
+
+	// 空行和注释
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return models.ExcludeRule{}, false
+	}
+
+	// 去除未转义的行尾空格
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	line = strings.ReplaceAll(line, `\ `, " ")
+
+	rule := models.ExcludeRule{Type: "glob", Enabled: true, FromIgnore: true}
+
+	// ! 表示重新包含；\! 与 \# 表示字面字符
+	switch {
+	case strings.HasPrefix(line, "!"):
+		rule.Negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+
+	// 以 / 结尾表示仅匹配目录
+	if strings.HasSuffix(line, "/") {
+		rule.IsDir = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	// 开头或中间包含 / 的模式相对于根目录
+	line = strings.TrimPrefix(line, "./")
+	anchored := anchorAll || strings.Contains(line, "/")
+	line = strings.TrimLeft(line, "/")
+	if line == "" {
+		return models.ExcludeRule{}, false
+	}
+	if anchored {
+		line = "/" + line
+	}
+
+	rule.Pattern = line
+	return rule, true
+}
+
+// nestedIgnore 记录工作目录中各层 .gitignore 的规则，每个文件的规则仅作用于其所在目录
+// 并发遍历时各目录的规则由不同 goroutine 加载，读写需加锁
+type nestedIgnore struct {
+	mu       sync.RWMutex
+	rules    map[string][]models.ExcludeRule // 目录相对路径 → 生效的规则（含上级目录）
+	matchers map[string]*ExcludeMatcher
+}
+
+func newNestedIgnore() *nestedIgnore {
+	return &nestedIgnore{
+		rules:    make(map[string][]models.ExcludeRule),
+		matchers: make(map[string]*ExcludeMatcher),
+	}
+}
+
+// loadDir 读取目录下的 .gitignore，与上级目录的规则合并
+func (n *nestedIgnore) loadDir(absDir, relDir string) {
+	n.mu.RLock()
+	parentRules := n.rules[parentDir(relDir)]
+	n.mu.RUnlock()
+
+	rules := make([]models.ExcludeRule, 0, len(parentRules))
+	rules = append(rules, parentRules...)
+
+	if file, err := os.Open(filepath.Join(absDir, ".gitignore")); err == nil {
+		own, err := ParseIgnorePatterns(file, false)
+		file.Close()
+		if err == nil {
+			for _, rule := range own {
+				rule.Pattern = scopeIgnorePattern(rule.Pattern, relDir)
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	var matcher *ExcludeMatcher
+	if len(rules) > 0 {
+		matcher = NewExcludeMatcher(rules)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.rules[relDir] = rules
+	if matcher != nil {
+		n.matchers[relDir] = matcher
+	}
+}
+
+// ShouldExclude 使用离路径最近的已加载目录的规则判断是否排除
+func (n *nestedIgnore) ShouldExclude(relPath string, isDir bool) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	dir := parentDir(relPath)
+	for {
+		if _, loaded := n.rules[dir]; loaded {
+			if m := n.matchers[dir]; m != nil {
+				return m.ShouldExclude(relPath, isDir)
+			}
+			return false
+		}
+		if dir == "" {
+			return false
+		}
+		dir = parentDir(dir)
+	}
+}
+
+// scopeIgnorePattern 将子目录 .gitignore 中的模式限定到该目录下，目录名中的 glob 元字符按字面匹配
+func scopeIgnorePattern(pattern, relDir string) string {
+	if relDir == "" {
+		return pattern
+	}
+	relDir = escapeGlob(relDir)
+	if strings.HasPrefix(pattern, "/") {
+		return "/" + relDir + pattern
+	}
+	return "/" + relDir + "/**/" + pattern
+}
+
+// parentDir 返回相对路径的上级目录，根目录为空字符串
+func parentDir(relPath string) string {
+	dir := filepath.ToSlash(filepath.Dir(relPath))
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,154 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// journalSaveInterval 导出过程中保存进度记录的间隔，导出失败时也会保存
+const journalSaveInterval = 2 * time.Second
+
+// exportJournal 目录导出的进度记录
+// 导出失败或中断后再次导出到同一目录时，源文件和已导出的文件都没有变化的项直接跳过，不再重新复制
+// 只保留最近一次未完成的导出，导出成功后删除
+type exportJournal struct {
+	OutputDir string                  `json:"outputDir"`
+	EOL       string                  `json:"eol"`  // 换行符转换，与本次导出不同时不能复用
+	Done      map[string]journalEntry `json:"done"` // 已导出的文件，键为相对路径
+	SavedAt   time.Time               `json:"savedAt"`
+
+	path     string
+	saved    time.Time
+	finished bool
+}
+
+// journalEntry 一个已导出文件的记录
+type journalEntry struct {
+	SourcePath    string    `json:"sourcePath"`
+	SourceSize    int64     `json:"sourceSize"`
+	SourceModTime time.Time `json:"sourceModTime"`
+	Size          int64     `json:"size"`    // 导出的文件大小
+	Streams       []string  `json:"streams"` // 一并导出的备用数据流
+	SHA256        string    `json:"sha256"`  // 导出内容的哈希，跳过时写入校验和文件
+	MD5           string    `json:"md5"`
+}
+
+// openJournal 读取与本次导出（同一输出目录和换行符转换）对应的进度记录，没有时创建空记录；path 为空时返回 nil（不记录）
+func openJournal(path, outputDir string, opts ExportOptions) *exportJournal {
+	if path == "" {
+		return nil
+	}
+	journal := &exportJournal{OutputDir: outputDir, EOL: opts.EOL, Done: make(map[string]journalEntry), path: path, saved: time.Now()}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return journal
+	}
+	var saved exportJournal
+	if json.Unmarshal(data, &saved) != nil || saved.OutputDir != outputDir || saved.EOL != opts.EOL || saved.Done == nil {
+		return journal
+	}
+	journal.Done = saved.Done
+	return journal
+}
+
+// completed 判断差异项是否已在上次中断的导出中完成：源文件的大小和修改时间、导出的文件大小和备用数据流都与记录一致
+func (j *exportJournal) completed(item models.DiffItem, destPath string) (journalEntry, bool) {
+	if j == nil {
+		return journalEntry{}, false
+	}
+	entry, ok := j.Done[item.RelPath]
+	if !ok || entry.SourcePath != item.SourcePath || !slices.Equal(entry.Streams, selectedStreams(item)) {
+		return entry, false
+	}
+	src, err := os.Stat(item.SourcePath)
+	if err != nil || src.Size() != entry.SourceSize || !src.ModTime().Equal(entry.SourceModTime) {
+		return entry, false
+	}
+	dest, err := os.Stat(destPath)
+	if err != nil || dest.Size() != entry.Size {
+		return entry, false
+	}
+	return entry, true
+}
+
+// record 记录导出完成的文件，距上次保存超过 journalSaveInterval 时保存
+func (j *exportJournal) record(item models.DiffItem, destPath, sha256Hex, md5Hex string) {
+	if j == nil {
+		return
+	}
+	src, err := os.Stat(item.SourcePath)
+	if err != nil {
+		return
+	}
+	dest, err := os.Stat(destPath)
+	if err != nil {
+		return
+	}
+	j.Done[item.RelPath] = journalEntry{
+		SourcePath:    item.SourcePath,
+		SourceSize:    src.Size(),
+		SourceModTime: src.ModTime(),
+		Size:          dest.Size(),
+		Streams:       selectedStreams(item),
+		SHA256:        sha256Hex,
+		MD5:           md5Hex,
+	}
+	if time.Since(j.saved) >= journalSaveInterval {
+		j.save()
+	}
+}
+
+// forget 删除文件的记录，下次导出时重新复制
+func (j *exportJournal) forget(relPath string) {
+	if j != nil {
+		delete(j.Done, relPath)
+	}
+}
+
+// save 写入进度记录，先写临时文件再重命名
+func (j *exportJournal) save() error {
+	if j == nil || j.finished {
+		return nil
+	}
+	j.saved = time.Now()
+	j.SavedAt = j.saved
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write export journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write export journal: %w", err)
+	}
+	return nil
+}
+
+// finish 导出成功后删除进度记录
+func (j *exportJournal) finish() {
+	if j == nil {
+		return
+	}
+	j.finished = true
+	os.Remove(j.path)
+}
+
+// selectedStreams 返回差异项中选中导出的备用数据流名称
+func selectedStreams(item models.DiffItem) []string {
+	var names []string
+	for _, stream := range item.Streams {
+		if stream.Selected {
+			names = append(names, stream.Stream)
+		}
+	}
+	return names
+}
@@ -0,0 +1,231 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxKeywordScanSize 关键字扫描的文件大小上限，更大的文件不扫描
+const maxKeywordScanSize = maxLineCountSize
+
+// maxFindingsPerFile 每个文件最多记录的命中行数
+const maxFindingsPerFile = 20
+
+// maxFindingText 命中行内容的最大长度（字符），超过时截断
+const maxFindingText = 200
+
+// KeywordScanner 在新增和修改的文件中查找关键字（TODO、密码、连接字符串、内部主机名等）
+type KeywordScanner struct {
+	rules   []compiledKeyword
+	enabled []models.KeywordRule // 启用的规则，用于检查点的设置摘要
+}
+
+// compiledKeyword 编译后的关键字规则
+type compiledKeyword struct {
+	name string
+	re   *regexp.Regexp
+	exts map[string]bool // 适用的扩展名，为 nil 时适用于所有文本文件
+}
+
+// RuleError 关键字、规范化等规则无法解析
+type RuleError struct {
+	Kind string // 规则类别，如「关键字规则」
+	Name string // 规则名称，没有名称时为模式
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("invalid rule %q: %v", e.Name, e.Err)
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateKeywordRules 检查启用的关键字规则能否解析，保存配置和读取项目配置时调用
+func ValidateKeywordRules(rules []models.KeywordRule) error {
+	for _, rule := range rules {
+		if !rule.Enabled || rule.Pattern == "" {
+			continue
+		}
+		if _, err := compileKeyword(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewKeywordScanner 由关键字规则创建扫描器，没有可用规则时返回 nil
+// 跳过未启用的规则；无法解析的规则由 ValidateKeywordRules 在保存时拒绝，手工编辑配置文件引入的会被跳过
+func NewKeywordScanner(rules []models.KeywordRule) *KeywordScanner {
+	s := &KeywordScanner{}
+	for _, rule := range rules {
+		if !rule.Enabled || rule.Pattern == "" {
+			continue
+		}
+		ck, err := compileKeyword(rule)
+		if err != nil {
+			continue
+		}
+		s.enabled = append(s.enabled, rule)
+		s.rules = append(s.rules, ck)
+	}
+	if len(s.rules) == 0 {
+		return nil
+	}
+	return s
+}
+
+// compileKeyword 编译一条关键字规则
+func compileKeyword(rule models.KeywordRule) (compiledKeyword, error) {
+	ck := compiledKeyword{name: rule.Name}
+	if ck.name == "" {
+		ck.name = rule.Pattern
+	}
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return ck, &RuleError{Kind: "关键字规则", Name: ck.name, Err: err}
+	}
+	ck.re = re
+	for _, ext := range rule.Extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if ck.exts == nil {
+			ck.exts = make(map[string]bool)
+		}
+		ck.exts[ext] = true
+	}
+	return ck, nil
+}
+
+// settings 返回创建扫描器的启用规则，未设置扫描器时为 nil
+func (s *KeywordScanner) settings() []models.KeywordRule {
+	if s == nil {
+		return nil
+	}
+	return s.enabled
+}
+
+// Scan 查找内容中命中规则的行；old 非 nil 时跳过基准中已有的相同行，只报告新引入的内容
+func (s *KeywordScanner) Scan(relPath string, content, old []byte) []models.KeywordFinding {
+	if s == nil {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(relPath))
+	rules := make([]compiledKeyword, 0, len(s.rules))
+	for _, rule := range s.rules {
+		if rule.exts == nil || rule.exts[ext] {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var existing map[string]bool
+	if old != nil {
+		existing = make(map[string]bool)
+		for _, line := range bytes.Split(old, []byte("\n")) {
+			existing[string(bytes.TrimRight(line, "\r"))] = true
+		}
+	}
+
+	var findings []models.KeywordFinding
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if existing[line] {
+			continue
+		}
+		for _, rule := range rules {
+			if !rule.re.MatchString(line) {
+				continue
+			}
+			findings = append(findings, models.KeywordFinding{Rule: rule.name, Line: lineNo, Text: findingText(line)})
+			if len(findings) >= maxFindingsPerFile {
+				return findings
+			}
+			break
+		}
+	}
+	return findings
+}
+
+// findingText 去掉行首尾空白并截断过长的行
+func findingText(line string) string {
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) <= maxFindingText {
+		return line
+	}
+	return string([]rune(line)[:maxFindingText]) + "…"
+}
+
+// SetKeywordScanner 设置关键字扫描器，比较时扫描新增和修改的文件并将命中记录到差异项
+func (c *Comparer) SetKeywordScanner(s *KeywordScanner) {
+	c.keywords = s
+}
+
+// scanKeywords 扫描差异项对应的工作目录文件，readOld 读取基准中的旧版本（新增文件为 nil），用于跳过已有的行
+func (c *Comparer) scanKeywords(item *models.DiffItem, readOld func() ([]byte, error)) {
+	if c.keywords == nil {
+		return
+	}
+	content, size, err := readFileHead(item.SourcePath, maxKeywordScanSize+1)
+	if err != nil || size > maxKeywordScanSize {
+		return
+	}
+	if !IsTextFile(item.RelPath) && !looksLikeText(content[:min(len(content), 512)]) {
+		return
+	}
+
+	var old []byte
+	if readOld != nil {
+		if old, err = readOld(); err != nil {
+			c.tracef("keywords %s: read baseline failed: %v", item.RelPath, err)
+		}
+	}
+	item.Findings = c.keywords.Scan(item.RelPath, content, old)
+	if len(item.Findings) > 0 {
+		c.tracef("keywords %s: %d findings", item.RelPath, len(item.Findings))
+	}
+}
+
+// zipContent 返回读取 ZIP 中文件内容的函数，过大的文件返回空内容
+func zipContent(f *zip.File) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if f.UncompressedSize64 > uint64(maxKeywordScanSize) {
+			return nil, nil
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+}
+
+// countFlagged 统计有关键字命中的差异项数
+func countFlagged(items []models.DiffItem) int {
+	n := 0
+	for _, item := range items {
+		if len(item.Findings) > 0 {
+			n++
+		}
+	}
+	return n
+}
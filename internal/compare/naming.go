@@ -0,0 +1,84 @@
+package compare
+
+import (
+	"Discrepancies/internal/tmpl"
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultZipNameTemplate 默认的 ZIP 文件名模板（不含扩展名）
+const DefaultZipNameTemplate = "{base}_{label}_{date}"
+
+// DefaultNameLabel 默认的文件名中缀，{label}
+const DefaultNameLabel = "差分"
+
+// ErrNameTemplate 命名模板无效、引用了为空的变量，或渲染结果不是有效的文件名
+var ErrNameTemplate = tmpl.ErrTemplate
+
+// NameVars 导出命名模板可引用的变量
+type NameVars struct {
+	Base   string    // 基准名称，{base}
+	Ticket string    // 构建号或工单号，{ticket}（别名 {build}）
+	Time   time.Time // 导出时间，{date}、{time}
+
+	DateFormat string // {date} 的格式，见 tmpl.DateLayout
+	Label      string // 文件名中缀，{label}，为空时为 DefaultNameLabel
+}
+
+// unsafeNameChars 变量值中不能出现在文件名里的字符，替换为 _
+var unsafeNameChars = strings.NewReplacer(
+	"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_",
+	"\"", "_", "<", "_", ">", "_", "|", "_",
+)
+
+// RenderName 渲染导出命名模板，支持 {base}、{label}、{ticket}、{build}、{date}、{time}、{env:变量名}，
+// 以及 text/template 写法（见 tmpl 包）。变量值中的路径分隔符等字符替换为 _
+func RenderName(text string, vars NameVars) (string, error) {
+	// 未设置的工单号在简单占位符中报错，在 text/template 中为空字符串，可用 {{if .ticket}} 判断
+	ticket := strings.TrimSpace(vars.Ticket)
+	values := map[string]string{
+		"base":   vars.Base,
+		"label":  cmp.Or(strings.TrimSpace(vars.Label), DefaultNameLabel),
+		"ticket": ticket,
+		"build":  ticket,
+	}
+	for k, v := range values {
+		values[k] = unsafeNameChars.Replace(v)
+	}
+	return tmpl.Render(text, tmpl.Context{Vars: values, Time: vars.Time, DateFormat: vars.DateFormat})
+}
+
+// RenderDirName 渲染输出子目录模板，模板中可用 / 分隔多级目录，结果必须是相对路径
+func RenderDirName(text string, vars NameVars) (string, error) {
+	rendered, err := RenderName(text, vars)
+	if err != nil {
+		return "", err
+	}
+	rendered = filepath.FromSlash(rendered)
+	if !filepath.IsLocal(rendered) {
+		return "", fmt.Errorf("%w: %s", ErrNameTemplate, rendered)
+	}
+	return rendered, nil
+}
+
+// GenerateZipName 按模板生成 ZIP 文件名，模板为空时使用 DefaultZipNameTemplate
+func GenerateZipName(text string, vars NameVars) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultZipNameTemplate
+	}
+	if vars.Time.IsZero() {
+		vars.Time = time.Now()
+	}
+	name, err := RenderName(text, vars)
+	if err != nil {
+		return "", err
+	}
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".zip")
+	if strings.ContainsAny(name, `/\`) || !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %s", ErrNameTemplate, name)
+	}
+	return name + ".zip", nil
+}
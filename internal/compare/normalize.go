@@ -0,0 +1,327 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ContentMaskPlaceholder 被屏蔽的文本在差异预览中显示为该占位符
+const ContentMaskPlaceholder = "‹已忽略›"
+
+// maxNormalizeSize 规范化的文件大小上限，更大的文件按原始内容比较
+const maxNormalizeSize = maxLineCountSize
+
+// NormalizeFunc 一个规范化步骤，返回处理后的内容
+type NormalizeFunc func(content []byte) []byte
+
+// normalizeSteps 可在规则中使用的规范化步骤，参数为步骤名冒号后的部分
+var normalizeSteps = map[string]func(arg string) (NormalizeFunc, error){
+	"eol":            func(string) (NormalizeFunc, error) { return normalizeEOL, nil },
+	"bom":            func(string) (NormalizeFunc, error) { return stripBOM, nil },
+	"utf8":           func(string) (NormalizeFunc, error) { return toUTF8, nil },
+	"trailing-space": func(string) (NormalizeFunc, error) { return trimTrailingSpace, nil },
+	"sort-keys":      func(string) (NormalizeFunc, error) { return sortXMLKeys, nil },
+	"mask":           newMaskStep,
+}
+
+// RegisterNormalizeStep 注册规范化步骤，规则中以 name 或 name:参数 引用
+func RegisterNormalizeStep(name string, factory func(arg string) (NormalizeFunc, error)) {
+	normalizeSteps[name] = factory
+}
+
+// Normalizer 比较和预览前按文件路径对内容依次执行的规范化步骤
+type Normalizer struct {
+	rules   []compiledNormalize
+	enabled []models.NormalizeRule     // 启用的规范化规则，用于检查点的设置摘要
+	ignores []models.ContentIgnoreRule // 启用的内容忽略规则
+}
+
+// compiledNormalize 编译后的规范化规则
+type compiledNormalize struct {
+	matcher *ExcludeMatcher // 路径匹配，为 nil 时适用于所有文本文件
+	exts    map[string]bool // 扩展名匹配（内容忽略规则），与 matcher 不同时使用
+	steps   []NormalizeFunc
+}
+
+// NewNormalizer 由规范化规则和内容忽略规则创建规范化器，没有可用规则时返回 nil
+// 跳过未启用的规则；无法解析的规则由 ValidateNormalizeRules、ValidateContentIgnoreRules 在保存时拒绝，手工编辑配置文件引入的会被跳过
+func NewNormalizer(rules []models.NormalizeRule, ignores []models.ContentIgnoreRule) *Normalizer {
+	n := &Normalizer{}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		n.enabled = append(n.enabled, rule)
+		if cr, err := compileNormalize(rule); err == nil && len(cr.steps) > 0 {
+			n.rules = append(n.rules, cr)
+		}
+	}
+
+	// 内容忽略规则相当于按扩展名匹配的 mask 步骤
+	for _, rule := range ignores {
+		if !rule.Enabled || rule.Pattern == "" {
+			continue
+		}
+		n.ignores = append(n.ignores, rule)
+		if cr, err := compileContentIgnore(rule); err == nil {
+			n.rules = append(n.rules, cr)
+		}
+	}
+
+	if len(n.rules) == 0 {
+		return nil
+	}
+	return n
+}
+
+// ValidateNormalizeRules 检查启用的规范化规则的步骤名和 mask 正则，保存配置和读取项目配置时调用
+func ValidateNormalizeRules(rules []models.NormalizeRule) error {
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if _, err := compileNormalize(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileNormalize 编译一条规范化规则，步骤名未知或参数无效时返回错误
+func compileNormalize(rule models.NormalizeRule) (compiledNormalize, error) {
+	cr := compiledNormalize{}
+	if rule.Pattern != "" {
+		cr.matcher = NewExcludeMatcher([]models.ExcludeRule{{Pattern: rule.Pattern, Type: "glob", Enabled: true}})
+	}
+	name := rule.Pattern
+	if name == "" {
+		name = strings.Join(rule.Steps, ", ")
+	}
+	for _, spec := range rule.Steps {
+		stepName, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		factory, ok := normalizeSteps[stepName]
+		if !ok {
+			return cr, &RuleError{Kind: "规范化规则", Name: name, Err: fmt.Errorf("unknown step %q", stepName)}
+		}
+		step, err := factory(arg)
+		if err != nil {
+			return cr, &RuleError{Kind: "规范化规则", Name: name, Err: fmt.Errorf("step %q: %w", spec, err)}
+		}
+		cr.steps = append(cr.steps, step)
+	}
+	return cr, nil
+}
+
+// ValidateContentIgnoreRules 检查启用的内容忽略规则能否解析，保存配置和读取项目配置时调用
+func ValidateContentIgnoreRules(rules []models.ContentIgnoreRule) error {
+	for _, rule := range rules {
+		if !rule.Enabled || rule.Pattern == "" {
+			continue
+		}
+		if _, err := compileContentIgnore(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileContentIgnore 编译一条内容忽略规则
+func compileContentIgnore(rule models.ContentIgnoreRule) (compiledNormalize, error) {
+	step, err := newMaskStep(rule.Pattern)
+	if err != nil {
+		return compiledNormalize{}, &RuleError{Kind: "内容忽略规则", Name: rule.Pattern, Err: err}
+	}
+	cr := compiledNormalize{steps: []NormalizeFunc{step}}
+	for _, ext := range rule.Extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if cr.exts == nil {
+			cr.exts = make(map[string]bool)
+		}
+		cr.exts[ext] = true
+	}
+	return cr, nil
+}
+
+// settings 返回创建规范化器的启用规则，未设置规范化器时为 nil
+func (n *Normalizer) settings() []any {
+	if n == nil {
+		return nil
+	}
+	return []any{n.enabled, n.ignores}
+}
+
+// matches 判断规则是否适用于该文件
+func (r compiledNormalize) matches(relPath string) bool {
+	switch {
+	case r.matcher != nil:
+		return r.matcher.ShouldExclude(relPath, false)
+	case r.exts != nil:
+		return r.exts[strings.ToLower(filepath.Ext(relPath))]
+	}
+	return IsTextFile(relPath)
+}
+
+// Applies 判断是否有规则适用于该文件
+func (n *Normalizer) Applies(relPath string) bool {
+	if n == nil {
+		return false
+	}
+	for _, rule := range n.rules {
+		if rule.matches(relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize 按规则顺序对内容执行适用的规范化步骤
+func (n *Normalizer) Normalize(relPath string, content []byte) []byte {
+	if n == nil {
+		return content
+	}
+	for _, rule := range n.rules {
+		if !rule.matches(relPath) {
+			continue
+		}
+		for _, step := range rule.steps {
+			content = step(content)
+		}
+	}
+	return content
+}
+
+// normalizeEOL 将 CRLF 和 CR 换行统一为 LF
+func normalizeEOL(content []byte) []byte {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(content, []byte("\r"), []byte("\n"))
+}
+
+// stripBOM 去掉开头的 UTF-8 BOM
+func stripBOM(content []byte) []byte {
+	return bytes.TrimPrefix(content, utf8BOM)
+}
+
+// trimTrailingSpace 去掉每行末尾的空格和制表符
+func trimTrailingSpace(content []byte) []byte {
+	lines := bytes.Split(content, []byte("\n"))
+	for i, line := range lines {
+		cr := bytes.HasSuffix(line, []byte("\r"))
+		line = bytes.TrimRight(bytes.TrimSuffix(line, []byte("\r")), " \t")
+		if cr {
+			line = append(line, '\r')
+		}
+		lines[i] = line
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// newMaskStep 创建屏蔽正则匹配内容的步骤：正则为多行模式，含捕获组时只屏蔽捕获组
+func newMaskStep(pattern string) (NormalizeFunc, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty mask pattern")
+	}
+	re, err := regexp.Compile("(?m)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	return func(content []byte) []byte { return maskMatches(re, content) }, nil
+}
+
+// maskMatches 将匹配的内容替换为 ContentMaskPlaceholder
+func maskMatches(re *regexp.Regexp, content []byte) []byte {
+	matches := re.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content
+	}
+
+	var buf bytes.Buffer
+	last := 0
+	for _, match := range matches {
+		// 有捕获组时屏蔽各捕获组，否则屏蔽整个匹配
+		spans := [][2]int{{match[0], match[1]}}
+		if len(match) > 2 {
+			spans = spans[:0]
+			for i := 2; i+1 < len(match); i += 2 {
+				if match[i] >= 0 {
+					spans = append(spans, [2]int{match[i], match[i+1]})
+				}
+			}
+		}
+		for _, span := range spans {
+			if span[0] < last {
+				continue // 嵌套的捕获组已被外层屏蔽
+			}
+			buf.Write(content[last:span[0]])
+			buf.WriteString(ContentMaskPlaceholder)
+			last = span[1]
+		}
+	}
+	buf.Write(content[last:])
+	return buf.Bytes()
+}
+
+// xmlKeyedElement .resx 的 <data name="..."> 和 .config 的 <add key="..."/> 元素
+// 自闭合的写法放在前面，避免从 <data .../> 一直匹配到下一个元素的 </data>
+var xmlKeyedElement = regexp.MustCompile(`(?s)<data\s+name="([^"]*)"[^>]*/>|<data\s+name="([^"]*)".*?</data>|<add\s+key="([^"]*)"[^>]*/>`)
+
+// sortXMLKeys 将连续排列（之间只有空白）的同类键值元素按键排序，元素间的空白保持原位
+// 用于消除 .resx、.config 中因编辑器重新排序导致的差异
+func sortXMLKeys(content []byte) []byte {
+	matches := xmlKeyedElement.FindAllSubmatchIndex(content, -1)
+	if len(matches) < 2 {
+		return content
+	}
+
+	type element struct {
+		key  string
+		text []byte
+	}
+	keyOf := func(m []int) string {
+		for i := 2; i+1 < len(m); i += 2 {
+			if m[i] >= 0 {
+				return string(content[m[i]:m[i+1]])
+			}
+		}
+		return ""
+	}
+
+	var buf bytes.Buffer
+	last := 0
+	for start := 0; start < len(matches); {
+		// 找出一组连续的元素
+		end := start + 1
+		for end < len(matches) && len(bytes.TrimSpace(content[matches[end-1][1]:matches[end][0]])) == 0 {
+			end++
+		}
+
+		run := matches[start:end]
+		elements := make([]element, len(run))
+		for i, m := range run {
+			elements[i] = element{key: keyOf(m), text: content[m[0]:m[1]]}
+		}
+		sort.SliceStable(elements, func(i, j int) bool { return elements[i].key < elements[j].key })
+
+		buf.Write(content[last:run[0][0]])
+		for i, m := range run {
+			if i > 0 {
+				buf.Write(content[run[i-1][1]:m[0]])
+			}
+			buf.Write(elements[i].text)
+		}
+		last = run[len(run)-1][1]
+		start = end
+	}
+	buf.Write(content[last:])
+	return buf.Bytes()
+}
@@ -0,0 +1,121 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"archive/zip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHashAlgorithm 比较内容使用的算法不是 crc32、md5 或 sha256
+var ErrInvalidHashAlgorithm = errors.New("hash algorithm must be crc32, md5 or sha256")
+
+// SetHashAlgorithm 设置大小相同的文件比较内容使用的算法，空字符串表示默认的 crc32
+// crc32 将工作目录文件的 CRC32 与 ZIP 中记录的比较，不读取基准；md5、sha256 读取两侧内容，可排除 CRC32 碰撞
+func (c *Comparer) SetHashAlgorithm(algo string) error {
+	switch algo {
+	case "", models.HashCRC32, models.HashMD5, models.HashSHA256:
+		c.hashAlgo = algo
+		return nil
+	}
+	return ErrInvalidHashAlgorithm
+}
+
+// SetFastMode 设置快速比较：大小相同且修改时间与 ZIP 中记录的相同（按 ZIP 时间戳的 2 秒精度）的文件不读取内容，视为相同
+func (c *Comparer) SetFastMode(enabled bool) {
+	c.fastMode = enabled
+}
+
+// SetConcurrency 设置扫描工作目录时并发读取目录的数量，0 或负数表示默认值
+func (c *Comparer) SetConcurrency(n int) {
+	c.concurrency = max(n, 0)
+}
+
+// SetCaseInsensitive 设置路径是否不区分大小写：工作目录中与 ZIP 只差大小写的文件视为同一文件，结果中使用 ZIP 中的写法
+func (c *Comparer) SetCaseInsensitive(enabled bool) {
+	c.ignoreCase = enabled
+}
+
+// hashName 返回比较内容使用的算法名称
+func (c *Comparer) hashName() string {
+	if c.hashAlgo == "" {
+		return models.HashCRC32
+	}
+	return c.hashAlgo
+}
+
+// newHash 返回比较内容使用的哈希
+func (c *Comparer) newHash() hash.Hash {
+	switch c.hashAlgo {
+	case models.HashMD5:
+		return md5.New()
+	case models.HashSHA256:
+		return sha256.New()
+	}
+	return crc32.NewIEEE()
+}
+
+// zipChecksum 返回 ZIP 中文件的校验和：crc32 直接使用 ZIP 中记录的值，其他算法读取内容计算
+func (c *Comparer) zipChecksum(f *zip.File) ([]byte, error) {
+	if c.hashName() == models.HashCRC32 {
+		return binary.BigEndian.AppendUint32(nil, f.CRC32), nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	h := c.newHash()
+	n, err := io.Copy(h, rc)
+	if err != nil {
+		return nil, err
+	}
+	c.stats.BytesHashed += n
+	return h.Sum(nil), nil
+}
+
+// sameModTime 判断工作目录文件的修改时间与 ZIP 中记录的是否相同（相差不到 2 秒）
+// 只有 MS-DOS 时间戳的条目没有时区，读取时按 UTC 解析，这里按本地时间比较
+func sameModTime(f *zip.File, modTime time.Time) bool {
+	recorded := f.Modified
+	if recorded.IsZero() {
+		return false
+	}
+	if recorded.Location() == time.UTC {
+		recorded = time.Date(recorded.Year(), recorded.Month(), recorded.Day(),
+			recorded.Hour(), recorded.Minute(), recorded.Second(), 0, time.Local)
+	}
+	diff := modTime.Sub(recorded)
+	return diff > -2*time.Second && diff < 2*time.Second
+}
+
+// foldCase 将工作目录中与 ZIP 只差大小写的路径改为 ZIP 中的写法，使两侧按同一文件比较
+func (c *Comparer) foldCase(zipFiles map[string]*zip.File, workFiles map[string]string) map[string]string {
+	zipPaths := make(map[string]string, len(zipFiles))
+	for relPath := range zipFiles {
+		zipPaths[strings.ToLower(relPath)] = relPath
+	}
+
+	folded := make(map[string]string, len(workFiles))
+	for relPath, workFilePath := range workFiles {
+		if _, exact := zipFiles[relPath]; !exact {
+			if zipPath, ok := zipPaths[strings.ToLower(relPath)]; ok {
+				if _, taken := workFiles[zipPath]; !taken {
+					c.tracef("fold case %q -> %q", relPath, zipPath)
+					relPath = zipPath
+				}
+			}
+		}
+		folded[relPath] = workFilePath
+	}
+	return folded
+}
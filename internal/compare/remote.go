@@ -0,0 +1,156 @@
+package compare
+
+import (
+	"Discrepancies/internal/encrypt"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/remote"
+	"Discrepancies/internal/signing"
+	"bytes"
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExportDiffsToRemote 将差异文件逐个上传到远程目标（SFTP 目录或 S3 前缀），内容从源文件流式写入，不在本地暂存
+// 变更日志、校验和文件、签名和基准 ZIP 的保存与导出到本地目录相同；备用数据流、导出进度记录和导出后校验只用于本地导出
+func ExportDiffsToRemote(items []models.DiffItem, dest remote.Destination, opts ExportOptions, onProgress func(current, total int, message string)) error {
+	if err := ValidateEOL(opts.EOL); err != nil {
+		return err
+	}
+	selectedItems := make([]models.DiffItem, 0)
+	for _, item := range items {
+		if item.Selected && item.Type != "deleted" {
+			selectedItems = append(selectedItems, item)
+		}
+	}
+	if err := checkChangelog(selectedItems, opts); err != nil {
+		return err
+	}
+	if err := checkEvidence(itemPaths(selectedItems), opts); err != nil {
+		return err
+	}
+
+	sums := newChecksumSet(opts)
+	for i, item := range selectedItems {
+		if onProgress != nil {
+			onProgress(i+1, len(selectedItems), fmt.Sprintf("上传: %s", item.RelPath))
+		}
+		src, err := openExport(item.SourcePath, item.RelPath, opts.EOL)
+		if err != nil {
+			return &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to upload file: %w", err)}
+		}
+		err = uploadFile(dest, filepath.ToSlash(item.RelPath), src, sums.add(filepath.ToSlash(item.RelPath)))
+		src.Close()
+		if err != nil {
+			return &ItemError{RelPath: item.RelPath, Err: fmt.Errorf("failed to upload file: %w", err)}
+		}
+	}
+
+	if err := uploadEvidence(dest, opts, sums, onProgress); err != nil {
+		return err
+	}
+	if opts.Changelog != "" {
+		if err := uploadFile(dest, ChangelogName, strings.NewReader(opts.Changelog), sums.add(ChangelogName)); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", ChangelogName, err)
+		}
+	}
+	contents := sums.contents()
+	for name, content := range contents {
+		if err := uploadFile(dest, name, strings.NewReader(content)); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", name, err)
+		}
+	}
+	if opts.SigningKey != "" {
+		digest := sha512.Sum512([]byte(contents[SHA256SumsName]))
+		if err := uploadSignature(dest, SHA256SumsName+signing.SignatureExt, digest[:], opts.SigningKey); err != nil {
+			return fmt.Errorf("failed to sign %s: %w", SHA256SumsName, err)
+		}
+	}
+	return nil
+}
+
+// ExportZipToRemote 将差异文件打包为 ZIP，边打包边上传到远程目标中的 zipName，不在本地生成 ZIP
+// 加密、校验和和签名与导出为本地 ZIP 相同
+func ExportZipToRemote(items []models.DiffItem, dest remote.Destination, zipName string, opts ExportOptions, onProgress func(current, total int, message string)) error {
+	selectedItems, err := zipExportItems(items, opts)
+	if err != nil {
+		return err
+	}
+	if err := checkEvidence([]string{zipName}, opts); err != nil {
+		return err
+	}
+
+	w, err := dest.Create(zipName)
+	if err != nil {
+		return fmt.Errorf("failed to create remote zip file: %w", err)
+	}
+	digest, err := writeExportZip(w, selectedItems, opts, onProgress)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to upload zip file: %w", err)
+	}
+	if err := uploadEvidence(dest, opts, nil, onProgress); err != nil {
+		return err
+	}
+
+	if opts.SigningKey != "" {
+		sigName := strings.TrimSuffix(zipName, encrypt.FileExt) + signing.SignatureExt
+		if err := uploadSignature(dest, sigName, digest, opts.SigningKey); err != nil {
+			return fmt.Errorf("failed to sign zip file: %w", err)
+		}
+	}
+	return nil
+}
+
+// uploadFile 将 r 的内容写入远程文件，同时写入 extra
+func uploadFile(dest remote.Destination, relPath string, r io.Reader, extra ...io.Writer) error {
+	w, err := dest.Create(relPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(io.MultiWriter(append([]io.Writer{w}, extra...)...), r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// uploadEvidence 将基准 ZIP 和比较结果上传到远程目标，内容同时计入校验和；远程目标不能创建硬链接，总是上传
+func uploadEvidence(dest remote.Destination, opts ExportOptions, sums *checksumSet, onProgress func(current, total int, message string)) error {
+	if opts.Baseline != "" {
+		name := filepath.Base(opts.Baseline)
+		if onProgress != nil {
+			onProgress(1, 1, fmt.Sprintf("上传基准: %s", name))
+		}
+		src, err := os.Open(opts.Baseline)
+		if err != nil {
+			return fmt.Errorf("failed to archive baseline: %w", err)
+		}
+		err = uploadFile(dest, name, src, sums.add(name))
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("failed to archive baseline: %w", err)
+		}
+	}
+	if len(opts.Result) > 0 {
+		if err := uploadFile(dest, ResultName, bytes.NewReader(opts.Result), sums.add(ResultName)); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", ResultName, err)
+		}
+	}
+	return nil
+}
+
+// uploadSignature 对摘要签名，写入远程的 .sig 文件
+func uploadSignature(dest remote.Destination, sigName string, digest []byte, keyPath string) error {
+	content, err := signing.Signature(digest, keyPath)
+	if err != nil {
+		return err
+	}
+	return uploadFile(dest, sigName, bytes.NewReader(content))
+}
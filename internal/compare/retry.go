@@ -0,0 +1,65 @@
+package compare
+
+import (
+	"os"
+	"time"
+)
+
+// 读取工作目录文件失败时的默认重试设置，构建过程中正在写入的文件常会短暂无法打开
+const (
+	DefaultReadRetries      = 2
+	DefaultReadRetryBackoff = 200 * time.Millisecond
+)
+
+// SetReadRetry 设置读取工作目录文件失败时的重试次数和首次重试前的等待时间（之后每次加倍）
+// retries 为 0 时使用默认值，负数表示不重试
+func (c *Comparer) SetReadRetry(retries int, backoff time.Duration) {
+	c.readRetries = retries
+	c.readBackoff = backoff
+}
+
+// checksumWorkFile 用比较内容的算法计算工作目录文件的校验和，失败时按设置重试
+// 文件在各次尝试之间或读取过程中大小、修改时间发生变化时 unstable 为 true，说明文件正在被写入
+func (c *Comparer) checksumWorkFile(path string) (sum []byte, n int64, unstable bool, err error) {
+	retries := c.readRetries
+	if retries == 0 {
+		retries = DefaultReadRetries
+	}
+	backoff := c.readBackoff
+	if backoff <= 0 {
+		backoff = DefaultReadRetryBackoff
+	}
+
+	var first os.FileInfo
+	for attempt := 0; ; attempt++ {
+		before, statErr := os.Stat(path)
+		if statErr == nil {
+			if first == nil {
+				first = before
+			} else if changed(first, before) {
+				unstable = true
+			}
+		}
+
+		sum, n, err = fileChecksum(path, c.newHash())
+		if err == nil {
+			if after, statErr := os.Stat(path); statErr == nil && before != nil && changed(before, after) {
+				unstable = true
+			}
+			return sum, n, unstable, nil
+		}
+		if attempt >= retries {
+			return nil, n, unstable, err
+		}
+
+		c.stats.ReadRetries++
+		c.tracef("read %s failed (attempt %d): %v, retry in %s", path, attempt+1, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// changed 判断两次获取的文件信息中大小或修改时间是否不同
+func changed(a, b os.FileInfo) bool {
+	return a.Size() != b.Size() || !a.ModTime().Equal(b.ModTime())
+}
@@ -0,0 +1,60 @@
+package compare
+
+import (
+	"archive/zip"
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidSubPath 子目录不是 ZIP 根目录和工作目录下的相对路径
+var ErrInvalidSubPath = errors.New("sub path must be relative and stay inside the root")
+
+// SetSubPath 将比较限定在子目录内，路径相对于 ZIP 的根目录和工作目录，空字符串表示比较全部文件
+// 结果中的路径仍相对于根目录
+func (c *Comparer) SetSubPath(subPath string) error {
+	cleaned, err := CleanSubPath(subPath)
+	if err != nil {
+		return err
+	}
+	c.subPath = cleaned
+	return nil
+}
+
+// CleanSubPath 规范化子目录路径为不带首尾斜杠的正斜杠形式，"." 和空字符串返回空字符串
+func CleanSubPath(subPath string) (string, error) {
+	subPath = strings.TrimSpace(filepath.ToSlash(subPath))
+	if subPath == "" {
+		return "", nil
+	}
+	if path.IsAbs(subPath) || filepath.IsAbs(subPath) || filepath.VolumeName(subPath) != "" {
+		return "", ErrInvalidSubPath
+	}
+	cleaned := path.Clean(subPath)
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidSubPath
+	}
+	return cleaned, nil
+}
+
+// inScope 判断根目录下的相对路径是否在比较范围内
+func (c *Comparer) inScope(relPath string) bool {
+	return c.subPath == "" || strings.HasPrefix(relPath, c.subPath+"/")
+}
+
+// scopeZipFiles 去掉 ZIP 文件列表中不在比较范围内的文件
+func (c *Comparer) scopeZipFiles(files map[string]*zip.File) map[string]*zip.File {
+	if c.subPath == "" {
+		return files
+	}
+	for relPath := range files {
+		if !c.inScope(relPath) {
+			delete(files, relPath)
+		}
+	}
+	return files
+}
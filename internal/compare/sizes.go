@@ -0,0 +1,75 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"sort"
+)
+
+// DefaultLargestCount 比较结果中默认列出的大小增加最多的文件数
+const DefaultLargestCount = 10
+
+// SetLargestCount 设置比较结果中列出的大小增加最多的文件数，0 表示默认值，负数表示不列出
+func (c *Comparer) SetLargestCount(n int) {
+	c.largestCount = n
+}
+
+// LargestChanges 返回大小增加最多的 n 个新增、修改和重命名的文件，按增加量降序
+func LargestChanges(items []models.DiffItem, n int) []models.SizeChange {
+	changes := make([]models.SizeChange, 0)
+	if n <= 0 {
+		return changes
+	}
+	for _, item := range items {
+		if item.Type == "deleted" || item.Type == TypeUnchanged || item.Stream != "" {
+			continue
+		}
+		if delta := item.NewSize - item.OldSize; delta > 0 {
+			changes = append(changes, models.SizeChange{
+				RelPath: item.RelPath,
+				Type:    item.Type,
+				OldSize: item.OldSize,
+				NewSize: item.NewSize,
+				Delta:   delta,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Delta != changes[j].Delta {
+			return changes[i].Delta > changes[j].Delta
+		}
+		return changes[i].RelPath < changes[j].RelPath
+	})
+	if len(changes) > n {
+		changes = changes[:n]
+	}
+	return changes
+}
+
+// largest 按设置的数量返回比较结果中大小增加最多的文件
+func (c *Comparer) largest(items []models.DiffItem) []models.SizeChange {
+	n := c.largestCount
+	if n == 0 {
+		n = DefaultLargestCount
+	}
+	return LargestChanges(items, n)
+}
+
+// sumSizes 按差异类型汇总大小：新增文件的总大小、修改和重命名文件的大小变化之和、删除文件的总大小
+// 数据流和读取失败（大小未知）的文件不计入
+func sumSizes(result *models.CompareResult) {
+	result.TotalAddedBytes, result.TotalModifiedBytesDelta, result.TotalDeletedBytes = 0, 0, 0
+	for _, item := range result.Items {
+		if item.Stream != "" || item.Error != "" {
+			continue
+		}
+		switch item.Type {
+		case "added":
+			result.TotalAddedBytes += item.NewSize
+		case "modified", "renamed":
+			result.TotalModifiedBytesDelta += item.NewSize - item.OldSize
+		case "deleted":
+			result.TotalDeletedBytes += item.OldSize
+		}
+	}
+}
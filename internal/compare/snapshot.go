@@ -0,0 +1,194 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// SnapshotExt 快照文件的扩展名；内容与 SHA256SUMS 相同（每行一个文件的 SHA-256 和相对路径），可用 sha256sum -c 校验
+const SnapshotExt = ".sha256"
+
+// ErrNoSnapshot 工作目录还没有记录快照
+var ErrNoSnapshot = errors.New("snapshot not found")
+
+// IsSnapshot 判断基准是否是快照文件
+func IsSnapshot(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), SnapshotExt)
+}
+
+// Snapshot 计算工作目录中所有文件（遵循排除规则、.gitignore、子目录和层数限制）的 SHA-256，写入快照文件 dest，返回记录的文件数
+// 先写临时文件再重命名，记录失败时保留原有的快照
+func (c *Comparer) Snapshot(dest string) (int, error) {
+	workFiles, err := c.scanWorkFiles()
+	if err != nil {
+		return 0, err
+	}
+	paths := make([]string, 0, len(workFiles))
+	for relPath := range workFiles {
+		if !c.shouldExclude(relPath, false) {
+			paths = append(paths, relPath)
+		}
+	}
+	sort.Strings(paths)
+
+	var sb strings.Builder
+	for i, relPath := range paths {
+		c.emitProgress(i+1, len(paths), fmt.Sprintf("记录: %s", relPath))
+		sum, n, err := fileChecksum(workFiles[relPath], sha256.New())
+		if err != nil {
+			return 0, fmt.Errorf("failed to hash %s: %w", relPath, err)
+		}
+		c.stats.FilesScanned++
+		c.stats.BytesHashed += n
+		fmt.Fprintf(&sb, "%s  %s\n", hex.EncodeToString(sum), relPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return 0, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	tmpPath := dest + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(sb.String()), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, dest); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return len(paths), nil
+}
+
+// CompareSnapshot 以 Snapshot 记录的快照为基准比较工作目录
+// 快照只有哈希，没有文件内容和大小：修改的文件不统计增删行数，基准一侧的大小为 0
+// 快照中不在当前比较范围（子目录、层数）内或现在被排除的文件不视为删除
+func (c *Comparer) CompareSnapshot(snapshotPath string) (*models.CompareResult, error) {
+	c.stats = models.CompareStats{}
+	file, err := os.Open(snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSnapshot
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	sums, err := parseSums(file)
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	workFiles, err := c.scanWorkFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CompareResult{
+		Items:    make([]models.DiffItem, 0),
+		Skipped:  make([]models.PathIssue, 0),
+		MaxDepth: c.maxDepth,
+	}
+	base := pathDepth(c.subPath)
+	paths := make([]string, 0, len(sums)+len(workFiles))
+	for relPath := range sums {
+		if c.inScope(relPath) && (c.maxDepth == 0 || pathDepth(relPath) <= base+c.maxDepth) {
+			paths = append(paths, relPath)
+		}
+	}
+	for relPath := range workFiles {
+		if _, ok := sums[relPath]; !ok {
+			paths = append(paths, relPath)
+		}
+	}
+	sort.Strings(paths)
+
+	for i, relPath := range paths {
+		if c.shouldExclude(relPath, false) {
+			result.Excluded++
+			continue
+		}
+		c.stats.FilesScanned++
+		c.emitProgress(i+1, len(paths), fmt.Sprintf("检查: %s", relPath))
+
+		oldSum, inSnapshot := sums[relPath]
+		workFilePath, exists := workFiles[relPath]
+		switch {
+		case !exists:
+			result.Items = append(result.Items, models.DiffItem{RelPath: relPath, Type: "deleted", Selected: true})
+			result.Deleted++
+		case !inSnapshot:
+			item := models.DiffItem{RelPath: relPath, Type: "added", Selected: true, SourcePath: workFilePath}
+			if info, err := os.Stat(workFilePath); err == nil {
+				item.NewSize = info.Size()
+			}
+			c.scanKeywords(&item, nil)
+			result.Items = append(result.Items, item)
+			result.Added++
+		default:
+			sum, n, err := fileChecksum(workFilePath, sha256.New())
+			if err != nil {
+				c.tracef("checksum work %s failed: %v", workFilePath, err)
+				result.Items = append(result.Items, failedItem(relPath, workFilePath, "读取工作目录中的文件失败", err))
+				result.Failed++
+				continue
+			}
+			c.stats.BytesHashed += n
+			workSum := hex.EncodeToString(sum)
+			c.tracef("sha256 %s snapshot=%s work=%s", relPath, oldSum, workSum)
+			if workSum == oldSum {
+				result.Unchanged++
+				if c.listUnchanged {
+					result.Items = append(result.Items, models.DiffItem{RelPath: relPath, Type: TypeUnchanged, SourcePath: workFilePath, NewSize: n})
+				}
+				continue
+			}
+			item := models.DiffItem{RelPath: relPath, Type: "modified", Selected: true, SourcePath: workFilePath, NewSize: n}
+			c.scanKeywords(&item, nil)
+			result.Items = append(result.Items, item)
+			result.Modified++
+		}
+	}
+
+	c.markGenerated(result)
+	result.TotalFiles = countDiffs(result)
+	result.Flagged = countFlagged(result.Items)
+	result.Largest = c.largest(result.Items)
+	sumSizes(result)
+	return result, nil
+}
+
+// scanWorkFiles 列出工作目录中比较范围内的文件，命名管道等特殊文件不计入
+func (c *Comparer) scanWorkFiles() (map[string]string, error) {
+	var found atomic.Int64
+	stop := c.startHeartbeat(PhaseScan, func() (int, string) {
+		n := int(found.Load())
+		return n, fmt.Sprintf("扫描工作目录… 已发现 %d 个文件", n)
+	})
+	workFiles, _, _, err := getAllFilesAndDirs(c.workDir, c.subPath, c.maxDepth, c.concurrency, c.gitignore, c.tracef, &found)
+	stop()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list work directory files: %w", err)
+	}
+	return workFiles, nil
+}
+
+// parseSums 读取 sha256sum 格式的清单，返回相对路径到小写十六进制哈希的映射，格式不符的行忽略
+func parseSums(r io.Reader) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		sum, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			continue
+		}
+		sums[name] = strings.ToLower(sum)
+	}
+	return sums, scanner.Err()
+}
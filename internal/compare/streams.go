@@ -0,0 +1,81 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/platform"
+	"sort"
+)
+
+// SetCompareStreams 设置是否比较工作目录文件的 NTFS 备用数据流（仅 Windows 生效）
+func (c *Comparer) SetCompareStreams(enabled bool) {
+	c.compareStreams = enabled
+}
+
+// addStreamItems 列出工作目录文件的备用数据流，作为文件差异项的子项
+// ZIP 不保存备用数据流，因此所有数据流都视为新增；数据流路径（如 a.txt:Zone.Identifier）同样受排除规则约束。
+// 内容未变化但带有数据流的文件也列为已修改，以便查看和导出其数据流
+func (c *Comparer) addStreamItems(result *models.CompareResult, workFiles map[string]string) {
+	if !c.compareStreams {
+		return
+	}
+
+	index := make(map[string]int, len(result.Items))
+	for i, item := range result.Items {
+		index[item.RelPath] = i
+	}
+
+	relPaths := make([]string, 0, len(workFiles))
+	for relPath := range workFiles {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+
+	for _, relPath := range relPaths {
+		if c.shouldExclude(relPath, false) {
+			continue
+		}
+
+		workFilePath := workFiles[relPath]
+		streams, err := platform.ListStreams(workFilePath)
+		if err == platform.ErrStreamsUnsupported {
+			return
+		}
+		if err != nil {
+			c.tracef("list streams %s failed: %v", workFilePath, err)
+			continue
+		}
+
+		children := make([]models.DiffItem, 0, len(streams))
+		for _, stream := range streams {
+			streamPath := relPath + ":" + stream.Name
+			if c.shouldExclude(streamPath, false) {
+				continue
+			}
+			c.tracef("stream %s (%d bytes)", streamPath, stream.Size)
+			children = append(children, models.DiffItem{
+				RelPath:    streamPath,
+				Type:       "added",
+				Selected:   true,
+				SourcePath: workFilePath + ":" + stream.Name,
+				Stream:     stream.Name,
+			})
+		}
+		if len(children) == 0 {
+			continue
+		}
+
+		i, ok := index[relPath]
+		if !ok {
+			result.Items = append(result.Items, models.DiffItem{
+				RelPath:    relPath,
+				Type:       "modified",
+				Selected:   true,
+				SourcePath: workFilePath,
+			})
+			result.Modified++
+			i = len(result.Items) - 1
+			index[relPath] = i
+		}
+		result.Items[i].Streams = children
+	}
+}
@@ -0,0 +1,45 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"archive/zip"
+)
+
+// TypeUnchanged 内容相同的文件，只在启用 SetListUnchanged 时出现在结果中
+const TypeUnchanged = "unchanged"
+
+// SetListUnchanged 设置是否在结果中列出内容相同的文件（类型为 unchanged，不选中）
+// 未启用时结果中仍有相同文件的数量和总大小
+func (c *Comparer) SetListUnchanged(enabled bool) {
+	c.listUnchanged = enabled
+}
+
+// addUnchanged 记录一个确认相同的文件
+func (c *Comparer) addUnchanged(result *models.CompareResult, relPath, workFilePath string, zipFile *zip.File, n int64) {
+	result.Unchanged++
+	result.UnchangedBytes += int64(zipFile.UncompressedSize64)
+	if !c.listUnchanged {
+		return
+	}
+	result.Items = append(result.Items, models.DiffItem{
+		RelPath:    relPath,
+		Type:       TypeUnchanged,
+		SourcePath: workFilePath,
+		OldSize:    int64(zipFile.UncompressedSize64),
+		NewSize:    n,
+	})
+}
+
+// countDiffs 返回差异项数，不含列出的相同文件
+func countDiffs(result *models.CompareResult) int {
+	if result.Unchanged == 0 {
+		return len(result.Items)
+	}
+	n := 0
+	for _, item := range result.Items {
+		if item.Type != TypeUnchanged {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,93 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"archive/zip"
+	"bytes"
+	"io"
+)
+
+// SetIgnoreEncoding 设置是否忽略只在 UTF-16 与 UTF-8 之间转换了编码的修改：启用后解码后文本相同的文件视为相同，未启用时仍列为修改并标记 EncodingOnly
+func (c *Comparer) SetIgnoreEncoding(enabled bool) {
+	c.ignoreEncoding = enabled
+}
+
+// EncodingNormalizeRule 忽略编码时在规范化规则前加入的规则，使差异预览和其他规范化步骤同样按解码后的文本处理
+func EncodingNormalizeRule() models.NormalizeRule {
+	return models.NormalizeRule{Steps: []string{"utf8"}, Enabled: true, Comment: "忽略 UTF-16 与 UTF-8 的编码差异"}
+}
+
+// unicodeEncoding 根据开头的 BOM 判断 Unicode 编码，没有 BOM 时返回空字符串
+func unicodeEncoding(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, utf8BOM):
+		return models.EncodingUTF8BOM
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		return models.EncodingUTF16LE
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		return models.EncodingUTF16BE
+	}
+	return ""
+}
+
+// isUTF16 判断内容是否以 UTF-16 BOM 开头
+func isUTF16(content []byte) bool {
+	enc := unicodeEncoding(content)
+	return enc == models.EncodingUTF16LE || enc == models.EncodingUTF16BE
+}
+
+// toUTF8 将带 BOM 的 UTF-16（LE、BE）和 UTF-8 内容转换为不带 BOM 的 UTF-8，其他内容和无法解码的内容原样返回
+func toUTF8(content []byte) []byte {
+	enc := unicodeEncoding(content)
+	if enc == "" {
+		return content
+	}
+	text, ok := decodeText(content, enc)
+	if !ok {
+		return content
+	}
+	return []byte(text)
+}
+
+// encodingOnly 判断哈希不同的两个文件是否只在 UTF-16 与 UTF-8 之间转换了编码（或改变了 UTF-16 的字节顺序）
+// 先读取两侧开头的 BOM，至少一侧是 UTF-16 且两侧编码不同时才读取全部内容，其他文件没有额外开销
+func (c *Comparer) encodingOnly(f *zip.File, workFilePath string, workSize int64) bool {
+	if max(int64(f.UncompressedSize64), workSize) > maxNormalizeSize {
+		return false
+	}
+	newContent, _, err := readFileHead(workFilePath, 3)
+	if err != nil {
+		return false
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	head := make([]byte, 3)
+	n, _ := io.ReadFull(rc, head)
+	head = head[:n]
+	if !isUTF16(head) && !isUTF16(newContent) || unicodeEncoding(head) == unicodeEncoding(newContent) {
+		return false
+	}
+
+	rest, err := io.ReadAll(rc)
+	if err != nil {
+		return false
+	}
+	oldContent := append(head, rest...)
+	newContent, _, err = readFileHead(workFilePath, maxNormalizeSize+1)
+	if err != nil {
+		return false
+	}
+	return encodingOnlyContent(oldContent, newContent)
+}
+
+// encodingOnlyContent 判断两侧内容是否只在 UTF-16 与 UTF-8 之间转换了编码（或改变了 UTF-16 的字节顺序）
+func encodingOnlyContent(oldContent, newContent []byte) bool {
+	if !isUTF16(oldContent) && !isUTF16(newContent) || unicodeEncoding(oldContent) == unicodeEncoding(newContent) {
+		return false
+	}
+	return bytes.Equal(toUTF8(oldContent), toUTF8(newContent))
+}
@@ -0,0 +1,148 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/signing"
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrVerifyFailed 导出后重新读取的文件与写入时计算的哈希不一致（如网络共享静默损坏了数据）
+var ErrVerifyFailed = errors.New("exported files do not match their sources")
+
+// VerifyError 导出后校验未通过的文件
+type VerifyError struct {
+	Files []string // 内容不一致或无法读取的导出文件（相对路径，ZIP 导出时为 ZIP 文件名）
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrVerifyFailed, strings.Join(e.Files, ", "))
+}
+
+func (e *VerifyError) Unwrap() error {
+	return ErrVerifyFailed
+}
+
+// exportedFile 导出时写入的文件及写入内容的 SHA-256，供导出后校验
+type exportedFile struct {
+	relPath string
+	path    string
+	sha256  string
+}
+
+// verifyExported 重新读取导出的文件，与写入时计算的哈希比较，返回不一致或无法读取的文件
+func verifyExported(files []exportedFile, onProgress func(current, total int, message string)) []string {
+	mismatched := make([]string, 0)
+	for i, f := range files {
+		if onProgress != nil {
+			onProgress(i+1, len(files), fmt.Sprintf("校验: %s", f.relPath))
+		}
+		sum, _, err := fileChecksum(f.path, sha256.New())
+		if err != nil || hex.EncodeToString(sum) != f.sha256 {
+			mismatched = append(mismatched, f.relPath)
+		}
+	}
+	return mismatched
+}
+
+// VerifyPackage 校验导出的 ZIP：按包内 SHA256SUMS 校验每个文件，并用 publicKeys 校验 ZIP 旁的分离签名
+func VerifyPackage(zipPath string, publicKeys []string) (*models.PackageVerifyReport, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip file: %w", err)
+	}
+	defer reader.Close()
+
+	report := &models.PackageVerifyReport{
+		Tampered: make([]string, 0),
+		Missing:  make([]string, 0),
+		Unlisted: make([]string, 0),
+	}
+
+	entries := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		if !f.FileInfo().IsDir() {
+			entries[f.Name] = f
+		}
+	}
+
+	if manifest := entries[SHA256SumsName]; manifest != nil {
+		report.HasManifest = true
+		sums, err := readSumsFile(manifest)
+		if err != nil {
+			return nil, err
+		}
+		report.Files = len(sums)
+		for name, want := range sums {
+			f := entries[name]
+			if f == nil {
+				report.Missing = append(report.Missing, name)
+				continue
+			}
+			got, err := zipEntrySHA256(f)
+			if err != nil || got != want {
+				report.Tampered = append(report.Tampered, name)
+				continue
+			}
+			report.Verified++
+		}
+		for name := range entries {
+			if _, listed := sums[name]; !listed && name != SHA256SumsName && name != MD5SumsName {
+				report.Unlisted = append(report.Unlisted, name)
+			}
+		}
+	}
+	sort.Strings(report.Tampered)
+	sort.Strings(report.Missing)
+	sort.Strings(report.Unlisted)
+
+	switch err := signing.VerifyFile(zipPath, publicKeys); {
+	case err == nil:
+		report.Signature = models.SignatureValid
+	case errors.Is(err, os.ErrNotExist):
+		report.Signature = models.SignatureMissing
+	default:
+		report.Signature = models.SignatureInvalid
+	}
+
+	report.Valid = report.HasManifest && report.Signature == models.SignatureValid &&
+		len(report.Tampered) == 0 && len(report.Missing) == 0 && len(report.Unlisted) == 0
+	return report, nil
+}
+
+// readSumsFile 读取 sha256sum 格式的清单，返回路径到哈希的映射
+func readSumsFile(f *zip.File) (map[string]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	sums, err := parseSums(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", SHA256SumsName, err)
+	}
+	return sums, nil
+}
+
+// zipEntrySHA256 计算 ZIP 条目内容的 SHA-256（十六进制）
+func zipEntrySHA256(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,183 @@
+package compare
+
+import (
+	"Discrepancies/internal/models"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// SkipReasonSpecialFile 跳过命名管道、套接字、设备等特殊文件的原因
+const SkipReasonSpecialFile = "skipped: special file"
+
+// specialFileMode 非普通文件的类型位（不含目录和符号链接）
+const specialFileMode = fs.ModeNamedPipe | fs.ModeSocket | fs.ModeDevice | fs.ModeCharDevice | fs.ModeIrregular
+
+// walkWorkers 并发读取目录的最大数量
+// 网络驱动器上读取目录的延迟远大于 CPU 开销，因此数量高于 CPU 核数
+var walkWorkers = max(16, runtime.NumCPU()*2)
+
+// dirWalker 并发遍历目录树，各子树的读取目录操作并行执行
+type dirWalker struct {
+	root     string
+	ignore   *nestedIgnore
+	trace    func(format string, args ...any)
+	maxDepth int // 进入子目录的最大层数（相对于 base 所在的层），0 表示不限制
+	base     int // 起始目录所在的层数
+
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu      sync.Mutex
+	files   map[string]string
+	dirs    map[string]bool
+	skipped []models.PathIssue
+	err     error
+
+	found *atomic.Int64 // 已发现的文件数，供心跳事件读取，可为 nil
+}
+
+// getAllFilesAndDirs 获取目录下的所有文件和子目录
+// ignore 不为 nil 时，遍历过程中加载各层 .gitignore 并跳过被忽略的文件和目录；trace 记录路径规范化和跳过的条目
+// 命名管道、套接字、设备文件读取时可能阻塞，不计入文件列表，作为跳过的文件返回；found 不为 nil 时累计已发现的文件数
+// start 不为空时只遍历该子目录（正斜杠形式，相对于 root），返回的路径仍相对于 root；maxDepth 大于 0 时只遍历 start 下的前 maxDepth 层
+// workers 为并发读取目录的数量，0 表示默认值
+func getAllFilesAndDirs(root, start string, maxDepth, workers int, ignore *nestedIgnore, trace func(format string, args ...any), found *atomic.Int64) (map[string]string, map[string]bool, []models.PathIssue, error) {
+	if workers <= 0 {
+		workers = walkWorkers
+	}
+	w := &dirWalker{
+		root:     root,
+		ignore:   ignore,
+		trace:    trace,
+		maxDepth: maxDepth,
+		base:     pathDepth(start),
+		sem:      make(chan struct{}, workers),
+		files:    make(map[string]string),
+		dirs:     make(map[string]bool),
+		found:    found,
+	}
+
+	startDir := filepath.Join(root, filepath.FromSlash(start))
+	if _, err := os.Lstat(startDir); err != nil {
+		return w.files, w.dirs, w.skipped, err
+	}
+	if ignore != nil {
+		ignore.loadDir(root, "")
+		// 子目录及其外层各级目录的 .gitignore 同样作用于子目录中的文件
+		if start != "" {
+			relDir := ""
+			for _, name := range strings.Split(start, "/") {
+				relDir = path.Join(relDir, name)
+				ignore.loadDir(filepath.Join(root, filepath.FromSlash(relDir)), relDir)
+			}
+		}
+	}
+
+	w.walk(startDir, start)
+	w.wg.Wait()
+	return w.files, w.dirs, w.skipped, w.err
+}
+
+// walk 读取目录并处理其中的条目，子目录有空闲名额时交给新的 goroutine，否则在当前 goroutine 中递归
+func (w *dirWalker) walk(absDir, relDir string) {
+	entries, err := os.ReadDir(absDir)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(absDir, entry.Name())
+		relPath := entry.Name()
+		if relDir != "" {
+			relPath = relDir + "/" + entry.Name()
+		}
+		isDir := entry.IsDir()
+
+		// SVN 元数据目录始终跳过
+		if isDir && entry.Name() == ".svn" {
+			continue
+		}
+
+		if w.ignore != nil {
+			if isDir && entry.Name() == ".git" {
+				continue
+			}
+			if w.ignore.ShouldExclude(relPath, isDir) {
+				w.trace("exclude %s: .gitignore", relPath)
+				continue
+			}
+			if isDir {
+				w.ignore.loadDir(path, relPath)
+			}
+		}
+
+		if !isDir && isSpecialFile(path, entry) {
+			w.trace("skip %s: special file (%s)", relPath, entry.Type())
+			w.mu.Lock()
+			w.skipped = append(w.skipped, models.PathIssue{Path: relPath, Reason: SkipReasonSpecialFile})
+			w.mu.Unlock()
+			continue
+		}
+
+		if !isDir {
+			w.mu.Lock()
+			w.files[relPath] = path
+			w.mu.Unlock()
+			if w.found != nil {
+				w.found.Add(1)
+			}
+			w.trace("normalize work %q -> %q", path, relPath)
+			continue
+		}
+
+		w.mu.Lock()
+		w.dirs[relPath] = true
+		w.mu.Unlock()
+
+		// 已到达限制的层数，不再进入子目录
+		if w.maxDepth > 0 && pathDepth(relPath)-w.base >= w.maxDepth {
+			continue
+		}
+
+		select {
+		case w.sem <- struct{}{}:
+			w.wg.Add(1)
+			go func(absDir, relDir string) {
+				defer w.wg.Done()
+				defer func() { <-w.sem }()
+				w.walk(absDir, relDir)
+			}(path, relPath)
+		default:
+			w.walk(path, relPath)
+		}
+	}
+}
+
+// isSpecialFile 判断条目是否是特殊文件；符号链接按其指向的目标判断
+func isSpecialFile(path string, entry fs.DirEntry) bool {
+	mode := entry.Type()
+	if mode&fs.ModeSymlink != 0 {
+		info, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+		mode = info.Mode().Type()
+	}
+	return mode&specialFileMode != 0
+}
+
+// fail 记录遍历中遇到的第一个错误
+func (w *dirWalker) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+}
@@ -5,10 +5,19 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 const configFileName = "config.json"
-const configDirName = ".discrepancies"
+const configDirName = ".discrepancies" // 旧版本的配置目录（位于用户主目录下）
+const logFileName = "discrepancies.log"
+const checkpointFileName = "compare.checkpoint.json"
+const exportJournalFileName = "export.journal.json"
+const instanceSocketName = "instance.sock"
+const signingKeyName = "signing.key"
+const sharedRulesFileName = "shared-rules.json"
+const updatesDirName = "updates"
+const tempDirName = "tmp"
 
 // 默认排除规则
 var defaultExcludeRules = []models.ExcludeRule{
@@ -18,6 +27,7 @@ var defaultExcludeRules = []models.ExcludeRule{
 	{Pattern: ".idea", Type: "glob", IsDir: true, Enabled: true, Comment: "JetBrains IDE 配置"},
 	{Pattern: ".vs", Type: "glob", IsDir: true, Enabled: true, Comment: "Visual Studio 配置"},
 	{Pattern: ".vscode", Type: "glob", IsDir: true, Enabled: true, Comment: "VS Code 配置"},
+	{Pattern: ".svn", Type: "glob", IsDir: true, Enabled: true, Comment: "SVN 工作副本元数据"},
 	{Pattern: "node_modules", Type: "glob", IsDir: true, Enabled: true, Comment: "Node.js 依赖"},
 	{Pattern: "My Project", Type: "glob", IsDir: true, Enabled: true, Comment: "VB.NET 项目文件夹"},
 	{Pattern: "Service References", Type: "glob", IsDir: true, Enabled: true, Comment: "服务引用"},
@@ -31,28 +41,114 @@ var defaultExcludeRules = []models.ExcludeRule{
 	{Pattern: "*.user", Type: "glob", IsDir: false, Enabled: true, Comment: "用户配置文件"},
 	{Pattern: ".DS_Store", Type: "glob", IsDir: false, Enabled: true, Comment: "macOS 系统文件"},
 	{Pattern: "Thumbs.db", Type: "glob", IsDir: false, Enabled: true, Comment: "Windows 缩略图"},
+	// 生成文件规则：源文件同样有差异时折叠生成文件
+	{Pattern: "*.Designer.vb", Type: "generated", IsDir: false, Enabled: true, Source: "*.vb,*.resx,*.settings", Comment: "WinForms 设计器、资源和设置生成的代码"},
+	{Pattern: "*.Designer.cs", Type: "generated", IsDir: false, Enabled: true, Source: "*.cs,*.resx,*.settings", Comment: "WinForms 设计器、资源和设置生成的代码"},
+	{Pattern: "*.g.cs", Type: "generated", IsDir: false, Enabled: true, Source: "*.xaml", Comment: "XAML 编译生成的代码"},
+	{Pattern: "*.g.vb", Type: "generated", IsDir: false, Enabled: true, Source: "*.xaml", Comment: "XAML 编译生成的代码"},
+}
+
+// 默认关键字扫描规则
+var defaultKeywordRules = []models.KeywordRule{
+	{Name: "待办", Pattern: `\b(TODO|FIXME|HACK)\b`, Enabled: true, Comment: "未完成的代码"},
+	{Name: "密码", Pattern: `(?i)\b(password|passwd|pwd)\b["']?\s*[:=]\s*["']?[^\s"'<;]{2,}`, Enabled: true, Comment: "写在代码或配置中的密码"},
+	{Name: "连接字符串", Pattern: `(?i)\b(data source|server|host)\s*=[^;"]+;.*\b(user id|uid|initial catalog|database)\s*=`, Enabled: true, Comment: "数据库连接字符串"},
+	{Name: "私钥", Pattern: `-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`, Enabled: true, Comment: "PEM 格式的私钥"},
+	{Name: "内部主机名", Pattern: `(?i)\b[a-z0-9-]+\.(internal|corp|intra|lan)\b`, Enabled: false, Comment: "改为公司内部域名后启用"},
+}
+
+// DefaultExcludeRules 返回默认排除规则的副本
+func DefaultExcludeRules() []models.ExcludeRule {
+	rules := make([]models.ExcludeRule, len(defaultExcludeRules))
+	copy(rules, defaultExcludeRules)
+	return rules
+}
+
+// DefaultKeywordRules 返回默认关键字扫描规则的副本
+func DefaultKeywordRules() []models.KeywordRule {
+	rules := make([]models.KeywordRule, len(defaultKeywordRules))
+	copy(rules, defaultKeywordRules)
+	return rules
+}
+
+// LogFilePath 返回日志文件路径（位于缓存目录）
+func LogFilePath() (string, error) {
+	return dataFilePath(cacheDir, logFileName)
+}
+
+// CheckpointFilePath 返回比较检查点文件路径（位于缓存目录）
+func CheckpointFilePath() (string, error) {
+	return dataFilePath(cacheDir, checkpointFileName)
+}
+
+// ExportJournalFilePath 返回导出进度记录文件路径（位于缓存目录），导出中断后据此跳过已导出的文件
+func ExportJournalFilePath() (string, error) {
+	return dataFilePath(cacheDir, exportJournalFileName)
+}
+
+// InstanceSocketPath 返回单实例通信使用的套接字路径（位于缓存目录）
+func InstanceSocketPath() (string, error) {
+	return dataFilePath(cacheDir, instanceSocketName)
+}
+
+// UpdatesDir 返回暂存下载的更新安装包的目录（位于缓存目录）
+func UpdatesDir() (string, error) {
+	return dataFilePath(cacheDir, updatesDirName)
+}
+
+// TempDir 返回存放预览、导出等操作的临时文件的目录（位于缓存目录）
+func TempDir() (string, error) {
+	return dataFilePath(cacheDir, tempDirName)
+}
+
+// SigningKeyPath 返回导出签名使用的 Ed25519 私钥路径，公钥为同名 .pub 文件
+func SigningKeyPath() (string, error) {
+	return dataFilePath(configDir, signingKeyName)
+}
+
+// dataFilePath 返回配置目录或缓存目录下的文件路径，目录不存在时创建（只读模式下不创建）
+func dataFilePath(dirFunc func() (string, error), name string) (string, error) {
+	dir, err := dirFunc()
+	if err != nil {
+		return "", err
+	}
+
+	if ReadOnly() {
+		return filepath.Join(dir, name), nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
 }
 
 // Manager 配置管理器
 type Manager struct {
-	configPath string
-	config     *models.Config
+	configPath   string
+	config       *models.Config
+	legacySecret string // 旧版本配置文件中明文保存的 S3 私有访问密钥，待移到 s3-secret 文件
+
+	mu sync.RWMutex // 保护 config 指针：Set 整体替换配置时，后台刷新共享规则的协程可能正在读取
+
+	sharedMu sync.Mutex           // 保护 shared，共享规则在后台刷新
+	shared   models.SharedRuleSet // 团队共享规则，地址与配置不同时视为未获取
 }
 
 // NewManager 创建新的配置管理器
 func NewManager() (*Manager, error) {
-	homeDir, err := os.UserHomeDir()
+	dir, err := configDir()
 	if err != nil {
 		return nil, err
 	}
 
-	configDir := filepath.Join(homeDir, configDirName)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return nil, err
+	if !ReadOnly() {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
 	}
 
 	m := &Manager{
-		configPath: filepath.Join(configDir, configFileName),
+		configPath: filepath.Join(dir, configFileName),
 		config:     &models.Config{},
 	}
 
@@ -65,12 +161,26 @@ func NewManager() (*Manager, error) {
 		m.Save()
 	}
 
+	// 配置文件中开启了只读模式时，之后不再写入任何文件
+	if m.config.ReadOnly {
+		SetReadOnly(true)
+	}
+	m.migrateS3Secret(m.legacySecret)
+
 	// 如果排除规则为空，使用默认规则
 	if len(m.config.ExcludeRules) == 0 {
 		m.config.ExcludeRules = defaultExcludeRules
 		m.Save()
 	}
 
+	// 旧版本的配置没有关键字规则时写入默认规则，清空为 [] 表示不扫描
+	if m.config.KeywordRules == nil {
+		m.config.KeywordRules = DefaultKeywordRules()
+		m.Save()
+	}
+
+	m.loadSharedCache()
+
 	return m, nil
 }
 
@@ -84,12 +194,20 @@ func (m *Manager) Load() error {
 		return err
 	}
 
-	return json.Unmarshal(data, m.config)
+	if err := json.Unmarshal(data, m.config); err != nil {
+		return err
+	}
+	migrateLastPaths(data, m.config)
+	m.legacySecret = loadS3Secret(data, m.config)
+	return nil
 }
 
-// Save 保存配置
+// Save 保存配置，只读模式下返回 ErrReadOnly（修改只在本次运行中生效）
 func (m *Manager) Save() error {
-	data, err := json.MarshalIndent(m.config, "", "  ")
+	if ReadOnly() {
+		return ErrReadOnly
+	}
+	data, err := json.MarshalIndent(m.current(), "", "  ")
 	if err != nil {
 		return err
 	}
@@ -99,43 +217,34 @@ func (m *Manager) Save() error {
 
 // Get 获取当前配置
 func (m *Manager) Get() models.Config {
-	if m.config == nil {
+	cfg := m.current()
+	if cfg == nil {
 		return models.Config{}
 	}
-	return *m.config
+	return *cfg
 }
 
-// Set 设置配置
+// Set 设置配置；前端的配置中没有 S3 私有访问密钥，保留已读取的密钥
 func (m *Manager) Set(cfg models.Config) error {
+	m.mu.Lock()
+	cfg.Remote.S3SecretKey = m.config.Remote.S3SecretKey
 	m.config = &cfg
+	m.mu.Unlock()
 	return m.Save()
 }
 
-// SetLastZipPath 设置上次选择的 ZIP 文件路径
-func (m *Manager) SetLastZipPath(path string) error {
-	m.config.LastZipPath = path
-	return m.Save()
-}
-
-// SetLastWorkDir 设置上次选择的工作目录
-func (m *Manager) SetLastWorkDir(path string) error {
-	m.config.LastWorkDir = path
-	return m.Save()
-}
-
-// SetLastOutputDir 设置上次选择的输出目录
-func (m *Manager) SetLastOutputDir(path string) error {
-	m.config.LastOutputDir = path
-	return m.Save()
+// current 在锁内取得当前配置的指针，Set 之后读到的是新配置，之前取得的旧配置不再被修改
+func (m *Manager) current() *models.Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config
 }
 
 // GetDefaultOutputDir 获取默认输出目录
 func (m *Manager) GetDefaultOutputDir() string {
-	// 如果有上次保存的输出目录且目录存在，使用它
-	if m.config.LastOutputDir != "" {
-		if _, err := os.Stat(m.config.LastOutputDir); err == nil {
-			return m.config.LastOutputDir
-		}
+	// 如果有最近使用且仍然存在的输出目录，使用它
+	if recent := existingPaths(m.config.RecentOutputDirs); len(recent) > 0 {
+		return recent[0]
 	}
 	// 默认使用用户文档目录（确保目录存在）
 	homeDir, _ := os.UserHomeDir()
@@ -147,12 +256,35 @@ func (m *Manager) GetDefaultOutputDir() string {
 	return homeDir
 }
 
+// GetSetting 获取通用设置项，不存在时 ok 为 false
+func (m *Manager) GetSetting(key string) (any, bool) {
+	if m.config == nil {
+		return nil, false
+	}
+	value, ok := m.config.Settings[key]
+	return value, ok
+}
+
+// SetSetting 设置通用设置项并保存，value 为 nil 时删除该项
+func (m *Manager) SetSetting(key string, value any) error {
+	if value == nil {
+		delete(m.config.Settings, key)
+		return m.Save()
+	}
+	if m.config.Settings == nil {
+		m.config.Settings = make(map[string]any)
+	}
+	m.config.Settings[key] = value
+	return m.Save()
+}
+
 // GetExcludeRules 获取排除规则
 func (m *Manager) GetExcludeRules() []models.ExcludeRule {
-	if m.config == nil || len(m.config.ExcludeRules) == 0 {
+	cfg := m.current()
+	if cfg == nil || len(cfg.ExcludeRules) == 0 {
 		return defaultExcludeRules
 	}
-	return m.config.ExcludeRules
+	return cfg.ExcludeRules
 }
 
 // SetExcludeRules 设置排除规则
@@ -162,8 +294,8 @@ func (m *Manager) SetExcludeRules(rules []models.ExcludeRule) error {
 }
 
 // AddExcludeRule 添加排除规则
-func (m *Manager) AddExcludeRule(rule models.ExcludeRule) error {
-	m.config.ExcludeRules = append(m.config.ExcludeRules, rule)
+func (m *Manager) AddExcludeRule(rules ...models.ExcludeRule) error {
+	m.config.ExcludeRules = append(m.config.ExcludeRules, rules...)
 	return m.Save()
 }
 
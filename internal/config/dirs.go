@@ -0,0 +1,155 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// appDirName 系统配置目录和缓存目录下的应用目录名
+const appDirName = "Discrepancies"
+
+// configFiles 属于配置的文件，从旧目录迁移时移到配置目录，其他文件（日志、检查点）移到缓存目录
+var configFiles = []string{configFileName, signingKeyName, signingKeyName + ".pub"}
+
+// migrateOnce 每次运行只尝试一次从旧目录迁移
+var migrateOnce sync.Once
+
+// configDir 返回配置目录：便携模式下为可执行文件所在目录，否则为系统的用户配置目录
+// （Linux 为 $XDG_CONFIG_HOME 或 ~/.config，Windows 为 %AppData%，macOS 为 ~/Library/Application Support）
+// 旧版本的 ~/.discrepancies 在首次使用时迁移，只读模式下不迁移，继续读取旧目录
+func configDir() (string, error) {
+	return resolveConfigDir(!ReadOnly())
+}
+
+// resolveConfigDir 返回配置目录，migrate 为 false 时不迁移旧目录
+func resolveConfigDir(migrate bool) (string, error) {
+	if Portable() {
+		return executableDir()
+	}
+	if migrate {
+		migrateOnce.Do(migrateLegacyDir)
+	}
+	dir, err := appDir(os.UserConfigDir)
+	if err != nil {
+		return "", err
+	}
+
+	// 未迁移或迁移失败时继续使用旧目录中的配置
+	if legacy, ok := legacyDir(); ok && !fileExists(filepath.Join(dir, configFileName)) && fileExists(filepath.Join(legacy, configFileName)) {
+		return legacy, nil
+	}
+	return dir, nil
+}
+
+// cacheDir 返回日志、检查点等缓存文件的目录：便携模式下为可执行文件所在目录，否则为系统的用户缓存目录
+// （Linux 为 $XDG_CACHE_HOME 或 ~/.cache，Windows 为 %LocalAppData%，macOS 为 ~/Library/Caches）
+func cacheDir() (string, error) {
+	if Portable() {
+		return executableDir()
+	}
+	if !ReadOnly() {
+		migrateOnce.Do(migrateLegacyDir)
+	}
+	return appDir(os.UserCacheDir)
+}
+
+// appDir 返回系统目录下的应用目录
+func appDir(base func() (string, error)) (string, error) {
+	dir, err := base()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName), nil
+}
+
+// legacyDir 返回旧版本使用的 ~/.discrepancies 目录，不存在时 ok 为 false
+func legacyDir() (string, bool) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	dir := filepath.Join(homeDir, configDirName)
+	info, err := os.Stat(dir)
+	return dir, err == nil && info.IsDir()
+}
+
+// migrateLegacyDir 将旧目录中的文件移到配置目录和缓存目录，目标已存在的文件不覆盖，全部移走后删除旧目录
+// 迁移失败时保留旧文件，不影响使用
+func migrateLegacyDir() {
+	legacy, ok := legacyDir()
+	if !ok {
+		return
+	}
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		return
+	}
+	config, err := appDir(os.UserConfigDir)
+	if err != nil {
+		return
+	}
+	cache, err := appDir(os.UserCacheDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue // 套接字等特殊文件不迁移
+		}
+		target := cache
+		if slices.Contains(configFiles, entry.Name()) {
+			target = config
+		}
+		moveFile(filepath.Join(legacy, entry.Name()), filepath.Join(target, entry.Name()))
+	}
+	os.Remove(filepath.Join(legacy, instanceSocketName))
+	os.Remove(legacy) // 只在目录为空时删除
+}
+
+// moveFile 移动文件，目标已存在时不移动；不在同一文件系统时复制后删除原文件
+func moveFile(src, dst string) error {
+	if fileExists(dst) {
+		return os.ErrExist
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
+
+// fileExists 判断文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// PortableFlag 启用便携模式的命令行参数
+const PortableFlag = "--portable"
+
+// portable 是否通过命令行开启了便携模式
+var portable atomic.Bool
+
+// SetPortable 开启或关闭便携模式，应在启动时、读取配置之前设置
+func SetPortable(enabled bool) {
+	portable.Store(enabled)
+}
+
+// Portable 判断是否处于便携模式：命令行开启，或可执行文件旁有 config.json
+// 便携模式下配置、日志、签名密钥等都保存在可执行文件所在目录，可放在 U 盘中随身携带
+func Portable() bool {
+	if portable.Load() {
+		return true
+	}
+	dir, err := executableDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, configFileName))
+	return err == nil
+}
+
+// executableDir 返回可执行文件所在目录
+func executableDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Dir(exe), nil
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectFileName 项目配置文件名，位于工作目录根目录
+const ProjectFileName = ".discrepancies.json"
+
+// projectPreset 按项目类型预设的排除规则
+type projectPreset struct {
+	name   string
+	detect func(names []string) bool
+	rules  []models.ExcludeRule
+}
+
+// projectPresets 可识别的项目类型
+var projectPresets = []projectPreset{
+	{
+		name:   "dotnet",
+		detect: hasFileWithExt(".csproj", ".vbproj", ".sln"),
+		rules: []models.ExcludeRule{
+			{Pattern: "obj", Type: "glob", IsDir: true, Enabled: true, Comment: ".NET 编译输出目录"},
+			{Pattern: "bin", Type: "glob", IsDir: true, Enabled: true, Comment: ".NET 编译输出目录"},
+			{Pattern: ".vs", Type: "glob", IsDir: true, Enabled: true, Comment: "Visual Studio 配置"},
+			{Pattern: "*.user", Type: "glob", IsDir: false, Enabled: true, Comment: "用户配置文件"},
+			{Pattern: "*.suo", Type: "glob", IsDir: false, Enabled: true, Comment: "VS 解决方案用户选项"},
+			{Pattern: "*.Designer.vb", Type: "generated", IsDir: false, Enabled: true, Source: "*.vb,*.resx,*.settings", Comment: "WinForms 设计器、资源和设置生成的代码"},
+			{Pattern: "*.Designer.cs", Type: "generated", IsDir: false, Enabled: true, Source: "*.cs,*.resx,*.settings", Comment: "WinForms 设计器、资源和设置生成的代码"},
+			{Pattern: "*.g.cs", Type: "generated", IsDir: false, Enabled: true, Source: "*.xaml", Comment: "XAML 编译生成的代码"},
+			{Pattern: "*.g.vb", Type: "generated", IsDir: false, Enabled: true, Source: "*.xaml", Comment: "XAML 编译生成的代码"},
+		},
+	},
+	{
+		name:   "node",
+		detect: hasFile("package.json"),
+		rules: []models.ExcludeRule{
+			{Pattern: "node_modules", Type: "glob", IsDir: true, Enabled: true, Comment: "Node.js 依赖"},
+			{Pattern: ".npm", Type: "glob", IsDir: true, Enabled: true, Comment: "npm 缓存"},
+			{Pattern: "*.log", Type: "glob", IsDir: false, Enabled: true, Comment: "npm / yarn 日志"},
+		},
+	},
+	{
+		name:   "go",
+		detect: hasFile("go.mod"),
+		rules: []models.ExcludeRule{
+			{Pattern: "vendor", Type: "glob", IsDir: true, Enabled: true, Comment: "Go 依赖副本"},
+			{Pattern: "*.test", Type: "glob", IsDir: false, Enabled: true, Comment: "Go 测试二进制"},
+			{Pattern: "*.out", Type: "glob", IsDir: false, Enabled: true, Comment: "Go 覆盖率输出"},
+		},
+	},
+}
+
+// commonRules 所有项目通用的排除规则
+var commonRules = []models.ExcludeRule{
+	{Pattern: ".git", Type: "glob", IsDir: true, Enabled: true, Comment: "git 仓库元数据"},
+	{Pattern: ".svn", Type: "glob", IsDir: true, Enabled: true, Comment: "SVN 工作副本元数据"},
+	{Pattern: ".idea", Type: "glob", IsDir: true, Enabled: true, Comment: "JetBrains IDE 配置"},
+	{Pattern: ".vscode", Type: "glob", IsDir: true, Enabled: true, Comment: "VS Code 配置"},
+	{Pattern: ".DS_Store", Type: "glob", IsDir: false, Enabled: true, Comment: "macOS 系统文件"},
+	{Pattern: "Thumbs.db", Type: "glob", IsDir: false, Enabled: true, Comment: "Windows 缩略图"},
+	{Pattern: ProjectFileName, Type: "glob", IsDir: false, Enabled: true, Comment: "项目配置文件"},
+}
+
+// DetectProjectTypes 根据目录根部的文件识别项目类型（dotnet、node、go）
+func DetectProjectTypes(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	types := make([]string, 0)
+	for _, preset := range projectPresets {
+		if preset.detect(names) {
+			types = append(types, preset.name)
+		}
+	}
+	return types, nil
+}
+
+// NewProjectConfig 创建包含通用规则和指定项目类型预设规则的项目配置
+func NewProjectConfig(types []string) models.ProjectConfig {
+	rules := make([]models.ExcludeRule, 0, len(commonRules))
+	rules = append(rules, commonRules...)
+	for _, t := range types {
+		for _, preset := range projectPresets {
+			if preset.name == t {
+				rules = append(rules, preset.rules...)
+			}
+		}
+	}
+	return models.ProjectConfig{ProjectTypes: types, ExcludeRules: dedupeRules(rules), KeywordRules: DefaultKeywordRules()}
+}
+
+// LoadProjectConfig 读取目录下的项目配置文件，文件不存在时返回 false
+func LoadProjectConfig(dir string) (models.ProjectConfig, bool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ProjectFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return models.ProjectConfig{}, false, nil
+		}
+		return models.ProjectConfig{}, false, err
+	}
+
+	var project models.ProjectConfig
+	if err := json.Unmarshal(data, &project); err != nil {
+		return models.ProjectConfig{}, false, fmt.Errorf("failed to parse %s: %w", ProjectFileName, err)
+	}
+	return project, true, nil
+}
+
+// WriteProjectConfig 将项目配置写入目录，返回文件路径
+func WriteProjectConfig(dir string, project models.ProjectConfig) (string, error) {
+	if ReadOnly() {
+		return "", ErrReadOnly
+	}
+	data, err := json.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, ProjectFileName)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// dedupeRules 去除模式和类型相同的重复规则，保留第一条
+func dedupeRules(rules []models.ExcludeRule) []models.ExcludeRule {
+	seen := make(map[string]bool, len(rules))
+	result := make([]models.ExcludeRule, 0, len(rules))
+	for _, rule := range rules {
+		key := fmt.Sprintf("%s|%s|%t", rule.Type, rule.Pattern, rule.IsDir)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, rule)
+	}
+	return result
+}
+
+// hasFile 返回判断文件列表中是否包含指定文件名的函数
+func hasFile(name string) func(names []string) bool {
+	return func(names []string) bool {
+		for _, n := range names {
+			if strings.EqualFold(n, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// hasFileWithExt 返回判断文件列表中是否包含指定扩展名文件的函数
+func hasFileWithExt(exts ...string) func(names []string) bool {
+	return func(names []string) bool {
+		for _, n := range names {
+			for _, ext := range exts {
+				if strings.EqualFold(filepath.Ext(n), ext) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// ReadOnlyFlag 启用只读模式的命令行参数
+const ReadOnlyFlag = "--read-only"
+
+// ErrReadOnly 只读模式下拒绝写入文件
+var ErrReadOnly = errors.New("read-only mode: writing files is disabled")
+
+// readOnly 是否处于只读模式：不保存配置，不写日志、检查点和临时文件，不创建配置目录
+var readOnly atomic.Bool
+
+// SetReadOnly 开启或关闭只读模式，应在启动时、创建配置管理器之前设置
+func SetReadOnly(enabled bool) {
+	readOnly.Store(enabled)
+}
+
+// ReadOnly 判断是否处于只读模式
+func ReadOnly() bool {
+	return readOnly.Load()
+}
+
+// ReadOnlyConfigured 判断配置文件中是否开启了只读模式，只读取配置文件，不创建配置目录
+func ReadOnlyConfigured() bool {
+	dir, err := resolveConfigDir(false)
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, configFileName))
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		ReadOnly bool `json:"readOnly"`
+	}
+	return json.Unmarshal(data, &cfg) == nil && cfg.ReadOnly
+}
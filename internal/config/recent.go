@@ -0,0 +1,102 @@
+package config
+
+import (
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// MaxRecentPaths 每个最近使用列表保留的路径数
+const MaxRecentPaths = 10
+
+// RecentPaths 返回最近使用的 ZIP 文件、工作目录和输出目录，最近的在前，已不存在的路径不返回
+func (m *Manager) RecentPaths() models.RecentPaths {
+	if m.config == nil {
+		return models.RecentPaths{ZipPaths: []string{}, WorkDirs: []string{}, OutputDirs: []string{}}
+	}
+	return models.RecentPaths{
+		ZipPaths:   existingPaths(m.config.RecentZipPaths),
+		WorkDirs:   existingPaths(m.config.RecentWorkDirs),
+		OutputDirs: existingPaths(m.config.RecentOutputDirs),
+	}
+}
+
+// AddRecentZipPath 将 ZIP 文件路径记录为最近使用
+func (m *Manager) AddRecentZipPath(path string) error {
+	m.config.RecentZipPaths = pushRecent(m.config.RecentZipPaths, path)
+	return m.Save()
+}
+
+// AddRecentWorkDir 将工作目录记录为最近使用
+func (m *Manager) AddRecentWorkDir(path string) error {
+	m.config.RecentWorkDirs = pushRecent(m.config.RecentWorkDirs, path)
+	return m.Save()
+}
+
+// AddRecentOutputDir 将输出目录记录为最近使用
+func (m *Manager) AddRecentOutputDir(path string) error {
+	m.config.RecentOutputDirs = pushRecent(m.config.RecentOutputDirs, path)
+	return m.Save()
+}
+
+// pushRecent 将路径移到列表最前，去掉重复的路径并限制长度
+func pushRecent(list []string, path string) []string {
+	if path == "" {
+		return list
+	}
+	path = filepath.Clean(path)
+	result := make([]string, 0, min(len(list)+1, MaxRecentPaths))
+	result = append(result, path)
+	for _, p := range list {
+		if len(result) >= MaxRecentPaths {
+			break
+		}
+		if !samePath(p, path) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// samePath 判断两个路径是否相同，Windows 和 macOS 上不区分大小写
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// existingPaths 返回列表中仍然存在的路径
+func existingPaths(list []string) []string {
+	result := make([]string, 0, len(list))
+	for _, p := range list {
+		if _, err := os.Stat(p); err == nil {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// migrateLastPaths 将旧版本配置中的 lastZipPath、lastWorkDir、lastOutputDir 加入最近使用列表
+func migrateLastPaths(data []byte, cfg *models.Config) {
+	var legacy struct {
+		LastZipPath   string `json:"lastZipPath"`
+		LastWorkDir   string `json:"lastWorkDir"`
+		LastOutputDir string `json:"lastOutputDir"`
+	}
+	if json.Unmarshal(data, &legacy) != nil {
+		return
+	}
+	if len(cfg.RecentZipPaths) == 0 {
+		cfg.RecentZipPaths = pushRecent(nil, legacy.LastZipPath)
+	}
+	if len(cfg.RecentWorkDirs) == 0 {
+		cfg.RecentWorkDirs = pushRecent(nil, legacy.LastWorkDir)
+	}
+	if len(cfg.RecentOutputDirs) == 0 {
+		cfg.RecentOutputDirs = pushRecent(nil, legacy.LastOutputDir)
+	}
+}
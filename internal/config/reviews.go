@@ -0,0 +1,26 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+)
+
+const reviewsDirName = "reviews"
+
+// ReviewFilePath 返回一组比较（基准和工作目录）的审阅记录文件路径（位于配置目录的 reviews 子目录）
+// 文件名取两个路径的哈希，基准为 Git 引用时传入 "git:<ref>"
+func ReviewFilePath(baseline, workDir string) (string, error) {
+	dir, err := dataFilePath(func() (string, error) {
+		base, err := configDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, reviewsDirName), nil
+	}, "")
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(baseline) + "\x00" + filepath.Clean(workDir)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"), nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+const s3SecretFileName = "s3-secret"
+
+// S3SecretPath 返回保存 S3 私有访问密钥的文件路径（位于配置目录，权限 0600）
+func S3SecretPath() (string, error) {
+	return dataFilePath(configDir, s3SecretFileName)
+}
+
+// loadS3Secret 读取 s3-secret 文件中的私有访问密钥，返回旧版本配置文件中明文保存的 remote.s3SecretKey
+// 私有访问密钥不写入配置文件，也不通过配置返回前端
+func loadS3Secret(data []byte, cfg *models.Config) string {
+	var legacy struct {
+		Remote struct {
+			S3SecretKey string `json:"s3SecretKey"`
+		} `json:"remote"`
+	}
+	json.Unmarshal(data, &legacy)
+
+	if secretPath, err := S3SecretPath(); err == nil {
+		if content, err := os.ReadFile(secretPath); err == nil {
+			cfg.Remote.S3SecretKey = strings.TrimSpace(string(content))
+		}
+	}
+	if cfg.Remote.S3SecretKey == "" {
+		cfg.Remote.S3SecretKey = legacy.Remote.S3SecretKey
+	}
+	return legacy.Remote.S3SecretKey
+}
+
+// migrateS3Secret 将旧版本配置文件中的私有访问密钥移到 s3-secret 文件，并重新保存配置文件以删除明文
+// s3-secret 已存在时以文件为准；只读模式下或写入失败时保留原配置文件，不丢失密钥
+func (m *Manager) migrateS3Secret(legacy string) {
+	if legacy == "" || ReadOnly() {
+		return
+	}
+	secretPath, err := S3SecretPath()
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(secretPath); os.IsNotExist(err) {
+		if err := os.WriteFile(secretPath, []byte(legacy+"\n"), 0600); err != nil {
+			return
+		}
+	}
+	m.Save()
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultSharedRulesRefresh 共享规则的默认刷新间隔
+const DefaultSharedRulesRefresh = time.Hour
+
+// sharedRulesTimeout 获取共享规则的超时时间
+const sharedRulesTimeout = 15 * time.Second
+
+// maxSharedRulesSize 共享规则文件的大小上限
+const maxSharedRulesSize = 4 << 20
+
+// SharedRules 返回团队共享规则集，未配置地址时 Source 为空
+func (m *Manager) SharedRules() models.SharedRuleSet {
+	source := m.current().SharedRulesURL
+	m.sharedMu.Lock()
+	defer m.sharedMu.Unlock()
+
+	if m.shared.Source != source {
+		return models.SharedRuleSet{Source: source}
+	}
+	return m.shared
+}
+
+// SharedRulesInterval 返回共享规则的刷新间隔
+func (m *Manager) SharedRulesInterval() time.Duration {
+	refresh := m.current().SharedRulesRefresh
+	if refresh <= 0 {
+		return DefaultSharedRulesRefresh
+	}
+	return time.Duration(refresh) * time.Minute
+}
+
+// RefreshSharedRules 从配置的地址获取共享规则并缓存到缓存目录，未配置地址时不做任何事
+// 获取失败时保留上次的规则（包括启动时从缓存读取的规则），返回错误
+func (m *Manager) RefreshSharedRules(ctx context.Context) error {
+	source := strings.TrimSpace(m.current().SharedRulesURL)
+	if source == "" {
+		return nil
+	}
+
+	rules, err := fetchSharedRules(ctx, source)
+
+	m.sharedMu.Lock()
+	defer m.sharedMu.Unlock()
+	if m.shared.Source != source {
+		m.shared = models.SharedRuleSet{Source: source}
+	}
+	if err != nil {
+		m.shared.Error = err.Error()
+		return err
+	}
+	m.shared = models.SharedRuleSet{Source: source, FetchedAt: time.Now().Format(time.RFC3339), Rules: rules}
+	m.saveSharedCache()
+	return nil
+}
+
+// EffectiveExcludeRules 返回比较时使用的排除规则：共享规则在前，本地规则在后
+// 规则按顺序匹配、最后命中的生效，因此本地规则可以覆盖共享规则；与本地规则相同的共享规则不重复加入
+func (m *Manager) EffectiveExcludeRules() []models.ExcludeRule {
+	local := m.GetExcludeRules()
+	shared := m.SharedRules().Rules
+	if len(shared) == 0 {
+		return local
+	}
+
+	type ruleKey struct {
+		pattern, kind string
+		isDir         bool
+	}
+	seen := make(map[ruleKey]bool, len(local))
+	for _, rule := range local {
+		seen[ruleKey{rule.Pattern, rule.Type, rule.IsDir}] = true
+	}
+	rules := make([]models.ExcludeRule, 0, len(shared)+len(local))
+	for _, rule := range shared {
+		if !seen[ruleKey{rule.Pattern, rule.Type, rule.IsDir}] {
+			rules = append(rules, rule)
+		}
+	}
+	return append(rules, local...)
+}
+
+// loadSharedCache 读取缓存的共享规则，缓存的地址与配置不同时忽略
+func (m *Manager) loadSharedCache() {
+	path, err := dataFilePath(cacheDir, sharedRulesFileName)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var cached models.SharedRuleSet
+	if json.Unmarshal(data, &cached) != nil || cached.Source != m.current().SharedRulesURL {
+		return
+	}
+
+	m.sharedMu.Lock()
+	defer m.sharedMu.Unlock()
+	m.shared = cached
+}
+
+// saveSharedCache 将共享规则写入缓存目录，只读模式下不写入；调用方持有 sharedMu
+func (m *Manager) saveSharedCache() {
+	if ReadOnly() {
+		return
+	}
+	path, err := dataFilePath(cacheDir, sharedRulesFileName)
+	if err != nil {
+		return
+	}
+	if data, err := json.MarshalIndent(m.shared, "", "  "); err == nil {
+		os.WriteFile(path, data, 0644)
+	}
+}
+
+// fetchSharedRules 从 HTTP(S) 地址或文件路径（含 UNC 路径）读取共享规则
+func fetchSharedRules(ctx context.Context, source string) ([]models.ExcludeRule, error) {
+	var data []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		ctx, cancel := context.WithTimeout(ctx, sharedRulesTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch shared rules: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch shared rules: %s", resp.Status)
+		}
+		if data, err = io.ReadAll(io.LimitReader(resp.Body, maxSharedRulesSize)); err != nil {
+			return nil, fmt.Errorf("failed to fetch shared rules: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(source); err != nil {
+			return nil, fmt.Errorf("failed to read shared rules: %w", err)
+		}
+	}
+	return parseSharedRules(data)
+}
+
+// parseSharedRules 解析共享规则：规则数组，或与项目配置相同、含 excludeRules 的对象
+func parseSharedRules(data []byte) ([]models.ExcludeRule, error) {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	var rules []models.ExcludeRule
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("failed to parse shared rules: %w", err)
+		}
+	} else {
+		var project models.ProjectConfig
+		if err := json.Unmarshal(data, &project); err != nil {
+			return nil, fmt.Errorf("failed to parse shared rules: %w", err)
+		}
+		rules = project.ExcludeRules
+	}
+	if rules == nil {
+		rules = []models.ExcludeRule{}
+	}
+	return rules, nil
+}
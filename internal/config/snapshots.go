@@ -0,0 +1,25 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+)
+
+const snapshotsDirName = "snapshots"
+
+// SnapshotFilePath 返回工作目录的快照文件路径（位于配置目录的 snapshots 子目录），文件名取工作目录路径的哈希
+func SnapshotFilePath(workDir string) (string, error) {
+	dir, err := dataFilePath(func() (string, error) {
+		base, err := configDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, snapshotsDirName), nil
+	}, "")
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(workDir)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".sha256"), nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"os"
+)
+
+const throughputFileName = "throughput.json"
+
+// maxThroughputSamples 保留的最近比较耗时记录数
+const maxThroughputSamples = 20
+
+// LoadThroughput 读取缓存目录中最近几次比较的耗时记录，没有记录或读取失败时返回 nil
+func LoadThroughput() []models.ThroughputSample {
+	path, err := dataFilePath(cacheDir, throughputFileName)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var samples []models.ThroughputSample
+	if json.Unmarshal(data, &samples) != nil {
+		return nil
+	}
+	return samples
+}
+
+// RecordThroughput 追加一次比较的耗时记录，只保留最近的 20 次；只读模式下不写入
+func RecordThroughput(sample models.ThroughputSample) error {
+	if ReadOnly() {
+		return nil
+	}
+	path, err := dataFilePath(cacheDir, throughputFileName)
+	if err != nil {
+		return err
+	}
+
+	samples := append(LoadThroughput(), sample)
+	if len(samples) > maxThroughputSamples {
+		samples = samples[len(samples)-maxThroughputSamples:]
+	}
+	data, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,78 @@
+// Package difftool 调用用户配置的外部比较工具（如 VS Code、WinMerge、Beyond Compare）查看文件差异
+package difftool
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// 命令模板中的占位符
+const (
+	PlaceholderOld   = "{old}"   // 基准一侧的文件
+	PlaceholderNew   = "{new}"   // 工作目录一侧的文件
+	PlaceholderTitle = "{title}" // 文件的相对路径，用作窗口或标签标题
+)
+
+var (
+	// ErrNotConfigured 未配置外部比较工具
+	ErrNotConfigured = errors.New("external diff tool is not configured")
+	// ErrInvalidCommand 命令模板无法解析（如引号不成对）
+	ErrInvalidCommand = errors.New("invalid external diff tool command")
+)
+
+// Command 按命令模板创建比较 oldPath 和 newPath 的命令
+// 模板按空白拆分参数，含空格的部分用双引号括起；占位符在拆分后替换，路径中的空格和引号不影响参数
+// 模板中没有 {old} 和 {new} 时，两个文件依次追加在末尾
+func Command(template, oldPath, newPath, title string) (*exec.Cmd, error) {
+	if strings.TrimSpace(template) == "" {
+		return nil, ErrNotConfigured
+	}
+	args, err := splitArgs(template)
+	if err != nil {
+		return nil, err
+	}
+
+	replacer := strings.NewReplacer(PlaceholderOld, oldPath, PlaceholderNew, newPath, PlaceholderTitle, title)
+	if !strings.Contains(template, PlaceholderOld) && !strings.Contains(template, PlaceholderNew) {
+		args = append(args, PlaceholderOld, PlaceholderNew)
+	}
+	for i := range args {
+		args[i] = replacer.Replace(args[i])
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// splitArgs 按空白拆分命令行，双引号内的空白不拆分；反斜杠原样保留，Windows 路径无需转义
+func splitArgs(command string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inQuotes, hasArg := false, false
+	for _, r := range command {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case !inQuotes && (r == ' ' || r == '\t'):
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidCommand)
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, ErrNotConfigured
+	}
+	return args, nil
+}
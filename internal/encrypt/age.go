@@ -0,0 +1,79 @@
+package encrypt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// 使用 age（https://age-encryption.org/v1）的 X25519 收件人加密，
+// 加密结果可用 age -d -i 私钥文件 解密
+
+// FileExt 加密后的文件扩展名
+const FileExt = ".age"
+
+// ErrNoIdentity 私钥与文件的任一收件人都不匹配
+var ErrNoIdentity = errors.New("no matching identity for age file")
+
+// ErrInvalidRecipient 公钥不是有效的 age1... 格式
+var ErrInvalidRecipient = errors.New("invalid age recipient")
+
+// GenerateIdentity 生成 X25519 密钥对，返回私钥（AGE-SECRET-KEY-1...）和公钥（age1...）
+func GenerateIdentity() (identity, recipient string, err error) {
+	key, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", "", err
+	}
+	return key.String(), key.Recipient().String(), nil
+}
+
+// ParseRecipient 解析 age1... 格式的公钥
+func ParseRecipient(s string) (*age.X25519Recipient, error) {
+	recipient, err := age.ParseX25519Recipient(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRecipient, s)
+	}
+	return recipient, nil
+}
+
+// ParseIdentity 解析 AGE-SECRET-KEY-1... 格式的私钥
+func ParseIdentity(s string) (*age.X25519Identity, error) {
+	identity, err := age.ParseX25519Identity(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.New("invalid age identity")
+	}
+	return identity, nil
+}
+
+// Encrypt 返回写入明文、输出 age 密文到 w 的 WriteCloser，必须 Close 才会写出最后一块
+func Encrypt(w io.Writer, recipients []string) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no age recipients")
+	}
+	parsed := make([]age.Recipient, 0, len(recipients))
+	for _, r := range recipients {
+		recipient, err := ParseRecipient(r)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, recipient)
+	}
+	return age.Encrypt(w, parsed...)
+}
+
+// Decrypt 用 identity 解密 age 密文，返回明文 Reader；读取时校验每一块，截断或篡改的密文返回错误
+func Decrypt(r io.Reader, identity string) (io.Reader, error) {
+	private, err := ParseIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := age.Decrypt(r, private)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, ErrNoIdentity
+	}
+	return plain, err
+}
@@ -0,0 +1,88 @@
+// Package ipc 实现单实例运行：后启动的进程通过本地套接字把参数转交给已运行的实例
+// Windows 10 1803 起同样支持 Unix 域套接字，因此各平台使用相同的实现
+package ipc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// dialTimeout 连接已运行实例的超时时间
+const dialTimeout = 2 * time.Second
+
+// Message 转交给已运行实例的启动参数
+type Message struct {
+	Args    []string `json:"args"`    // 命令行参数（不含程序名）
+	WorkDir string   `json:"workDir"` // 发送方的当前目录，用于解析相对路径
+}
+
+// Server 接收其他实例转交的参数
+type Server struct {
+	listener net.Listener
+	path     string
+}
+
+// Send 将参数发送给已运行的实例，没有实例在运行时返回错误
+func Send(socketPath string, msg Message) error {
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("no running instance: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	if err := json.NewEncoder(conn).Encode(msg); err != nil {
+		return fmt.Errorf("failed to send arguments: %w", err)
+	}
+	return nil
+}
+
+// Listen 开始监听其他实例转交的参数，每收到一条消息调用一次 handler
+// 上次异常退出留下的套接字文件会被删除
+func Listen(socketPath string, handler func(Message)) (*Server, error) {
+	if _, err := os.Stat(socketPath); err == nil {
+		if conn, err := net.DialTimeout("unix", socketPath, dialTimeout); err == nil {
+			conn.Close()
+			return nil, errors.New("another instance is already listening")
+		}
+		os.Remove(socketPath)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+
+	s := &Server{listener: listener, path: socketPath}
+	go s.serve(handler)
+	return s, nil
+}
+
+// serve 逐个接受连接并解析消息
+func (s *Server) serve(handler func(Message)) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		var msg Message
+		conn.SetDeadline(time.Now().Add(dialTimeout))
+		err = json.NewDecoder(conn).Decode(&msg)
+		conn.Close()
+		if err == nil {
+			handler(msg)
+		}
+	}
+}
+
+// Close 停止监听并删除套接字文件
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
@@ -2,16 +2,54 @@ package models
 
 // DiffItem 表示一个差异项
 type DiffItem struct {
-	RelPath    string `json:"relPath"`    // 相对路径
-	Type       string `json:"type"`       // "added" | "modified" | "deleted"
-	Selected   bool   `json:"selected"`   // 是否选中
-	SourcePath string `json:"sourcePath"` // 源文件完整路径（工作目录中的路径）
+	RelPath      string `json:"relPath"`      // 相对路径
+	Type         string `json:"type"`         // "added" | "modified" | "deleted" | "renamed" | "unchanged"（仅启用列出相同文件时）
+	Selected     bool   `json:"selected"`     // 是否选中
+	SourcePath   string `json:"sourcePath"`   // 源文件完整路径（工作目录中的路径）
+	OldPath      string `json:"oldPath"`      // 重命名前的相对路径（仅 renamed）
+	Unversioned  bool   `json:"unversioned"`  // 文件未纳入版本控制（svn status 为 ?）
+	Note         string `json:"note"`         // 变更说明或审阅意见（界面中填写），导出时写入变更日志，报告中列出
+	Review       string `json:"review"`       // 审阅状态：pending | approved | rejected，空表示未审阅
+	Error        string `json:"error"`        // 比较、预览或导出该文件失败的原因，为空表示没有错误
+	Unstable     bool   `json:"unstable"`     // 比较时文件正在变化（如正被构建写入），结果可能不准确
+	BOMOnly      bool   `json:"bomOnly"`      // 只差开头的 UTF-8 BOM（未开启忽略 BOM 时仍列为修改）
+	EncodingOnly bool   `json:"encodingOnly"` // 只在 UTF-16 与 UTF-8 之间转换了编码或字节顺序，解码后的文本相同（未开启忽略编码时仍列为修改）
+	CommentsOnly bool   `json:"commentsOnly"` // 只改了注释和空行（C#、VB、JavaScript/TypeScript、SQL，开启分析时）
+
+	GeneratedFrom string `json:"generatedFrom"` // 按生成文件规则配对的源文件（相对路径），仅当源文件同样有差异时设置，界面中默认折叠
+
+	Findings []KeywordFinding `json:"findings"` // 关键字扫描命中的行（启用关键字规则时，仅新增和修改的文本文件）
+
+	LinesAdded   int `json:"linesAdded"`   // 新增行数（仅修改的文本文件）
+	LinesRemoved int `json:"linesRemoved"` // 删除行数（仅修改的文本文件）
+
+	OldSize int64 `json:"oldSize"` // 基准中的大小（字节），新增的文件为 0
+	NewSize int64 `json:"newSize"` // 工作目录中的大小（字节），删除的文件为 0
+
+	Stream  string     `json:"stream"`  // NTFS 备用数据流名称（仅数据流子项）
+	Streams []DiffItem `json:"streams"` // 文件的备用数据流差异（启用数据流比较时）
+}
+
+// KeywordFinding 关键字扫描命中的一行
+type KeywordFinding struct {
+	Rule string `json:"rule"` // 命中的规则名称
+	Line int    `json:"line"` // 行号（从 1 开始）
+	Text string `json:"text"` // 行内容（过长时截断）
 }
 
 // DiffLine 表示一行差异
 type DiffLine struct {
-	Type    string `json:"type"`    // "equal" | "insert" | "delete"
-	Content string `json:"content"` // 行内容
+	Type    string     `json:"type"`    // "equal" | "insert" | "delete"
+	Content string     `json:"content"` // 行内容
+	NewLine int        `json:"newLine"` // 在新文件中的行号（从 1 开始），删除的行为 0
+	Blame   *LineBlame `json:"blame"`   // 该行最后一次修改的提交信息（启用 git blame 时）
+}
+
+// LineBlame 表示 git blame 得到的行提交信息
+type LineBlame struct {
+	Commit string `json:"commit"` // 提交哈希（短）
+	Author string `json:"author"` // 作者
+	Date   string `json:"date"`   // 提交时间
 }
 
 // TextDiff 表示文本差异结果
@@ -19,37 +57,464 @@ type TextDiff struct {
 	OldContent string     `json:"oldContent"` // 原始内容
 	NewContent string     `json:"newContent"` // 新内容
 	Lines      []DiffLine `json:"lines"`      // 差异行
+	Truncated  bool       `json:"truncated"`  // 文件超过预览大小上限，仅比较了开头部分
+}
+
+// FileType 表示文件类型检测结果
+type FileType struct {
+	MimeType string `json:"mimeType"` // MIME 类型
+	Category string `json:"category"` // "text" | "binary" | "image"
 }
 
 // CompareResult 表示比较结果
 type CompareResult struct {
-	Items      []DiffItem `json:"items"`      // 差异项列表
-	TotalFiles int        `json:"totalFiles"` // 总文件数
-	Added      int        `json:"added"`      // 新增文件数
-	Modified   int        `json:"modified"`   // 修改文件数
-	Deleted    int        `json:"deleted"`    // 删除文件数
+	ResultID    string       `json:"resultId"`    // 结果 ID，用于分页获取
+	Items       []DiffItem   `json:"items"`       // 差异项列表
+	TotalFiles  int          `json:"totalFiles"`  // 总文件数
+	Added       int          `json:"added"`       // 新增文件数
+	Modified    int          `json:"modified"`    // 修改文件数
+	Deleted     int          `json:"deleted"`     // 删除文件数
+	Renamed     int          `json:"renamed"`     // 重命名文件数（仅 git 基准）
+	Unversioned int          `json:"unversioned"` // 未纳入版本控制的文件数（启用 SVN 状态时）
+	Flagged     int          `json:"flagged"`     // 命中关键字规则的文件数
+	Generated   int          `json:"generated"`   // 源文件同样有差异的生成文件数（GeneratedFrom 不为空）
+	Failed      int          `json:"failed"`      // 无法读取、未能比较的文件数（列为修改但不选中，Error 说明原因）
+	Spilled     bool         `json:"spilled"`     // 结果超过内存上限，Items 为空，需通过分页接口获取
+	Skipped     []PathIssue  `json:"skipped"`     // 未比较的文件（如命名管道、设备等特殊文件）及原因
+	Largest     []SizeChange `json:"largest"`     // 大小增加最多的新增和修改文件，按增加量降序
+	MaxDepth    int          `json:"maxDepth"`    // 比较时限制的目录层数，0 表示未限制
+	Container   string       `json:"container"`   // 基准的容器类型（apk、ipa、vsix），普通 ZIP 为空，容器的默认排除规则已生效
+
+	TotalAddedBytes         int64 `json:"totalAddedBytes"`         // 新增文件的总大小（字节）
+	TotalModifiedBytesDelta int64 `json:"totalModifiedBytesDelta"` // 修改和重命名文件的大小变化之和（字节），可为负数
+	TotalDeletedBytes       int64 `json:"totalDeletedBytes"`       // 删除文件的总大小（字节）
+
+	Unchanged      int   `json:"unchanged"`      // 确认内容相同（含规范化后相同）的文件数
+	UnchangedBytes int64 `json:"unchangedBytes"` // 相同文件在基准中的总大小（字节）
+	Excluded       int   `json:"excluded"`       // 被排除规则或 .gitignore 排除、未比较的文件数
+}
+
+// SizeChange 一个文件的大小变化
+type SizeChange struct {
+	RelPath string `json:"relPath"` // 相对路径
+	Type    string `json:"type"`    // 差异类型：added | modified | renamed
+	OldSize int64  `json:"oldSize"` // 基准中的大小（字节）
+	NewSize int64  `json:"newSize"` // 工作目录中的大小（字节）
+	Delta   int64  `json:"delta"`   // 增加的字节数
+}
+
+// ResultFilter 分页获取结果时的筛选条件
+type ResultFilter struct {
+	Types []string `json:"types"` // 差异类型，为空表示全部
+	Query string   `json:"query"` // 路径包含的文本（不区分大小写）
+	Paths []string `json:"paths"` // 路径需匹配其中之一的 glob 模式（与排除规则语法相同），为空表示全部
+}
+
+// ResultPage 比较结果的一页
+type ResultPage struct {
+	ResultID string     `json:"resultId"` // 结果 ID
+	Total    int        `json:"total"`    // 筛选后的总数
+	Offset   int        `json:"offset"`   // 本页起始位置
+	Items    []DiffItem `json:"items"`    // 本页的差异项
 }
 
 // ExcludeRule 排除规则
 type ExcludeRule struct {
-	Pattern  string `json:"pattern"`  // 匹配模式
-	Type     string `json:"type"`     // "glob" | "regex"
-	IsDir    bool   `json:"isDir"`    // 是否仅匹配目录
-	Enabled  bool   `json:"enabled"`  // 是否启用
-	Comment  string `json:"comment"`  // 备注说明
+	Pattern string `json:"pattern"` // 匹配模式
+	Type    string `json:"type"`    // "glob" | "regex" | "generated"（生成文件规则：不排除文件，源文件同样有差异时标记为生成文件）
+	IsDir   bool   `json:"isDir"`   // 是否仅匹配目录
+	Enabled bool   `json:"enabled"` // 是否启用
+	Negate  bool   `json:"negate"`  // 是否为包含规则（重新包含被前面规则排除的路径）
+	Comment string `json:"comment"` // 备注说明
+	Source  string `json:"source"`  // 生成文件规则对应的源文件，* 代表匹配生成文件时 * 对应的部分，同一目录中，逗号分隔多个，如 *.vb,*.resx
+
+	FromIgnore bool `json:"fromIgnore"` // 是否来自 .gitignore 等忽略文件：与 gitignore 一致，未限定目录的规则同样匹配上级目录
+}
+
+// ContentIgnoreRule 内容忽略规则：比较和预览前屏蔽文件中匹配正则的内容
+type ContentIgnoreRule struct {
+	Pattern    string   `json:"pattern"`    // 正则表达式（多行模式，^ $ 匹配行首行尾），含捕获组时只屏蔽捕获组
+	Extensions []string `json:"extensions"` // 适用的扩展名，如 .cs、.vb，为空时适用于所有文本文件
+	Enabled    bool     `json:"enabled"`    // 是否启用
+	Comment    string   `json:"comment"`    // 备注说明
+}
+
+// NormalizeRule 规范化规则：比较和预览前对匹配路径的文件内容依次执行的步骤
+type NormalizeRule struct {
+	Pattern string   `json:"pattern"` // 路径通配符，如 *.resx、src/**/*.config，为空时适用于所有文本文件
+	Steps   []string `json:"steps"`   // 步骤：eol、bom、trailing-space、sort-keys、mask:正则
+	Enabled bool     `json:"enabled"` // 是否启用
+	Comment string   `json:"comment"` // 备注说明
+}
+
+// KeywordRule 关键字扫描规则：比较时在新增和修改的文件中查找匹配的行，避免密码等内容随补丁包发布
+type KeywordRule struct {
+	Name       string   `json:"name"`       // 规则名称，显示在扫描结果中，为空时显示正则表达式
+	Pattern    string   `json:"pattern"`    // 正则表达式，逐行匹配，(?i) 开头表示不区分大小写
+	Extensions []string `json:"extensions"` // 适用的扩展名，如 .config、.cs，为空时适用于所有文本文件
+	Enabled    bool     `json:"enabled"`    // 是否启用
+	Comment    string   `json:"comment"`    // 备注说明
 }
 
 // Config 应用配置
 type Config struct {
-	LastZipPath   string        `json:"lastZipPath"`   // 上次选择的 ZIP 文件路径
-	LastWorkDir   string        `json:"lastWorkDir"`   // 上次选择的工作目录
-	LastOutputDir string        `json:"lastOutputDir"` // 上次选择的输出目录
-	ExcludeRules  []ExcludeRule `json:"excludeRules"`  // 排除规则列表
+	RecentZipPaths   []string      `json:"recentZipPaths"`   // 最近使用的 ZIP 文件，最近的在前，最多 10 个
+	RecentWorkDirs   []string      `json:"recentWorkDirs"`   // 最近使用的工作目录
+	RecentOutputDirs []string      `json:"recentOutputDirs"` // 最近使用的输出目录
+	ExcludeRules     []ExcludeRule `json:"excludeRules"`     // 排除规则列表
+
+	SharedRulesURL     string `json:"sharedRulesUrl"`     // 团队共享排除规则集的地址（HTTP(S) URL、UNC 或本地路径），为空时不使用；共享规则排在本地规则之前，本地规则优先
+	SharedRulesRefresh int    `json:"sharedRulesRefresh"` // 共享规则的刷新间隔（分钟），0 表示默认 60
+
+	UpdateFeedURL  string `json:"updateFeedUrl"`  // 发布源地址（描述最新版本的 JSON），为空时不检查更新
+	UpdateDownload bool   `json:"updateDownload"` // 发现新版本时下载当前平台的安装包并校验 SHA-256，下载后提示安装
+
+	ContentIgnoreRules []ContentIgnoreRule `json:"contentIgnoreRules"` // 内容忽略规则，只在屏蔽的内容上不同的文件视为未修改
+	NormalizeRules     []NormalizeRule     `json:"normalizeRules"`     // 规范化规则，规范化后相同的文件视为未修改
+	KeywordRules       []KeywordRule       `json:"keywordRules"`       // 关键字扫描规则，命中的行显示在差异列表中
+	AllowedEncodings   []string            `json:"allowedEncodings"`   // 格式检查时允许的文本编码，如 utf-8、utf-8-bom，为空时不检查；ASCII 总是允许
+
+	LargestChanges     int  `json:"largestChanges"`     // 比较结果和摘要中列出的大小增加最多的文件数，0 表示默认 10，负数表示不列出
+	ReadRetries        int  `json:"readRetries"`        // 读取工作目录文件失败（如正被构建写入）时的重试次数，0 表示默认 2，负数表示不重试
+	ReadRetryDelay     int  `json:"readRetryDelay"`     // 首次重试前的等待时间（毫秒），之后每次加倍，0 表示默认 200
+	DetectCommentsOnly bool `json:"detectCommentsOnly"` // 分析修改的 C#、VB、JavaScript/TypeScript、SQL 文件是否只改了注释，这类文件标为「注释」，可批量取消选中
+	IgnoreBOM          bool `json:"ignoreBom"`          // 只差 UTF-8 BOM 的文件视为未修改，差异预览中也去掉 BOM；关闭时这类文件列为修改并标记「BOM」
+	IgnoreEncoding     bool `json:"ignoreEncoding"`     // 只在 UTF-16（LE、BE）与 UTF-8 之间转换了编码、解码后文本相同的文件视为未修改，差异预览中按解码后的文本比较；关闭时这类文件列为修改并标记「编码」
+	ListUnchanged      bool `json:"listUnchanged"`      // 在差异列表中同时列出内容相同的文件（标为「相同」，不选中），用于审计时确认覆盖范围
+	MaxDepth           int  `json:"maxDepth"`           // 比较时只扫描前几层目录（设置了子目录时从子目录开始计算），用于快速检查目录结构，0 表示不限制
+	ReadOnly           bool `json:"readOnly"`           // 只读模式：不保存配置，不写日志、检查点和临时文件，禁止导出等写入操作；开启后需手动修改配置文件关闭
+
+	RespectGitignore bool   `json:"respectGitignore"` // 比较时是否遵循工作目录中各层的 .gitignore
+	MaxPreviewSize   int64  `json:"maxPreviewSize"`   // 文本预览的大小上限（字节），0 表示使用默认值
+	ZipNameEncoding  string `json:"zipNameEncoding"`  // ZIP 文件名编码：auto | utf-8 | shift-jis | gbk | cp437
+	EnableGitBlame   bool   `json:"enableGitBlame"`   // 工作目录是 git 仓库时，在差异预览中标注修改行的提交信息
+	ExternalDiffTool string `json:"externalDiffTool"` // 外部比较工具的命令，如 code --diff {old} {new}，{old}、{new} 为基准和工作目录一侧的文件，{title} 为相对路径
+
+	GitCommitOnExport bool   `json:"gitCommitOnExport"` // 导出后自动在工作目录的 git 仓库中提交导出的文件
+	GitCommitMessage  string `json:"gitCommitMessage"`  // 提交信息模板，支持 {baseline}、{count}
+	EnableSvnStatus   bool   `json:"enableSvnStatus"`   // 工作目录是 SVN 工作副本时，通过 svn status 标记未纳入版本控制的文件
+
+	LogLevel   string `json:"logLevel"`   // 日志级别：trace/debug/info/warning/error，为空时为 info
+	DebugTrace bool   `json:"debugTrace"` // 调试跟踪：将每个排除判断、哈希计算和路径规范化写入日志文件
+
+	ResultMemoryLimit int64 `json:"resultMemoryLimit"` // 比较结果的内存上限（字节），超过时写入临时文件，0 表示默认 256MB
+	CompareStreams    bool  `json:"compareStreams"`    // 比较 NTFS 备用数据流（仅 Windows），差异作为文件的子项列出
+	DeltaPatchMinSize int64 `json:"deltaPatchMinSize"` // 生成差分包时，不小于该大小（字节）的修改文件保存为二进制补丁，0 表示不使用补丁
+
+	ExportChecksums bool `json:"exportChecksums"` // 导出时在输出目录或 ZIP 中写入 SHA256SUMS
+	ExportMD5Sums   bool `json:"exportMd5Sums"`   // 导出时同时写入 MD5SUMS，供只支持 MD5 的旧工具使用
+	SignExports     bool `json:"signExports"`     // 导出时用配置目录下的 signing.key 生成分离签名（.sig）
+	VerifyExports   bool `json:"verifyExports"`   // 导出后重新读取写入的文件校验内容，发现网络共享等静默损坏的数据
+
+	ExportEOL string `json:"exportEol"` // 导出时将文本文件的换行符统一为 lf 或 crlf（如部署环境要求 CRLF），为空时原样复制
+
+	TrustedSigningKeys []string `json:"trustedSigningKeys"` // 校验包签名时信任的公钥（PEM），本机的签名公钥总是受信任
+	EncryptRecipients  []string `json:"encryptRecipients"`  // 导出为 ZIP 时用这些 age 公钥（age1...）加密整个包，保存为 .zip.age
+
+	ZipNameTemplate   string `json:"zipNameTemplate"`   // ZIP 文件名模板，支持 {base}、{label}、{ticket}、{date}、{time}、{env:变量名} 或 text/template 写法，为空时为 {base}_{label}_{date}
+	ExportDirTemplate string `json:"exportDirTemplate"` // 输出目录下的子目录模板（可用 / 分隔多级），占位符同上，为空时直接导出到输出目录
+	ExportTicket      string `json:"exportTicket"`      // 构建号或工单号，模板中的 {ticket}；比较时关联了工单时使用关联的工单号
+	NameDateFormat    string `json:"nameDateFormat"`    // 文件名中的日期格式：chinese（2006年01月02日）| iso（2006-01-02）| compact（20060102）| Go 时间格式，为空时为 chinese
+	NameLabel         string `json:"nameLabel"`         // 文件名中缀 {label}，为空时为「差分」，如 delta、diff
+
+	ReportNameTemplate   string `json:"reportNameTemplate"`   // 打印报告的文件名模板（不含扩展名），占位符同 zipNameTemplate，为空时为 {base}_变更记录_{date:compact}
+	ReportTitleTemplate  string `json:"reportTitleTemplate"`  // 打印报告的标题模板，支持 {baseline}、{date}，为空时为「文件变更记录」
+	SummaryTitleTemplate string `json:"summaryTitleTemplate"` // 复制的差异摘要的标题模板，支持 {baseline}、{date}，为空时为「差异摘要」
+
+	ExportChangelog bool `json:"exportChangelog"` // 导出时在输出目录或 ZIP 根目录附带 CHANGELOG.md，列出选中的文件和填写的变更说明
+	ArchiveBaseline bool `json:"archiveBaseline"` // 导出时将基准 ZIP 和比较结果（compare-result.json）保存到导出目录（ZIP 导出时保存在 ZIP 旁边），作为导出包的比较依据
+	ArchiveHardLink bool `json:"archiveHardLink"` // 保存基准 ZIP 时优先创建硬链接，不占用额外空间，不在同一卷等无法链接时复制
+
+	IssueTracker IssueTrackerConfig `json:"issueTracker"` // 问题跟踪系统，用于生成工单链接和查询工单标题
+	Remote       RemoteConfig       `json:"remote"`       // 导出到 sftp://、s3:// 目标时的连接和凭据
+
+	Settings map[string]any `json:"settings"` // 界面偏好等通用设置（主题、语言、窗口布局、默认比较选项等），键由前端定义，通过 GetSetting/SetSetting 读写
+}
+
+// IssueTrackerConfig 问题跟踪系统配置
+type IssueTrackerConfig struct {
+	Type          string `json:"type"`          // jira | redmine，为空时不生成链接
+	BaseURL       string `json:"baseUrl"`       // 站点地址，如 https://example.atlassian.net
+	User          string `json:"user"`          // Jira Cloud 的登录邮箱（与 API token 配合使用），Jira Server 和 Redmine 留空
+	Token         string `json:"token"`         // API token（Jira）或 API key（Redmine）
+	ResolveTitles bool   `json:"resolveTitles"` // 关联工单时通过 REST API 查询工单标题
+}
+
+// RemoteConfig 远程导出目标的连接配置，留空的凭据从 SSH agent、~/.ssh 和 AWS_* 环境变量读取
+type RemoteConfig struct {
+	SSHKeyFile     string `json:"sshKeyFile"`     // SFTP 登录使用的私钥（无密码保护），为空时使用 SSH agent 和 ~/.ssh 下的 id_ed25519、id_ecdsa、id_rsa
+	KnownHostsFile string `json:"knownHostsFile"` // 校验 SFTP 服务器主机密钥的 known_hosts，为空时为 ~/.ssh/known_hosts
+	S3Endpoint     string `json:"s3Endpoint"`     // S3 兼容服务的地址（如 https://minio.example.com），为空时为 AWS S3
+	S3Region       string `json:"s3Region"`       // 区域，为空时使用环境变量 AWS_REGION，都没有时为 us-east-1
+	S3AccessKey    string `json:"s3AccessKey"`    // 访问密钥 ID，为空时使用环境变量 AWS_ACCESS_KEY_ID
+	S3SecretKey    string `json:"-"`              // 私有访问密钥，保存在配置目录的 s3-secret 文件中（不写入配置文件、不返回前端），为空时使用环境变量 AWS_SECRET_ACCESS_KEY
+}
+
+// TicketRef 比较关联的工单
+type TicketRef struct {
+	ID    string `json:"id"`    // 工单号，如 PROJ-123 或 4567
+	Title string `json:"title"` // 工单标题（查询失败或未开启查询时为空）
+	URL   string `json:"url"`   // 工单链接（未配置问题跟踪系统时为空）
+}
+
+// ProjectConfig 项目配置（工作目录下的 .discrepancies.json），供命令行使用
+type ProjectConfig struct {
+	ProjectTypes     []string      `json:"projectTypes"`     // 识别到的项目类型：dotnet、node、go
+	ExcludeRules     []ExcludeRule `json:"excludeRules"`     // 排除规则
+	RespectGitignore bool          `json:"respectGitignore"` // 是否遵循工作目录中各层的 .gitignore
+
+	ContentIgnoreRules []ContentIgnoreRule `json:"contentIgnoreRules"` // 内容忽略规则
+	NormalizeRules     []NormalizeRule     `json:"normalizeRules"`     // 规范化规则
+	KeywordRules       []KeywordRule       `json:"keywordRules"`       // 关键字扫描规则
+	AllowedEncodings   []string            `json:"allowedEncodings"`   // 允许的文本编码
+	IgnoreBOM          bool                `json:"ignoreBom"`          // 只差 UTF-8 BOM 的文件视为未修改
+	IgnoreEncoding     bool                `json:"ignoreEncoding"`     // 只在 UTF-16 与 UTF-8 之间转换了编码的文件视为未修改
+	DetectCommentsOnly bool                `json:"detectCommentsOnly"` // 标记只改了注释的代码文件
+}
+
+// LockedFile 被其他进程占用的文件
+type LockedFile struct {
+	Path      string   `json:"path"`      // 文件路径
+	Processes []string `json:"processes"` // 占用文件的进程（仅 Windows 可获取）
+}
+
+// PathIssue 路径预检发现的问题
+type PathIssue struct {
+	Path   string `json:"path"`   // 文件或目录路径
+	Reason string `json:"reason"` // 问题说明
+}
+
+// CompareStats 比较过程的统计信息
+type CompareStats struct {
+	FilesScanned int   `json:"filesScanned"` // 检查的文件数
+	BytesHashed  int64 `json:"bytesHashed"`  // 计算哈希读取的字节数
+	FastMode     bool  `json:"fastMode"`     // 是否使用了快速比较
+	CacheUsed    bool  `json:"cacheUsed"`    // 是否使用了缓存
+	ResumedFiles int   `json:"resumedFiles"` // 从检查点恢复、未重新比较的文件数
+
+	NormalizedEqual int `json:"normalizedEqual"` // 哈希不同但规范化（内容忽略、换行符、BOM 等）后相同、视为未修改的文件数
+	ReadRetries     int `json:"readRetries"`     // 读取工作目录文件失败后重试的次数
+	BOMOnly         int `json:"bomOnly"`         // 只差 UTF-8 BOM、按忽略 BOM 设置视为未修改的文件数
+	EncodingOnly    int `json:"encodingOnly"`    // 只转换了 UTF-16 与 UTF-8 编码、按忽略编码设置视为未修改的文件数
+	SizeDiffers     int `json:"sizeDiffers"`     // 大小不同、未读取内容即判定为修改的文件数
+}
+
+// CompareCompleteEvent 比较完成事件，携带本次运行的元数据
+type CompareCompleteEvent struct {
+	Engine         string       `json:"engine"`         // "zip" | "git"
+	Baseline       string       `json:"baseline"`       // 基准名称
+	WorkDir        string       `json:"workDir"`        // 工作目录
+	StartedAt      string       `json:"startedAt"`      // 开始时间（RFC 3339）
+	DurationMs     int64        `json:"durationMs"`     // 耗时（毫秒）
+	FilesPerSecond float64      `json:"filesPerSecond"` // 每秒检查的文件数
+	BytesPerSecond float64      `json:"bytesPerSecond"` // 每秒计算哈希的字节数
+	Total          int          `json:"total"`          // 差异总数
+	Added          int          `json:"added"`          // 新增文件数
+	Modified       int          `json:"modified"`       // 修改文件数
+	Deleted        int          `json:"deleted"`        // 删除文件数
+	Renamed        int          `json:"renamed"`        // 重命名文件数
+	Stats          CompareStats `json:"stats"`          // 统计信息
+}
+
+// DeltaApplyReport 应用差分包的结果
+type DeltaApplyReport struct {
+	Pending   int         `json:"pending"`   // 校验后需要应用的文件数
+	Added     int         `json:"added"`     // 新增的文件数
+	Replaced  int         `json:"replaced"`  // 以完整内容替换的文件数
+	Patched   int         `json:"patched"`   // 应用二进制补丁的文件数
+	Deleted   int         `json:"deleted"`   // 删除的文件数
+	Skipped   int         `json:"skipped"`   // 目标已是新版本而跳过的文件数
+	Verified  int         `json:"verified"`  // 应用后 MD5 与新版本一致的文件数
+	Conflicts []PathIssue `json:"conflicts"` // 与差分包旧版本不一致、无法应用的文件
+	DryRun    bool        `json:"dryRun"`    // 仅校验，未修改目标目录
+
+	Locked  []LockedFile `json:"locked"`  // 需要修改但被其他进程占用的文件，存在时不做任何修改
+	Applied []string     `json:"applied"` // 已修改（替换、新增、删除）的文件，应用中途失败时据此确认目标目录的状态
+}
+
+// 包签名的校验结果
+const (
+	SignatureValid   = "valid"   // 签名有效且来自受信任的公钥
+	SignatureInvalid = "invalid" // 签名与内容不符或公钥不受信任
+	SignatureMissing = "missing" // 没有分离签名文件
+)
+
+// PackageVerifyReport 校验导出包的结果
+type PackageVerifyReport struct {
+	HasManifest bool     `json:"hasManifest"` // 包内是否有 SHA256SUMS
+	Files       int      `json:"files"`       // 清单中的文件数
+	Verified    int      `json:"verified"`    // 哈希一致的文件数
+	Tampered    []string `json:"tampered"`    // 哈希与清单不一致的文件
+	Missing     []string `json:"missing"`     // 清单中有、包内缺失的文件
+	Unlisted    []string `json:"unlisted"`    // 包内有、清单中没有的文件
+	Signature   string   `json:"signature"`   // 签名校验结果：valid | invalid | missing
+	Valid       bool     `json:"valid"`       // 清单完整一致且签名有效
+}
+
+// 换行符类型
+const (
+	EOLNone  = "none"  // 没有换行符（空文件或只有一行）
+	EOLLF    = "lf"    // LF（Linux、macOS）
+	EOLCRLF  = "crlf"  // CRLF（Windows）
+	EOLCR    = "cr"    // CR（旧版 Mac OS）
+	EOLMixed = "mixed" // 混用多种换行符
+)
+
+// 文本编码
+const (
+	EncodingASCII    = "ascii"     // 只有 ASCII 字符，与 UTF-8、Shift-JIS、GBK 都兼容
+	EncodingUTF8     = "utf-8"     // UTF-8（无 BOM）
+	EncodingUTF8BOM  = "utf-8-bom" // 带 BOM 的 UTF-8
+	EncodingUTF16LE  = "utf-16le"  // 带 BOM 的 UTF-16 LE
+	EncodingUTF16BE  = "utf-16be"  // 带 BOM 的 UTF-16 BE
+	EncodingShiftJIS = "shift-jis" // Shift-JIS
+	EncodingGBK      = "gbk"       // GBK
+	EncodingUnknown  = "unknown"   // 无法识别
+)
+
+// TextFileAudit 一个差异文本文件的格式检查结果
+type TextFileAudit struct {
+	RelPath    string `json:"relPath"`    // 相对路径
+	Type       string `json:"type"`       // 差异类型
+	OldEOL     string `json:"oldEol"`     // 基准中的换行符：lf | crlf | cr | mixed | none，基准中没有该文件时为空
+	NewEOL     string `json:"newEol"`     // 工作目录中的换行符，已删除的文件为空
+	EOLChanged bool   `json:"eolChanged"` // 两侧都存在且换行符不同
+
+	OldEncoding     string `json:"oldEncoding"`     // 基准中的编码：ascii | utf-8 | utf-8-bom | utf-16le | utf-16be | shift-jis | gbk | unknown
+	NewEncoding     string `json:"newEncoding"`     // 工作目录中的编码
+	EncodingChanged bool   `json:"encodingChanged"` // 两侧都存在且编码不同（ASCII 与兼容的编码不算不同）
+	EncodingOnly    bool   `json:"encodingOnly"`    // 编码不同但解码后的文本相同，只是转换了编码
+	PolicyViolation bool   `json:"policyViolation"` // 工作目录中的编码不在允许的编码中
+}
+
+// TextAuditReport 差异文本文件的格式检查报告（跳过二进制和过大的文件）
+type TextAuditReport struct {
+	Files      []TextFileAudit `json:"files"`      // 检查的文本文件，按路径排序
+	LF         int             `json:"lf"`         // 工作目录中使用 LF 的文件数
+	CRLF       int             `json:"crlf"`       // 工作目录中使用 CRLF 的文件数
+	Mixed      int             `json:"mixed"`      // 工作目录中混用换行符的文件数
+	EOLChanged int             `json:"eolChanged"` // 换行符与基准不同的文件数
+
+	EncodingChanged int `json:"encodingChanged"` // 编码与基准不同的文件数
+	EncodingOnly    int `json:"encodingOnly"`    // 只转换了编码的文件数
+	Violations      int `json:"violations"`      // 编码不符合 allowedEncodings 的文件数
+}
+
+// APIError 返回给前端的错误
+type APIError struct {
+	Code    string `json:"code"`    // 稳定的错误码，如 ZIP_NOT_FOUND
+	Message string `json:"message"` // 用户提示
+	Detail  string `json:"detail"`  // 补充信息
+	Path    string `json:"path"`    // 出错的差异文件（相对路径），与单个文件无关时为空
+}
+
+// OpenRequest 通过命令行或文件关联打开的路径
+type OpenRequest struct {
+	ZipPath string `json:"zipPath"` // 原始 ZIP 文件路径
+	WorkDir string `json:"workDir"` // 工作目录
+	Compare bool   `json:"compare"` // 打开后立即比较（discrepancies:// 链接中指定了 run=1）
+}
+
+// SharedRuleSet 从团队共享地址获取的排除规则集
+type SharedRuleSet struct {
+	Source    string        `json:"source"`    // 规则集地址
+	FetchedAt string        `json:"fetchedAt"` // 最近一次成功获取的时间（RFC 3339），为空表示尚未获取
+	Rules     []ExcludeRule `json:"rules"`     // 规则列表
+	Error     string        `json:"error"`     // 最近一次获取失败的原因，此时使用缓存的规则
+}
+
+// RecentPaths 最近使用的路径，最近的在前
+type RecentPaths struct {
+	ZipPaths   []string `json:"zipPaths"`   // ZIP 文件
+	WorkDirs   []string `json:"workDirs"`   // 工作目录
+	OutputDirs []string `json:"outputDirs"` // 输出目录
+}
+
+// UpdateInfo 发布源中的最新版本信息
+type UpdateInfo struct {
+	Current    string `json:"current"`    // 当前版本
+	Latest     string `json:"latest"`     // 发布源中的最新版本
+	Available  bool   `json:"available"`  // 是否有更新的版本
+	Notes      string `json:"notes"`      // 更新说明
+	URL        string `json:"url"`        // 发布页面地址
+	StagedPath string `json:"stagedPath"` // 已下载并校验的安装包路径，为空表示未下载
 }
 
 // ProgressEvent 进度事件
 type ProgressEvent struct {
-	Current int    `json:"current"` // 当前进度
-	Total   int    `json:"total"`   // 总数
+	Phase   string `json:"phase"`   // 阶段：open（读取 ZIP）、scan（扫描工作目录）、compare、export
+	Current int    `json:"current"` // 当前进度（scan 阶段为已发现的文件数）
+	Total   int    `json:"total"`   // 总数，为 0 表示总数未知
 	Message string `json:"message"` // 进度消息
 }
+
+// SnapshotInfo 工作目录的快照
+type SnapshotInfo struct {
+	Path    string `json:"path"`    // 快照文件路径，为空表示还没有记录快照
+	Files   int    `json:"files"`   // 记录的文件数
+	TakenAt string `json:"takenAt"` // 记录时间（RFC 3339）
+}
+
+// TempUsage 临时文件的占用情况，清理时为删除的部分
+type TempUsage struct {
+	Dir   string `json:"dir"`   // 临时文件目录
+	Files int    `json:"files"` // 文件数
+	Bytes int64  `json:"bytes"` // 总大小（字节）
+	InUse int    `json:"inUse"` // 使用中（清理时跳过）的临时文件和目录数
+}
+
+// CompareEstimate 比较前的快速扫描结果（只列出文件，不读取内容）和预计耗时
+type CompareEstimate struct {
+	BaselineFiles int   `json:"baselineFiles"` // 基准中参与比较的文件数
+	BaselineBytes int64 `json:"baselineBytes"` // 基准中参与比较的文件总大小（字节）
+	WorkFiles     int   `json:"workFiles"`     // 工作目录中参与比较的文件数
+	WorkBytes     int64 `json:"workBytes"`     // 工作目录中参与比较的文件总大小（字节）
+	HashBytes     int64 `json:"hashBytes"`     // 两侧大小相同、需要读取内容比较校验和的字节数
+	Excluded      int   `json:"excluded"`      // 被排除规则或 .gitignore 排除的文件数
+	EstimatedMs   int64 `json:"estimatedMs"`   // 按历史吞吐量预计的耗时（毫秒），0 表示没有历史记录
+	Samples       int   `json:"samples"`       // 用于预计的历史比较次数
+}
+
+// ThroughputSample 一次比较检查的文件数、读取的字节数和耗时，用于预计之后的比较耗时
+type ThroughputSample struct {
+	Files      int   `json:"files"`      // 检查的文件数
+	Bytes      int64 `json:"bytes"`      // 计算校验和读取的字节数
+	DurationMs int64 `json:"durationMs"` // 耗时（毫秒）
+}
+
+// 比较内容使用的算法
+const (
+	HashCRC32  = "crc32"  // 工作目录文件的 CRC32 与 ZIP 中记录的比较，不读取基准
+	HashMD5    = "md5"    // 读取两侧内容计算 MD5
+	HashSHA256 = "sha256" // 读取两侧内容计算 SHA-256
+)
+
+// CompareOptions 一次比较的选项，由前端整体传入，新增选项时不需要修改接口签名
+type CompareOptions struct {
+	ZipPath         string        `json:"zipPath"`         // 基准 ZIP 文件
+	WorkDir         string        `json:"workDir"`         // 工作目录
+	ExcludeRules    []ExcludeRule `json:"excludeRules"`    // 不为 null 时代替配置中的排除规则（含共享规则）
+	SessionRules    []ExcludeRule `json:"sessionRules"`    // 仅本次比较生效的临时规则，追加在排除规则之后（优先级更高）
+	NameEncoding    string        `json:"nameEncoding"`    // ZIP 的文件名编码，为空时使用配置
+	SubPath         string        `json:"subPath"`         // 只比较该子目录（相对于 ZIP 根目录和工作目录）
+	HashAlgorithm   string        `json:"hashAlgorithm"`   // 大小相同的文件比较内容的算法：crc32（默认）、md5、sha256
+	FastMode        bool          `json:"fastMode"`        // 大小和修改时间都与 ZIP 中记录的相同的文件不读取内容，视为相同
+	Concurrency     int           `json:"concurrency"`     // 扫描工作目录时并发读取目录的数量，0 表示默认
+	CaseInsensitive bool          `json:"caseInsensitive"` // 路径不区分大小写，与 ZIP 只差大小写的文件视为同一文件
+}
+
+// 审阅状态
+const (
+	ReviewPending  = "pending"  // 待审阅
+	ReviewApproved = "approved" // 已通过
+	ReviewRejected = "rejected" // 已驳回
+)
+
+// Review 一个差异项的审阅记录，按基准和工作目录保存，重新比较后恢复
+type Review struct {
+	Status    string `json:"status"`    // 审阅状态，见 ReviewPending 等
+	Note      string `json:"note"`      // 说明或审阅意见
+	Type      string `json:"type"`      // 审阅时的差异类型
+	Size      int64  `json:"size"`      // 审阅时工作目录中的大小（字节），与类型一起判断审阅后文件是否又有变化
+	UpdatedAt string `json:"updatedAt"` // 最近修改的时间（RFC 3339）
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package platform
+
+// RunElevatedAndWait 非 Windows 平台不支持 UAC 提权
+func RunElevatedAndWait(exe string, args []string) (int, error) {
+	return 0, ErrElevationUnsupported
+}
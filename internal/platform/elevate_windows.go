@@ -0,0 +1,89 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	seeMaskNoCloseProcess               = 0x00000040
+	seeMaskNoAsync                      = 0x00000100
+	swHide                              = 0
+	errorCancelled        syscall.Errno = 1223
+)
+
+var (
+	modShell32          = syscall.NewLazyDLL("shell32.dll")
+	procShellExecuteExW = modShell32.NewProc("ShellExecuteExW")
+)
+
+// shellExecuteInfo 对应 SHELLEXECUTEINFOW
+type shellExecuteInfo struct {
+	cbSize         uint32
+	fMask          uint32
+	hwnd           uintptr
+	lpVerb         *uint16
+	lpFile         *uint16
+	lpParameters   *uint16
+	lpDirectory    *uint16
+	nShow          int32
+	hInstApp       uintptr
+	lpIDList       uintptr
+	lpClass        *uint16
+	hkeyClass      uintptr
+	dwHotKey       uint32
+	hIconOrMonitor uintptr
+	hProcess       syscall.Handle
+}
+
+// RunElevatedAndWait 通过 UAC 提示以管理员身份运行程序，等待其退出并返回退出码
+func RunElevatedAndWait(exe string, args []string) (int, error) {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = syscall.EscapeArg(arg)
+	}
+
+	verb, _ := syscall.UTF16PtrFromString("runas")
+	file, err := syscall.UTF16PtrFromString(exe)
+	if err != nil {
+		return 0, err
+	}
+	params, err := syscall.UTF16PtrFromString(strings.Join(quoted, " "))
+	if err != nil {
+		return 0, err
+	}
+
+	info := shellExecuteInfo{
+		fMask:        seeMaskNoCloseProcess | seeMaskNoAsync,
+		lpVerb:       verb,
+		lpFile:       file,
+		lpParameters: params,
+		nShow:        swHide,
+	}
+	info.cbSize = uint32(unsafe.Sizeof(info))
+
+	if ret, _, callErr := procShellExecuteExW.Call(uintptr(unsafe.Pointer(&info))); ret == 0 {
+		if errors.Is(callErr, errorCancelled) {
+			return 0, ErrElevationCancelled
+		}
+		return 0, callErr
+	}
+	if info.hProcess == 0 {
+		return 0, errors.New("elevated process handle is not available")
+	}
+	defer syscall.CloseHandle(info.hProcess)
+
+	if _, err := syscall.WaitForSingleObject(info.hProcess, syscall.INFINITE); err != nil {
+		return 0, err
+	}
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(info.hProcess, &code); err != nil {
+		return 0, err
+	}
+	return int(code), nil
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package platform
+
+// JobPipe 非 Windows 平台不支持提权，也不需要任务管道
+type JobPipe struct{}
+
+// NewJobPipe 非 Windows 平台不支持提权
+func NewJobPipe() (*JobPipe, error) {
+	return nil, ErrElevationUnsupported
+}
+
+// Name 返回管道路径
+func (p *JobPipe) Name() string {
+	return ""
+}
+
+// Exchange 非 Windows 平台不支持提权
+func (p *JobPipe) Exchange(request []byte) ([]byte, error) {
+	return nil, ErrElevationUnsupported
+}
+
+// Abort 非 Windows 平台无操作
+func (p *JobPipe) Abort() {}
+
+// Close 非 Windows 平台无操作
+func (p *JobPipe) Close() error {
+	return nil
+}
@@ -0,0 +1,106 @@
+//go:build windows
+
+package platform
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	pipeAccessDuplex          = 0x00000003
+	fileFlagFirstPipeInstance = 0x00080000
+	pipeRejectRemoteClients   = 0x00000008
+	pipeBufferSize            = 64 << 10
+
+	errorPipeConnected syscall.Errno = 535
+)
+
+var (
+	procCreateNamedPipeW = modKernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe = modKernel32.NewProc("ConnectNamedPipe")
+)
+
+// JobPipe 向提权子进程传递任务的命名管道
+// 管道名随机且只允许一个实例，其他进程既不能抢先创建同名管道，也不能在子进程连接前替换任务内容
+type JobPipe struct {
+	name   string
+	handle syscall.Handle
+}
+
+// NewJobPipe 创建任务管道，使用后由调用方 Close
+func NewJobPipe() (*JobPipe, error) {
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	name := JobPipePrefix + hex.EncodeToString(suffix)
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, _, callErr := procCreateNamedPipeW.Call(
+		uintptr(unsafe.Pointer(namePtr)),
+		pipeAccessDuplex|fileFlagFirstPipeInstance,
+		pipeRejectRemoteClients, // 字节流、阻塞模式
+		1,
+		pipeBufferSize,
+		pipeBufferSize,
+		0,
+		0,
+	)
+	if syscall.Handle(handle) == syscall.InvalidHandle {
+		return nil, callErr
+	}
+	return &JobPipe{name: name, handle: syscall.Handle(handle)}, nil
+}
+
+// Name 返回管道路径，作为参数传给子进程
+func (p *JobPipe) Name() string {
+	return p.name
+}
+
+// Exchange 等待子进程连接，发送任务后读取子进程的回复，直到子进程关闭连接
+func (p *JobPipe) Exchange(request []byte) ([]byte, error) {
+	if ret, _, callErr := procConnectNamedPipe.Call(uintptr(p.handle), 0); ret == 0 && callErr != errorPipeConnected {
+		return nil, callErr
+	}
+
+	for len(request) > 0 {
+		var written uint32
+		if err := syscall.WriteFile(p.handle, request, &written, nil); err != nil {
+			return nil, err
+		}
+		request = request[written:]
+	}
+
+	var reply []byte
+	buf := make([]byte, 4096)
+	for {
+		var read uint32
+		err := syscall.ReadFile(p.handle, buf, &read, nil)
+		reply = append(reply, buf[:read]...)
+		if err == syscall.ERROR_BROKEN_PIPE {
+			return reply, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Abort 子进程没有连接管道时（如用户取消了 UAC 提示）自行连接一次，使等待中的 Exchange 返回空回复
+func (p *JobPipe) Abort() {
+	if file, err := os.OpenFile(p.name, os.O_RDWR, 0); err == nil {
+		file.Close()
+	}
+}
+
+// Close 关闭管道
+func (p *JobPipe) Close() error {
+	return syscall.CloseHandle(p.handle)
+}
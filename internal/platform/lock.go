@@ -0,0 +1,34 @@
+package platform
+
+import (
+	"Discrepancies/internal/models"
+	"os"
+)
+
+// FindLockedFiles 检查目标文件是否被其他进程占用，返回被占用的文件及占用它们的进程
+// 不存在的文件会被忽略
+func FindLockedFiles(paths []string) []models.LockedFile {
+	locked := make([]models.LockedFile, 0)
+
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err == nil {
+			file.Close()
+			continue
+		}
+		if !isLockError(err) {
+			continue
+		}
+
+		locked = append(locked, models.LockedFile{
+			Path:      path,
+			Processes: lockHolders(path),
+		})
+	}
+
+	return locked
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package platform
+
+// isLockError 非 Windows 平台没有强制文件锁
+func isLockError(err error) bool {
+	return false
+}
+
+// lockHolders 非 Windows 平台不查询占用进程
+func lockHolders(path string) []string {
+	return nil
+}
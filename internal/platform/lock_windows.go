@@ -0,0 +1,99 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+	errorMoreData         syscall.Errno = 234
+
+	cchRmSessionKey   = 32
+	cchRmMaxAppName   = 255
+	cchRmMaxSvcName   = 63
+	rmGetListMaxTries = 3
+)
+
+var (
+	modRstrtmgr             = syscall.NewLazyDLL("rstrtmgr.dll")
+	procRmStartSession      = modRstrtmgr.NewProc("RmStartSession")
+	procRmRegisterResources = modRstrtmgr.NewProc("RmRegisterResources")
+	procRmGetList           = modRstrtmgr.NewProc("RmGetList")
+	procRmEndSession        = modRstrtmgr.NewProc("RmEndSession")
+)
+
+// rmUniqueProcess 对应 RM_UNIQUE_PROCESS
+type rmUniqueProcess struct {
+	ProcessID        uint32
+	ProcessStartTime syscall.Filetime
+}
+
+// rmProcessInfo 对应 RM_PROCESS_INFO
+type rmProcessInfo struct {
+	Process          rmUniqueProcess
+	AppName          [cchRmMaxAppName + 1]uint16
+	ServiceShortName [cchRmMaxSvcName + 1]uint16
+	ApplicationType  uint32
+	AppStatus        uint32
+	TSSessionID      uint32
+	Restartable      int32
+}
+
+// isLockError 判断错误是否是共享冲突或锁冲突
+func isLockError(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation)
+}
+
+// lockHolders 通过 Restart Manager 查询占用文件的进程
+func lockHolders(path string) []string {
+	if err := modRstrtmgr.Load(); err != nil {
+		return nil
+	}
+
+	var session uint32
+	var key [cchRmSessionKey + 1]uint16
+	if ret, _, _ := procRmStartSession.Call(uintptr(unsafe.Pointer(&session)), 0, uintptr(unsafe.Pointer(&key[0]))); ret != 0 {
+		return nil
+	}
+	defer procRmEndSession.Call(uintptr(session))
+
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil
+	}
+	names := []*uint16{name}
+	if ret, _, _ := procRmRegisterResources.Call(uintptr(session), 1, uintptr(unsafe.Pointer(&names[0])), 0, 0, 0, 0); ret != 0 {
+		return nil
+	}
+
+	var needed, count, reasons uint32
+	var infos []rmProcessInfo
+	for i := 0; i < rmGetListMaxTries; i++ {
+		var ptr uintptr
+		if count > 0 {
+			infos = make([]rmProcessInfo, count)
+			ptr = uintptr(unsafe.Pointer(&infos[0]))
+		}
+		ret, _, _ := procRmGetList.Call(uintptr(session), uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&count)), ptr, uintptr(unsafe.Pointer(&reasons)))
+		if ret == 0 {
+			break
+		}
+		if syscall.Errno(ret) != errorMoreData {
+			return nil
+		}
+		count = needed
+	}
+
+	holders := make([]string, 0, count)
+	for i := 0; i < int(count) && i < len(infos); i++ {
+		appName := syscall.UTF16ToString(infos[i].AppName[:])
+		holders = append(holders, fmt.Sprintf("%s (PID %d)", appName, infos[i].Process.ProcessID))
+	}
+	return holders
+}
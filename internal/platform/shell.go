@@ -0,0 +1,28 @@
+package platform
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// URLScheme 注册的协议名，链接形如 discrepancies://compare?zip=…&dir=…
+const URLScheme = "discrepancies"
+
+// shellVerbText ZIP 文件右键菜单中的命令名称
+const shellVerbText = "用 Discrepancies 比较"
+
+// ErrShellUnsupported 当前平台不能在运行时注册协议和右键菜单
+var ErrShellUnsupported = errors.New("shell integration is not supported on this platform")
+
+// executablePath 返回当前程序的绝对路径（解析符号链接），注册到系统中的命令指向它
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
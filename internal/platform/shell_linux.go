@@ -0,0 +1,97 @@
+//go:build linux
+
+package platform
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// desktopFileName 注册的桌面文件，桌面环境据此在 ZIP 的「打开方式」中列出本程序并处理 discrepancies:// 链接
+const desktopFileName = "discrepancies-handler.desktop"
+
+// RegisterShell 为当前用户写入桌面文件并将其设为 discrepancies:// 链接的处理程序，不改变 ZIP 的默认打开程序
+func RegisterShell() error {
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+	path, err := desktopFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, desktopEntry(exe), 0644); err != nil {
+		return err
+	}
+
+	// 以下工具不存在或失败时不影响注册，桌面环境下次扫描时也会识别
+	exec.Command("update-desktop-database", filepath.Dir(path)).Run()
+	exec.Command("xdg-mime", "default", desktopFileName, "x-scheme-handler/"+URLScheme).Run()
+	return nil
+}
+
+// UnregisterShell 删除 RegisterShell 写入的桌面文件
+func UnregisterShell() error {
+	path, err := desktopFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exec.Command("update-desktop-database", filepath.Dir(path)).Run()
+	return nil
+}
+
+// ShellRegistered 判断桌面文件是否存在且指向当前程序
+func ShellRegistered() bool {
+	exe, err := executablePath()
+	if err != nil {
+		return false
+	}
+	path, err := desktopFilePath()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	return err == nil && bytes.Equal(data, desktopEntry(exe))
+}
+
+// desktopFilePath 返回用户的桌面文件路径（$XDG_DATA_HOME/applications，默认 ~/.local/share/applications）
+func desktopFilePath() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "applications", desktopFileName), nil
+}
+
+// desktopEntry 生成桌面文件的内容；不在应用菜单中显示，只用于打开方式和链接
+func desktopEntry(exe string) []byte {
+	var sb strings.Builder
+	sb.WriteString("[Desktop Entry]\n")
+	sb.WriteString("Type=Application\n")
+	sb.WriteString("Name=Discrepancies\n")
+	sb.WriteString("Comment=" + shellVerbText + "\n")
+	sb.WriteString("Exec=" + desktopQuote(exe) + " %u\n")
+	sb.WriteString("Terminal=false\n")
+	sb.WriteString("NoDisplay=true\n")
+	sb.WriteString("MimeType=x-scheme-handler/" + URLScheme + ";application/zip;\n")
+	return []byte(sb.String())
+}
+
+// desktopQuote 按桌面文件规范给 Exec 中的路径加引号：引号内的特殊字符加反斜杠，再按字符串值的规则转义反斜杠，% 写作 %%
+func desktopQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "`", "\\\\`", "$", `\\$`, "%", "%%").Replace(s)
+	return `"` + s + `"`
+}
@@ -0,0 +1,18 @@
+//go:build !windows && !linux
+
+package platform
+
+// RegisterShell 其他平台不支持在运行时注册；macOS 的协议在应用包的 Info.plist 中声明，ZIP 可通过「打开方式」选择本程序
+func RegisterShell() error {
+	return ErrShellUnsupported
+}
+
+// UnregisterShell 其他平台不支持在运行时注册
+func UnregisterShell() error {
+	return ErrShellUnsupported
+}
+
+// ShellRegistered 其他平台总是返回 false
+func ShellRegistered() bool {
+	return false
+}
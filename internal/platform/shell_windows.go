@@ -0,0 +1,139 @@
+//go:build windows
+
+package platform
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	hkeyCurrentUser   = syscall.Handle(0x80000001)
+	shcneAssocChanged = 0x08000000
+)
+
+var (
+	modAdvapi32         = syscall.NewLazyDLL("advapi32.dll")
+	procRegCreateKeyExW = modAdvapi32.NewProc("RegCreateKeyExW")
+	procRegSetValueExW  = modAdvapi32.NewProc("RegSetValueExW")
+	procRegDeleteTreeW  = modAdvapi32.NewProc("RegDeleteTreeW")
+	procSHChangeNotify  = modShell32.NewProc("SHChangeNotify")
+)
+
+// 当前用户的注册表项（HKCU\Software\Classes 下），不需要管理员权限
+const (
+	protocolKey = `Software\Classes\` + URLScheme
+	zipVerbKey  = `Software\Classes\SystemFileAssociations\.zip\shell\Discrepancies.Compare`
+)
+
+// RegisterShell 为当前用户注册 discrepancies:// 协议和 ZIP 文件右键菜单中的「用 Discrepancies 比较」，不改变 ZIP 的默认打开程序
+func RegisterShell() error {
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+	command := syscall.EscapeArg(exe) + ` "%1"`
+	values := []struct{ key, name, value string }{
+		{protocolKey, "", "URL:Discrepancies Protocol"},
+		{protocolKey, "URL Protocol", ""},
+		{protocolKey + `\DefaultIcon`, "", exe + ",0"},
+		{protocolKey + `\shell\open\command`, "", command},
+		{zipVerbKey, "", shellVerbText},
+		{zipVerbKey, "Icon", exe},
+		{zipVerbKey + `\command`, "", command},
+	}
+	for _, v := range values {
+		if err := setRegString(v.key, v.name, v.value); err != nil {
+			return err
+		}
+	}
+	procSHChangeNotify.Call(shcneAssocChanged, 0, 0, 0)
+	return nil
+}
+
+// UnregisterShell 删除 RegisterShell 注册的协议和右键菜单
+func UnregisterShell() error {
+	for _, key := range []string{protocolKey, zipVerbKey} {
+		if err := deleteRegTree(key); err != nil {
+			return err
+		}
+	}
+	procSHChangeNotify.Call(shcneAssocChanged, 0, 0, 0)
+	return nil
+}
+
+// ShellRegistered 判断协议是否已注册到当前程序
+func ShellRegistered() bool {
+	exe, err := executablePath()
+	if err != nil {
+		return false
+	}
+	value, err := getRegString(protocolKey+`\shell\open\command`, "")
+	return err == nil && value == syscall.EscapeArg(exe)+` "%1"`
+}
+
+// setRegString 创建注册表项（已存在时打开）并写入字符串值，name 为空时写入默认值
+func setRegString(key, name, value string) error {
+	keyPtr, err := syscall.UTF16PtrFromString(key)
+	if err != nil {
+		return err
+	}
+	var handle syscall.Handle
+	if ret, _, _ := procRegCreateKeyExW.Call(uintptr(hkeyCurrentUser), uintptr(unsafe.Pointer(keyPtr)), 0, 0, 0,
+		uintptr(syscall.KEY_WRITE), 0, uintptr(unsafe.Pointer(&handle)), 0); ret != 0 {
+		return syscall.Errno(ret)
+	}
+	defer syscall.RegCloseKey(handle)
+
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	data, err := syscall.UTF16FromString(value)
+	if err != nil {
+		return err
+	}
+	if ret, _, _ := procRegSetValueExW.Call(uintptr(handle), uintptr(unsafe.Pointer(namePtr)), 0, syscall.REG_SZ,
+		uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)*2)); ret != 0 {
+		return syscall.Errno(ret)
+	}
+	return nil
+}
+
+// getRegString 读取注册表中的字符串值
+func getRegString(key, name string) (string, error) {
+	keyPtr, err := syscall.UTF16PtrFromString(key)
+	if err != nil {
+		return "", err
+	}
+	var handle syscall.Handle
+	if err := syscall.RegOpenKeyEx(hkeyCurrentUser, keyPtr, 0, syscall.KEY_READ, &handle); err != nil {
+		return "", err
+	}
+	defer syscall.RegCloseKey(handle)
+
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]uint16, 1024)
+	size := uint32(len(buf) * 2)
+	var typ uint32
+	if err := syscall.RegQueryValueEx(handle, namePtr, nil, &typ, (*byte)(unsafe.Pointer(&buf[0])), &size); err != nil {
+		return "", err
+	}
+	return syscall.UTF16ToString(buf), nil
+}
+
+// deleteRegTree 删除注册表项及其子项，项不存在时不返回错误
+func deleteRegTree(key string) error {
+	keyPtr, err := syscall.UTF16PtrFromString(key)
+	if err != nil {
+		return err
+	}
+	ret, _, _ := procRegDeleteTreeW.Call(uintptr(hkeyCurrentUser), uintptr(unsafe.Pointer(keyPtr)))
+	if ret != 0 && syscall.Errno(ret) != syscall.ERROR_FILE_NOT_FOUND {
+		return syscall.Errno(ret)
+	}
+	return nil
+}
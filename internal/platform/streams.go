@@ -0,0 +1,12 @@
+package platform
+
+import "errors"
+
+// ErrStreamsUnsupported 当前平台不支持备用数据流
+var ErrStreamsUnsupported = errors.New("alternate data streams are not supported on this platform")
+
+// Stream NTFS 备用数据流
+type Stream struct {
+	Name string // 流名称，如 Zone.Identifier
+	Size int64  // 流大小
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package platform
+
+// ListStreams 非 Windows 平台没有备用数据流
+func ListStreams(path string) ([]Stream, error) {
+	return nil, ErrStreamsUnsupported
+}
@@ -0,0 +1,70 @@
+//go:build windows
+
+package platform
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	errorHandleEOF      syscall.Errno = 38
+	findStreamInfoStd                 = 0
+	maxStreamNameLength               = syscall.MAX_PATH + 36
+)
+
+var (
+	modKernel32          = syscall.NewLazyDLL("kernel32.dll")
+	procFindFirstStreamW = modKernel32.NewProc("FindFirstStreamW")
+	procFindNextStreamW  = modKernel32.NewProc("FindNextStreamW")
+)
+
+// win32FindStreamData 对应 WIN32_FIND_STREAM_DATA
+type win32FindStreamData struct {
+	StreamSize int64
+	StreamName [maxStreamNameLength]uint16
+}
+
+// ListStreams 列出文件的备用数据流（不含默认的 ::$DATA 流）
+func ListStreams(path string) ([]Stream, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data win32FindStreamData
+	handle, _, callErr := procFindFirstStreamW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		findStreamInfoStd,
+		uintptr(unsafe.Pointer(&data)),
+		0,
+	)
+	if syscall.Handle(handle) == syscall.InvalidHandle {
+		if errors.Is(callErr, errorHandleEOF) {
+			return nil, nil
+		}
+		return nil, callErr
+	}
+	defer syscall.FindClose(syscall.Handle(handle))
+
+	streams := make([]Stream, 0)
+	for {
+		// 名称格式为 :name:$DATA，默认流为 ::$DATA
+		name := syscall.UTF16ToString(data.StreamName[:])
+		name = strings.TrimSuffix(strings.TrimPrefix(name, ":"), ":$DATA")
+		if name != "" {
+			streams = append(streams, Stream{Name: name, Size: data.StreamSize})
+		}
+
+		ret, _, callErr := procFindNextStreamW.Call(handle, uintptr(unsafe.Pointer(&data)))
+		if ret == 0 {
+			if errors.Is(callErr, errorHandleEOF) {
+				break
+			}
+			return streams, callErr
+		}
+	}
+	return streams, nil
+}
@@ -0,0 +1,75 @@
+package platform
+
+import (
+	"Discrepancies/internal/models"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrElevationUnsupported 当前平台不支持以管理员身份重新运行
+var ErrElevationUnsupported = errors.New("elevation is not supported on this platform")
+
+// JobPipePrefix 提权子进程任务管道的路径前缀
+const JobPipePrefix = `\\.\pipe\discrepancies-job-`
+
+// ErrElevationCancelled 用户在 UAC 提示中拒绝了提权
+var ErrElevationCancelled = errors.New("elevation was cancelled by the user")
+
+// CheckWritable 预检目标路径是否可写，返回没有写入权限的文件或目录
+// 已存在的文件检查能否以写方式打开，不存在的文件检查最近的已存在上级目录能否创建文件
+func CheckWritable(paths []string) []models.PathIssue {
+	issues := make([]models.PathIssue, 0)
+	checkedDirs := make(map[string]bool)
+
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			file, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				if os.IsPermission(err) {
+					issues = append(issues, models.PathIssue{Path: path, Reason: err.Error()})
+				}
+				continue
+			}
+			file.Close()
+			continue
+		}
+
+		dir := nearestExistingDir(filepath.Dir(path))
+		if checkedDirs[dir] {
+			continue
+		}
+		checkedDirs[dir] = true
+
+		if err := probeDir(dir); err != nil && os.IsPermission(err) {
+			issues = append(issues, models.PathIssue{Path: dir, Reason: err.Error()})
+		}
+	}
+
+	return issues
+}
+
+// nearestExistingDir 返回路径自身或最近的已存在上级目录
+func nearestExistingDir(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// probeDir 尝试在目录中创建并删除临时文件
+func probeDir(dir string) error {
+	file, err := os.CreateTemp(dir, ".discrepancies-write-test-*")
+	if err != nil {
+		return err
+	}
+	name := file.Name()
+	file.Close()
+	return os.Remove(name)
+}
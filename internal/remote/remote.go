@@ -0,0 +1,94 @@
+// Package remote 将导出的文件直接写入远程目标（sftp://、s3://），内容流式上传，不在本地暂存
+package remote
+
+import (
+	"Discrepancies/internal/models"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// 支持的远程目标协议
+const (
+	SchemeSFTP = "sftp"
+	SchemeS3   = "s3"
+)
+
+// ErrInvalidURL 远程目标地址无效（缺少主机或存储桶、协议不支持等）
+var ErrInvalidURL = errors.New("invalid remote destination")
+
+// Destination 远程导出目标，路径相对于目标地址中的目录（或 S3 前缀），使用 / 分隔
+type Destination interface {
+	// Create 创建或覆盖文件，返回写入内容的 Writer；Close 成功后文件才完整写入
+	Create(relPath string) (io.WriteCloser, error)
+	// String 返回目标地址（不含凭据），用于提示
+	String() string
+	// Close 断开连接
+	Close() error
+}
+
+// IsRemote 判断导出目标是否是远程地址（sftp:// 或 s3://）
+func IsRemote(dest string) bool {
+	scheme, _, ok := strings.Cut(dest, "://")
+	if !ok {
+		return false
+	}
+	scheme = strings.ToLower(scheme)
+	return scheme == SchemeSFTP || scheme == SchemeS3
+}
+
+// Join 在远程目标地址后追加子路径（如导出子目录模板渲染的结果）
+func Join(dest string, elem ...string) string {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return dest
+	}
+	parts := append([]string{u.Path}, elem...)
+	for i := range parts {
+		parts[i] = strings.ReplaceAll(parts[i], `\`, "/")
+	}
+	u.Path = path.Join(parts...)
+	u.RawPath = ""
+	return u.String()
+}
+
+// Open 连接远程目标
+// sftp://user@host[:port]/目录：用 SSH 密钥登录（见 models.RemoteConfig），主机密钥按 known_hosts 校验
+// s3://bucket/前缀：用访问密钥签名请求，大文件分段上传
+func Open(dest string, cfg models.RemoteConfig) (Destination, error) {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%w: missing host or bucket: %s", ErrInvalidURL, Redact(dest))
+	}
+	switch strings.ToLower(u.Scheme) {
+	case SchemeSFTP:
+		return openSFTP(u, cfg)
+	case SchemeS3:
+		return openS3(u, cfg)
+	}
+	return nil, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+}
+
+// Redact 返回去掉密码的地址，用于日志和提示
+func Redact(dest string) string {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return dest
+	}
+	return u.Redacted()
+}
+
+// cleanRel 校验并整理相对路径，不允许跳出目标目录
+func cleanRel(relPath string) (string, error) {
+	rel := path.Clean(strings.ReplaceAll(relPath, `\`, "/"))
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || strings.HasPrefix(rel, "/") {
+		return "", fmt.Errorf("invalid remote path: %s", relPath)
+	}
+	return rel, nil
+}
@@ -0,0 +1,177 @@
+package remote
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3PartSize 分段上传每段的大小；不超过一段的文件用一次 PUT 上传，写入时最多在内存中缓存一段
+const s3PartSize = 8 * 1024 * 1024
+
+// s3IdleTimeout 连接上持续没有数据收发的最长时间，超过时请求失败，避免上传卡住时导出一直等待
+const s3IdleTimeout = 2 * time.Minute
+
+// s3Destination 写入 S3（或兼容服务）存储桶中的前缀
+type s3Destination struct {
+	bucket string
+	prefix string
+	client *minio.Client
+}
+
+// openS3 按配置和环境变量准备 S3 客户端，不发起请求
+// AWS S3 默认使用虚拟主机风格地址，名称含 . 的存储桶改用路径风格，避免证书与主机名不符；兼容服务使用路径风格
+func openS3(u *url.URL, cfg models.RemoteConfig) (*s3Destination, error) {
+	region := cmp.Or(cfg.S3Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+	accessKey := cmp.Or(cfg.S3AccessKey, os.Getenv("AWS_ACCESS_KEY_ID"))
+	secretKey := cmp.Or(cfg.S3SecretKey, os.Getenv("AWS_SECRET_ACCESS_KEY"))
+	var token string
+	if cfg.S3AccessKey == "" {
+		token = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, errors.New("no s3 credentials: set remote.s3AccessKey and the s3-secret file in the config directory, or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	endpoint := "s3.amazonaws.com"
+	opts := &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, token),
+		Secure:       true,
+		Region:       region,
+		Transport:    s3Transport(),
+		BucketLookup: minio.BucketLookupAuto,
+	}
+	if cfg.S3Endpoint != "" {
+		parsed, err := url.Parse(strings.TrimRight(cfg.S3Endpoint, "/"))
+		if err != nil || parsed.Host == "" || parsed.Path != "" {
+			return nil, fmt.Errorf("%w: invalid s3 endpoint %q", ErrInvalidURL, cfg.S3Endpoint)
+		}
+		endpoint = parsed.Host
+		opts.Secure = parsed.Scheme != "http"
+		opts.BucketLookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(endpoint, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	return &s3Destination{bucket: u.Host, prefix: strings.Trim(u.Path, "/"), client: client}, nil
+}
+
+// s3Transport 返回带连接、握手和空闲超时的 HTTP 传输；上传大文件不限制总时长，只在连接停滞时失败
+func s3Transport() http.RoundTripper {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &idleTimeoutConn{Conn: conn}, nil
+		},
+		TLSHandshakeTimeout:   30 * time.Second,
+		ResponseHeaderTimeout: s3IdleTimeout,
+		ExpectContinueTimeout: time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	}
+}
+
+// idleTimeoutConn 每次读写前延长期限，连接超过 s3IdleTimeout 没有进展时读写返回超时错误
+type idleTimeoutConn struct {
+	net.Conn
+}
+
+func (c *idleTimeoutConn) Read(p []byte) (int, error) {
+	c.Conn.SetReadDeadline(time.Now().Add(s3IdleTimeout))
+	return c.Conn.Read(p)
+}
+
+func (c *idleTimeoutConn) Write(p []byte) (int, error) {
+	c.Conn.SetWriteDeadline(time.Now().Add(s3IdleTimeout))
+	return c.Conn.Write(p)
+}
+
+func (d *s3Destination) String() string {
+	return "s3://" + path.Join(d.bucket, d.prefix)
+}
+
+func (d *s3Destination) Close() error {
+	return nil
+}
+
+// Create 返回上传对象的 Writer，内容按段缓存在内存中，超过一段时改为分段上传
+// 每段附带 CRC32C 校验和，由服务器校验；分段上传失败时已上传的段会被删除
+func (d *s3Destination) Create(relPath string) (io.WriteCloser, error) {
+	rel, err := cleanRel(relPath)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Object{d: d, key: path.Join(d.prefix, rel)}, nil
+}
+
+// put 上传对象，size 为 -1 时按段读取并分段上传
+func (d *s3Destination) put(key string, r io.Reader, size int64) error {
+	_, err := d.client.PutObject(context.Background(), d.bucket, key, r, size, minio.PutObjectOptions{PartSize: s3PartSize})
+	if err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	return nil
+}
+
+// s3Object 正在上传的对象
+type s3Object struct {
+	d    *s3Destination
+	key  string
+	buf  []byte         // 开始分段上传前缓存的内容，不超过一段
+	pw   *io.PipeWriter // 分段上传时写入的内容通过管道交给后台的上传，为 nil 表示尚未开始
+	done chan error
+}
+
+func (o *s3Object) Write(p []byte) (int, error) {
+	if o.pw == nil {
+		if len(o.buf)+len(p) <= s3PartSize {
+			o.buf = append(o.buf, p...)
+			return len(p), nil
+		}
+		o.start()
+	}
+	return o.pw.Write(p)
+}
+
+// start 开始分段上传，先上传已缓存的内容
+func (o *s3Object) start() {
+	pr, pw := io.Pipe()
+	o.pw = pw
+	o.done = make(chan error, 1)
+	body := io.MultiReader(bytes.NewReader(o.buf), pr)
+	o.buf = nil
+	go func() {
+		err := o.d.put(o.key, body, -1)
+		// 上传失败时让之后的 Write 立即返回错误
+		pr.CloseWithError(err)
+		o.done <- err
+	}()
+}
+
+// Close 上传剩余内容并等待完成；未开始分段上传时用一次 PUT 上传整个对象
+func (o *s3Object) Close() error {
+	if o.pw == nil {
+		return o.d.put(o.key, bytes.NewReader(o.buf), int64(len(o.buf)))
+	}
+	o.pw.Close()
+	return <-o.done
+}
@@ -0,0 +1,176 @@
+package remote
+
+import (
+	"Discrepancies/internal/models"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sftpDestination 通过 SFTP 写入服务器上的目录，同一时间只写入一个文件
+type sftpDestination struct {
+	url    string
+	root   string
+	client *ssh.Client
+	sftp   *sftp.Client
+	dirs   map[string]bool // 已确认存在的目录
+}
+
+// openSFTP 登录 SSH 服务器并启动 sftp 子系统
+func openSFTP(u *url.URL, cfg models.RemoteConfig) (*sftpDestination, error) {
+	auth, err := sshAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+	hostKeys, err := hostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	username := u.User.Username()
+	if username == "" {
+		if current, err := user.Current(); err == nil {
+			username = filepath.Base(current.Username)
+		}
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: hostKeys,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	// 连续发送多个写请求，避免在高延迟的链路上每 32KB 等待一次往返
+	sftpClient, err := sftp.NewClient(client, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to start sftp subsystem: %w", err)
+	}
+	return &sftpDestination{url: u.Redacted(), root: sftpRoot(u.Path), client: client, sftp: sftpClient, dirs: make(map[string]bool)}, nil
+}
+
+// sftpRoot 返回地址中的目录：/~/ 开头或为空时相对于登录用户的主目录，其他为绝对路径
+func sftpRoot(urlPath string) string {
+	if urlPath == "" || urlPath == "/~" {
+		return "."
+	}
+	if rel, ok := strings.CutPrefix(urlPath, "/~/"); ok {
+		return path.Clean(rel)
+	}
+	return path.Clean(urlPath)
+}
+
+// sshAuth 返回登录方式：配置的私钥，或 SSH agent 和 ~/.ssh 下的默认私钥
+func sshAuth(cfg models.RemoteConfig) ([]ssh.AuthMethod, error) {
+	if cfg.SSHKeyFile != "" {
+		signer, err := loadSigner(cfg.SSHKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
+	}
+
+	methods := make([]ssh.AuthMethod, 0, 2)
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		signers := make([]ssh.Signer, 0)
+		for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+			// 有密码保护或无法解析的私钥跳过
+			if signer, err := loadSigner(filepath.Join(home, ".ssh", name)); err == nil {
+				signers = append(signers, signer)
+			}
+		}
+		if len(signers) > 0 {
+			methods = append(methods, ssh.PublicKeys(signers...))
+		}
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("no ssh key available: set remote.sshKeyFile or start ssh-agent")
+	}
+	return methods, nil
+}
+
+// loadSigner 读取 OpenSSH 或 PEM 格式的私钥
+func loadSigner(keyPath string) (ssh.Signer, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, fmt.Errorf("ssh key %s is protected by a passphrase, load it into ssh-agent instead", keyPath)
+		}
+		return nil, fmt.Errorf("failed to parse ssh key: %w", err)
+	}
+	return signer, nil
+}
+
+// hostKeyCallback 按 known_hosts 校验服务器主机密钥，不接受未知主机
+func hostKeyCallback(cfg models.RemoteConfig) (ssh.HostKeyCallback, error) {
+	file := cfg.KnownHostsFile
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+func (d *sftpDestination) String() string {
+	return d.url
+}
+
+func (d *sftpDestination) Close() error {
+	d.sftp.Close()
+	return d.client.Close()
+}
+
+// Create 打开（创建或截断）服务器上的文件，不存在的上级目录会先创建；写入下一个文件前需先 Close
+func (d *sftpDestination) Create(relPath string) (io.WriteCloser, error) {
+	rel, err := cleanRel(relPath)
+	if err != nil {
+		return nil, err
+	}
+	target := path.Join(d.root, rel)
+	if dir := path.Dir(target); !d.dirs[dir] {
+		if err := d.sftp.MkdirAll(dir); err != nil {
+			return nil, fmt.Errorf("failed to create remote directory %s: %w", dir, err)
+		}
+		d.dirs[dir] = true
+	}
+	file, err := d.sftp.Create(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open remote file %s: %w", target, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"Discrepancies/internal/models"
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultChangelogTitle 变更日志的默认标题
+const DefaultChangelogTitle = "变更日志"
+
+// RenderChangelog 将选中的差异项渲染为 Markdown 变更日志，按差异类型分组，附上各文件填写的说明
+// 删除的文件不在导出包中，也列在变更日志里，提示接收方手动删除
+func RenderChangelog(items []models.DiffItem, meta Meta, date time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n## %s\n\n", cmp.Or(meta.Title, DefaultChangelogTitle), date.Format("2006-01-02"))
+	if meta.Baseline != "" {
+		fmt.Fprintf(&sb, "基准: `%s`\n", meta.Baseline)
+	}
+	if len(meta.Tickets) > 0 {
+		fmt.Fprintf(&sb, "\n工单: %s\n", ticketsText(meta.Tickets, true))
+	}
+
+	groups := groupItems(items)
+	for _, g := range typeGroups {
+		selected := make([]models.DiffItem, 0, len(groups[g.Type]))
+		for _, item := range groups[g.Type] {
+			if item.Selected {
+				selected = append(selected, item)
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+		sort.Slice(selected, func(i, j int) bool { return selected[i].RelPath < selected[j].RelPath })
+
+		fmt.Fprintf(&sb, "\n### %s\n\n", g.Title)
+		for _, item := range selected {
+			fmt.Fprintf(&sb, "- `%s`", itemLabel(item))
+			if item.LinesAdded > 0 || item.LinesRemoved > 0 {
+				fmt.Fprintf(&sb, " (+%d/-%d)", item.LinesAdded, item.LinesRemoved)
+			}
+			if note := strings.Join(strings.Fields(item.Note), " "); note != "" {
+				fmt.Fprintf(&sb, "：%s", note)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
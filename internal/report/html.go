@@ -0,0 +1,103 @@
+package report
+
+import (
+	"Discrepancies/internal/models"
+	"bytes"
+	"cmp"
+	"embed"
+	"html/template"
+	"time"
+)
+
+//go:embed templates/print.html
+var templateFS embed.FS
+
+var printTemplate = template.Must(template.New("print.html").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).ParseFS(templateFS, "templates/print.html"))
+
+// printRow 打印报告中的一行
+type printRow struct {
+	Type         string
+	TypeText     string
+	Label        string
+	LinesAdded   int
+	LinesRemoved int
+	Review       string
+	ReviewText   string
+	Note         string
+}
+
+// printData 打印报告模板数据
+type printData struct {
+	Title       string
+	Meta        Meta
+	GeneratedAt string
+	Total       int
+	Added       int
+	Modified    int
+	Deleted     int
+	Renamed     int
+	Annotated   bool // 有差异项填写了审阅状态或说明，显示审阅和说明列
+	Rows        []printRow
+}
+
+// typeText 返回差异类型的显示名称
+func typeText(t string) string {
+	for _, g := range typeGroups {
+		if g.Type == t {
+			return g.Title
+		}
+	}
+	return t
+}
+
+// RenderPrintHTML 生成适合打印/另存为 PDF 的 HTML 报告，列出选中的差异项及其审阅状态和说明
+// 报告使用打印样式分页，表头在每页重复，末尾附签字栏
+func RenderPrintHTML(items []models.DiffItem, meta Meta) ([]byte, error) {
+	data := printData{
+		Title:       cmp.Or(meta.Title, DefaultPrintTitle),
+		Meta:        meta,
+		GeneratedAt: time.Now().Format("2006-01-02 15:04"),
+		Rows:        make([]printRow, 0, len(items)),
+	}
+
+	groups := groupItems(items)
+	for _, g := range typeGroups {
+		for _, item := range groups[g.Type] {
+			if !item.Selected {
+				continue
+			}
+			data.Rows = append(data.Rows, printRow{
+				Type:         item.Type,
+				TypeText:     typeText(item.Type),
+				Label:        itemLabel(item),
+				LinesAdded:   item.LinesAdded,
+				LinesRemoved: item.LinesRemoved,
+				Review:       item.Review,
+				ReviewText:   reviewText(item.Review),
+				Note:         item.Note,
+			})
+			if item.Review != "" || item.Note != "" {
+				data.Annotated = true
+			}
+			switch item.Type {
+			case "added":
+				data.Added++
+			case "modified":
+				data.Modified++
+			case "deleted":
+				data.Deleted++
+			case "renamed":
+				data.Renamed++
+			}
+		}
+	}
+	data.Total = len(data.Rows)
+
+	var buf bytes.Buffer
+	if err := printTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,27 @@
+package report
+
+import (
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"time"
+)
+
+// resultRecord 保存到导出目录的比较结果，与基准 ZIP 一起说明导出包是与什么比较得出的
+type resultRecord struct {
+	GeneratedAt time.Time             `json:"generatedAt"` // 导出时间
+	Baseline    string                `json:"baseline"`    // 基准名称（ZIP 文件名或 git 引用）
+	WorkDir     string                `json:"workDir"`     // 工作目录
+	Tickets     []models.TicketRef    `json:"tickets"`     // 比较关联的工单
+	Result      *models.CompareResult `json:"result"`      // 完整的比较结果，含未选中和未导出的差异项
+}
+
+// RenderResultJSON 将比较结果和报告头部信息渲染为缩进的 JSON
+func RenderResultJSON(result *models.CompareResult, meta Meta, date time.Time) ([]byte, error) {
+	return json.MarshalIndent(resultRecord{
+		GeneratedAt: date,
+		Baseline:    meta.Baseline,
+		WorkDir:     meta.WorkDir,
+		Tickets:     meta.Tickets,
+		Result:      result,
+	}, "", "  ")
+}
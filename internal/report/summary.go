@@ -0,0 +1,271 @@
+package report
+
+import (
+	"Discrepancies/internal/models"
+	"cmp"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// 摘要格式
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+)
+
+// Meta 报告头部信息
+type Meta struct {
+	Baseline string             // 基准名称（ZIP 文件名或 git 引用）
+	WorkDir  string             // 工作目录
+	Title    string             // 标题，为空时使用各报告的默认标题
+	Tickets  []models.TicketRef // 比较关联的工单
+}
+
+// 默认标题
+const (
+	DefaultPrintTitle   = "文件变更记录"
+	DefaultSummaryTitle = "差异摘要"
+)
+
+// DefaultPrintNameTemplate 打印报告默认的文件名模板（不含扩展名）
+const DefaultPrintNameTemplate = "{base}_变更记录_{date:compact}"
+
+// typeGroups 差异类型的分组顺序和显示名称
+var typeGroups = []struct {
+	Type  string
+	Title string
+}{
+	{"added", "新增"},
+	{"modified", "修改"},
+	{"renamed", "重命名"},
+	{"deleted", "删除"},
+}
+
+// groupItems 按差异类型分组
+func groupItems(items []models.DiffItem) map[string][]models.DiffItem {
+	groups := make(map[string][]models.DiffItem)
+	for _, item := range items {
+		groups[item.Type] = append(groups[item.Type], item)
+	}
+	return groups
+}
+
+// itemLabel 返回差异项的显示路径，重命名时包含旧路径
+func itemLabel(item models.DiffItem) string {
+	if item.Type == "renamed" && item.OldPath != "" {
+		return fmt.Sprintf("%s → %s", item.OldPath, item.RelPath)
+	}
+	return item.RelPath
+}
+
+// reviewText 返回审阅状态的显示名称，未审阅时返回空字符串
+func reviewText(status string) string {
+	switch status {
+	case models.ReviewPending:
+		return "待审阅"
+	case models.ReviewApproved:
+		return "已通过"
+	case models.ReviewRejected:
+		return "已驳回"
+	}
+	return ""
+}
+
+// reviewCountsText 返回审阅状态的统计，如「已通过 3，已驳回 1，待审阅 2」，没有审阅过的差异项时返回空字符串
+func reviewCountsText(items []models.DiffItem) string {
+	counts := make(map[string]int)
+	for _, item := range items {
+		if item.Review != "" {
+			counts[item.Review]++
+		}
+	}
+	parts := make([]string, 0, 3)
+	for _, status := range []string{models.ReviewApproved, models.ReviewRejected, models.ReviewPending} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", reviewText(status), counts[status]))
+		}
+	}
+	return strings.Join(parts, "，")
+}
+
+// annotationText 返回差异项的生成文件标记、审阅状态和说明，如「 [已通过] 修复登录超时」，都为空时返回空字符串
+func annotationText(item models.DiffItem) string {
+	text := ""
+	if item.GeneratedFrom != "" {
+		text += " [由 " + path.Base(item.GeneratedFrom) + " 生成]"
+	}
+	if status := reviewText(item.Review); status != "" {
+		text += " [" + status + "]"
+	}
+	if note := strings.Join(strings.Fields(item.Note), " "); note != "" {
+		text += " " + note
+	}
+	return text
+}
+
+// ticketsText 返回关联工单的显示文本，Markdown 格式中有链接的工单号显示为链接
+func ticketsText(tickets []models.TicketRef, markdown bool) string {
+	parts := make([]string, 0, len(tickets))
+	for _, t := range tickets {
+		text := t.ID
+		if markdown && t.URL != "" {
+			text = fmt.Sprintf("[%s](%s)", t.ID, t.URL)
+		}
+		if t.Title != "" {
+			text += " " + t.Title
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "；")
+}
+
+// FormatSize 将字节数格式化为 B、KB、MB、GB
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		value /= unit
+		if value < unit && value > -unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return ""
+}
+
+// sizeTotalsText 返回按差异类型汇总的大小变化，如「新增 4.2 MB，修改 +12.0 KB，删除 1.1 MB」，没有大小变化时返回空字符串
+func sizeTotalsText(result *models.CompareResult) string {
+	parts := make([]string, 0, 3)
+	if result.TotalAddedBytes > 0 {
+		parts = append(parts, "新增 "+FormatSize(result.TotalAddedBytes))
+	}
+	if delta := result.TotalModifiedBytesDelta; delta > 0 {
+		parts = append(parts, "修改 +"+FormatSize(delta))
+	} else if delta < 0 {
+		parts = append(parts, "修改 "+FormatSize(delta))
+	}
+	if result.TotalDeletedBytes > 0 {
+		parts = append(parts, "删除 "+FormatSize(result.TotalDeletedBytes))
+	}
+	return strings.Join(parts, "，")
+}
+
+// RenderSummary 将比较结果渲染为纯文本或 Markdown 摘要（统计数量、大小增加最多的文件和按类型分组的文件列表，列表中附审阅状态和说明）
+func RenderSummary(result *models.CompareResult, meta Meta, format string) (string, error) {
+	if result == nil {
+		return "", fmt.Errorf("no compare result")
+	}
+
+	var sb strings.Builder
+	markdown := false
+	switch format {
+	case FormatMarkdown:
+		markdown = true
+	case FormatText, "":
+	default:
+		return "", fmt.Errorf("unsupported summary format: %s", format)
+	}
+
+	// 标题和统计
+	title := cmp.Or(meta.Title, DefaultSummaryTitle)
+	if markdown {
+		fmt.Fprintf(&sb, "## %s\n\n", title)
+		if meta.Baseline != "" {
+			fmt.Fprintf(&sb, "- 基准: `%s`\n", meta.Baseline)
+		}
+		if meta.WorkDir != "" {
+			fmt.Fprintf(&sb, "- 工作目录: `%s`\n", meta.WorkDir)
+		}
+		if len(meta.Tickets) > 0 {
+			fmt.Fprintf(&sb, "- 工单: %s\n", ticketsText(meta.Tickets, true))
+		}
+		fmt.Fprintf(&sb, "- 共 %d 个差异：新增 %d，修改 %d，删除 %d", result.TotalFiles, result.Added, result.Modified, result.Deleted)
+	} else {
+		sb.WriteString(title + "\n")
+		if meta.Baseline != "" {
+			fmt.Fprintf(&sb, "基准: %s\n", meta.Baseline)
+		}
+		if meta.WorkDir != "" {
+			fmt.Fprintf(&sb, "工作目录: %s\n", meta.WorkDir)
+		}
+		if len(meta.Tickets) > 0 {
+			fmt.Fprintf(&sb, "工单: %s\n", ticketsText(meta.Tickets, false))
+		}
+		fmt.Fprintf(&sb, "共 %d 个差异：新增 %d，修改 %d，删除 %d", result.TotalFiles, result.Added, result.Modified, result.Deleted)
+	}
+	if result.Renamed > 0 {
+		fmt.Fprintf(&sb, "，重命名 %d", result.Renamed)
+	}
+	sb.WriteString("\n")
+	if sizes := sizeTotalsText(result); sizes != "" {
+		if markdown {
+			fmt.Fprintf(&sb, "- 大小：%s\n", sizes)
+		} else {
+			fmt.Fprintf(&sb, "大小：%s\n", sizes)
+		}
+	}
+	if result.Unchanged > 0 || result.Excluded > 0 {
+		coverage := fmt.Sprintf("相同 %d 个文件（%s），排除 %d 个文件", result.Unchanged, FormatSize(result.UnchangedBytes), result.Excluded)
+		if markdown {
+			fmt.Fprintf(&sb, "- 其他文件：%s\n", coverage)
+		} else {
+			fmt.Fprintf(&sb, "其他文件：%s\n", coverage)
+		}
+	}
+	if reviews := reviewCountsText(result.Items); reviews != "" {
+		if markdown {
+			fmt.Fprintf(&sb, "- 审阅：%s\n", reviews)
+		} else {
+			fmt.Fprintf(&sb, "审阅：%s\n", reviews)
+		}
+	}
+
+	// 大小增加最多的文件，便于发现误加入的大文件
+	if len(result.Largest) > 0 {
+		if markdown {
+			sb.WriteString("\n### 最大的变更\n\n")
+		} else {
+			sb.WriteString("\n[最大的变更]\n")
+		}
+		for _, change := range result.Largest {
+			if markdown {
+				fmt.Fprintf(&sb, "- `%s` +%s\n", change.RelPath, FormatSize(change.Delta))
+			} else {
+				fmt.Fprintf(&sb, "  %s +%s\n", change.RelPath, FormatSize(change.Delta))
+			}
+		}
+	}
+
+	// 分组文件列表
+	groups := groupItems(result.Items)
+	for _, g := range typeGroups {
+		items := groups[g.Type]
+		if len(items) == 0 {
+			continue
+		}
+
+		if markdown {
+			fmt.Fprintf(&sb, "\n### %s（%d）\n\n", g.Title, len(items))
+		} else {
+			fmt.Fprintf(&sb, "\n[%s] %d\n", g.Title, len(items))
+		}
+
+		for _, item := range items {
+			label := itemLabel(item)
+			stat := ""
+			if item.LinesAdded > 0 || item.LinesRemoved > 0 {
+				stat = fmt.Sprintf(" (+%d/-%d)", item.LinesAdded, item.LinesRemoved)
+			}
+			if markdown {
+				fmt.Fprintf(&sb, "- `%s`%s%s\n", label, stat, annotationText(item))
+			} else {
+				fmt.Fprintf(&sb, "  %s%s%s\n", label, stat, annotationText(item))
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
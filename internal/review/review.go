@@ -0,0 +1,92 @@
+// Package review 保存差异项的审阅状态和说明，按基准和工作目录区分，重新比较后恢复到结果中
+package review
+
+import (
+	"Discrepancies/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrInvalidStatus 审阅状态不是 pending、approved 或 rejected
+var ErrInvalidStatus = errors.New("review status must be pending, approved or rejected")
+
+// Set 一组比较（同一基准和工作目录）的审阅记录，键为差异项的相对路径
+type Set map[string]models.Review
+
+// ValidateStatus 检查审阅状态，空字符串视为待审阅
+func ValidateStatus(status string) error {
+	switch status {
+	case "", models.ReviewPending, models.ReviewApproved, models.ReviewRejected:
+		return nil
+	}
+	return ErrInvalidStatus
+}
+
+// Load 读取审阅记录文件，文件不存在时返回空记录
+func Load(path string) (Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Set{}, nil
+		}
+		return nil, fmt.Errorf("failed to read reviews: %w", err)
+	}
+	reviews := Set{}
+	if err := json.Unmarshal(data, &reviews); err != nil {
+		return nil, fmt.Errorf("failed to parse reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+// Save 写入审阅记录文件，没有记录时删除文件
+func (s Set) Save(path string) error {
+	if len(s) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove reviews: %w", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write reviews: %w", err)
+	}
+	return nil
+}
+
+// Record 记录差异项的审阅状态和说明，状态为待审阅且说明为空时删除记录
+func (s Set) Record(item models.DiffItem, status, note string) {
+	if (status == "" || status == models.ReviewPending) && note == "" {
+		delete(s, item.RelPath)
+		return
+	}
+	s[item.RelPath] = models.Review{
+		Status:    status,
+		Note:      note,
+		Type:      item.Type,
+		Size:      item.NewSize,
+		UpdatedAt: time.Now().Format(time.RFC3339),
+	}
+}
+
+// Apply 将审阅记录恢复到差异项中，没有记录的差异项清空审阅状态和说明；审阅后差异类型或大小又有变化的文件保留说明，状态改回待审阅
+func (s Set) Apply(items []models.DiffItem) {
+	for i := range items {
+		item := &items[i]
+		r, ok := s[item.RelPath]
+		if !ok {
+			item.Note, item.Review = "", ""
+			continue
+		}
+		item.Note = r.Note
+		item.Review = r.Status
+		if r.Status != models.ReviewPending && (r.Type != item.Type || r.Size != item.NewSize) {
+			item.Review = models.ReviewPending
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SignatureExt 分离签名文件的扩展名，签名保存在被签名文件旁
+const SignatureExt = ".sig"
+
+// signatureHeader 签名文件首行，标明算法
+const signatureHeader = "discrepancies-signature ed25519ph"
+
+// ErrNoKey 未生成签名密钥
+var ErrNoKey = errors.New("signing key not found")
+
+// ErrBadSignature 签名与文件内容不符，或不是由受信任的公钥签名
+var ErrBadSignature = errors.New("signature verification failed")
+
+// errSignatureFormat 签名文件不是本工具生成的格式
+var errSignatureFormat = errors.New("unsupported signature format")
+
+// ErrKeyExists 签名密钥已存在，覆盖后接收方持有的公钥将无法校验新签名
+var ErrKeyExists = errors.New("signing key already exists")
+
+// GenerateKey 生成 Ed25519 密钥对，私钥以 PKCS#8 PEM 写入 keyPath（仅当前用户可读），
+// 公钥以 PKIX PEM 写入 keyPath.pub，返回公钥 PEM。密钥已存在且 overwrite 为 false 时返回 ErrKeyExists
+func GenerateKey(keyPath string, overwrite bool) (string, error) {
+	if _, err := os.Stat(keyPath); err == nil && !overwrite {
+		return "", ErrKeyExists
+	}
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(keyPath, privatePEM, 0600); err != nil {
+		return "", fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(keyPath+".pub", publicPEM, 0644); err != nil {
+		return "", fmt.Errorf("failed to write public key: %w", err)
+	}
+	return string(publicPEM), nil
+}
+
+// PublicKey 读取 keyPath 对应的公钥 PEM
+func PublicKey(keyPath string) (string, error) {
+	data, err := os.ReadFile(keyPath + ".pub")
+	if os.IsNotExist(err) {
+		return "", ErrNoKey
+	}
+	return string(data), err
+}
+
+// SignFile 用 keyPath 中的私钥对文件生成分离签名，写入 path.sig 并返回签名文件路径
+// 使用 Ed25519ph（先计算 SHA-512 再签名），大文件无需整体读入内存
+func SignFile(path, keyPath string) (string, error) {
+	digest, err := fileDigest(path)
+	if err != nil {
+		return "", err
+	}
+	sigPath := path + SignatureExt
+	return sigPath, SignDigest(digest, keyPath, sigPath)
+}
+
+// SignDigest 对已计算的 SHA-512 摘要签名，写入 sigPath，用于签名边写边计算摘要的内容
+func SignDigest(digest []byte, keyPath, sigPath string) error {
+	content, err := Signature(digest, keyPath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(sigPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// Signature 对已计算的 SHA-512 摘要签名，返回 .sig 文件的内容，用于签名文件写入本地以外的位置
+func Signature(digest []byte, keyPath string) ([]byte, error) {
+	private, err := loadPrivateKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := private.Sign(nil, digest, &ed25519.Options{Hash: crypto.SHA512})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return []byte(signatureHeader + "\n" + base64.StdEncoding.EncodeToString(signature) + "\n"), nil
+}
+
+// loadPrivateKey 读取 PKCS#8 PEM 格式的 Ed25519 私钥
+func loadPrivateKey(keyPath string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(keyPath)
+	if os.IsNotExist(err) {
+		return nil, ErrNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key file: %s", keyPath)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not ed25519: %s", keyPath)
+	}
+	return private, nil
+}
+
+// fileDigest 计算文件的 SHA-512
+func fileDigest(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha512.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// VerifyFile 校验 path 的分离签名 path.sig，签名由 publicKeys（PEM）中任一公钥生成即通过
+// 签名文件不存在时返回的错误满足 os.IsNotExist
+func VerifyFile(path string, publicKeys []string) error {
+	data, err := os.ReadFile(path + SignatureExt)
+	if err != nil {
+		return err
+	}
+	err = VerifySignature(path, data, publicKeys)
+	if errors.Is(err, errSignatureFormat) {
+		return fmt.Errorf("%w: %s", err, path+SignatureExt)
+	}
+	return err
+}
+
+// VerifySignature 用 .sig 文件的内容 sigData 校验 path，用于签名不在文件旁的情况（如下载的更新包）
+func VerifySignature(path string, sigData []byte, publicKeys []string) error {
+	signature, err := parseSignature(sigData)
+	if err != nil {
+		return err
+	}
+	digest, err := fileDigest(path)
+	if err != nil {
+		return err
+	}
+
+	opts := &ed25519.Options{Hash: crypto.SHA512}
+	for _, publicKey := range publicKeys {
+		key, err := parsePublicKey(publicKey)
+		if err != nil {
+			continue
+		}
+		if ed25519.VerifyWithOptions(key, digest, signature, opts) == nil {
+			return nil
+		}
+	}
+	return ErrBadSignature
+}
+
+// parsePublicKey 解析 PKIX PEM 格式的 Ed25519 公钥
+func parsePublicKey(publicKey string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("invalid public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not ed25519")
+	}
+	return public, nil
+}
+
+// parseSignature 解析签名文件的内容
+func parseSignature(data []byte) ([]byte, error) {
+	header, encoded, _ := strings.Cut(string(data), "\n")
+	if header != signatureHeader {
+		return nil, errSignatureFormat
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, errSignatureFormat
+	}
+	return signature, nil
+}
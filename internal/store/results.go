@@ -0,0 +1,278 @@
+// Package store 保存比较结果，供前端按页获取
+// 结果超过内存上限时，差异项写入临时文件，通过分页接口按需读取
+package store
+
+import (
+	"Discrepancies/internal/compare"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/tempfile"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCapacity 默认保留的比较结果数量
+const DefaultCapacity = 4
+
+// DefaultMemoryLimit 默认的比较结果内存上限
+const DefaultMemoryLimit int64 = 256 << 20
+
+// entry 保存的比较结果；spill 不为 nil 时差异项已写入临时文件，result.Items 为空
+type entry struct {
+	result *models.CompareResult
+	spill  *spillFile
+}
+
+// Results 比较结果存储，超过容量时丢弃最早的结果
+type Results struct {
+	mu          sync.Mutex
+	capacity    int
+	memoryLimit int64
+	noSpill     bool              // 不写入临时文件（只读模式），超过内存上限的结果也保存在内存中
+	temp        *tempfile.Manager // 创建临时文件的管理器，为 nil 时使用系统临时目录
+	nextID      int
+	order       []string
+	results     map[string]*entry
+}
+
+// NewResults 创建比较结果存储
+func NewResults(capacity int) *Results {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Results{
+		capacity:    capacity,
+		memoryLimit: DefaultMemoryLimit,
+		results:     make(map[string]*entry),
+	}
+}
+
+// SetMemoryLimit 设置单个结果的内存上限（字节），小于等于 0 时使用默认值
+func (s *Results) SetMemoryLimit(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit <= 0 {
+		limit = DefaultMemoryLimit
+	}
+	s.memoryLimit = limit
+}
+
+// SetSpill 设置超过内存上限的结果是否写入临时文件
+func (s *Results) SetSpill(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.noSpill = !enabled
+}
+
+// SetTempManager 设置创建临时文件使用的管理器
+func (s *Results) SetTempManager(temp *tempfile.Manager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.temp = temp
+}
+
+// Put 保存比较结果，返回结果 ID（同时写入 result.ResultID）
+// 差异项超过内存上限时写入临时文件并清空 result.Items、设置 result.Spilled；写入失败时仍保存在内存中
+func (s *Results) Put(result *models.CompareResult) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	result.ResultID = id
+
+	e := &entry{result: result}
+	if !s.noSpill && estimateSize(result.Items) > s.memoryLimit {
+		if spill, err := writeSpillFile(result.Items, s.temp); err == nil {
+			e.spill = spill
+			result.Items = nil
+			result.Spilled = true
+		}
+	}
+	s.results[id] = e
+	s.order = append(s.order, id)
+
+	for len(s.order) > s.capacity {
+		s.evict(s.order[0])
+		s.order = s.order[1:]
+	}
+	return id
+}
+
+// Get 返回指定 ID 的比较结果，差异项已写入临时文件时 Items 为空
+func (s *Results) Get(id string) (*models.CompareResult, bool) {
+	e, ok := s.get(id)
+	if !ok {
+		return nil, false
+	}
+	return e.result, true
+}
+
+// Items 返回比较结果的全部差异项，已写入临时文件时从文件读取
+func (s *Results) Items(id string) ([]models.DiffItem, error) {
+	e, ok := s.get(id)
+	if !ok {
+		return nil, fmt.Errorf("result not found: %s", id)
+	}
+	if e.spill != nil {
+		return e.spill.all()
+	}
+	return e.result.Items, nil
+}
+
+// Page 按筛选和排序条件返回结果的一页
+// sortBy 为 path、type、lines（增删行数之和），前缀 - 表示降序，为空时按路径升序
+func (s *Results) Page(id string, offset, limit int, filter models.ResultFilter, sortBy string) (models.ResultPage, error) {
+	e, ok := s.get(id)
+	if !ok {
+		return models.ResultPage{}, fmt.Errorf("result not found: %s", id)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	page := models.ResultPage{ResultID: id, Offset: offset}
+	if e.spill != nil {
+		items, total, err := e.spill.page(offset, limit, filter, sortBy)
+		if err != nil {
+			return models.ResultPage{}, err
+		}
+		page.Items, page.Total = items, total
+		return page, nil
+	}
+
+	items := FilterItems(e.result.Items, filter)
+	if err := sortItems(items, sortBy); err != nil {
+		return models.ResultPage{}, err
+	}
+
+	page.Total = len(items)
+	if offset >= len(items) || limit <= 0 {
+		page.Items = []models.DiffItem{}
+		return page, nil
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	page.Items = items[offset:end]
+	return page, nil
+}
+
+// Close 删除所有结果的临时文件
+func (s *Results) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range s.order {
+		s.evict(id)
+	}
+	s.order = nil
+}
+
+// get 返回指定 ID 的结果
+func (s *Results) get(id string) (*entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.results[id]
+	return e, ok
+}
+
+// evict 丢弃结果并删除其临时文件，调用方需持有锁
+func (s *Results) evict(id string) {
+	if e, ok := s.results[id]; ok && e.spill != nil {
+		e.spill.remove()
+	}
+	delete(s.results, id)
+}
+
+// FilterItems 返回满足筛选条件的差异项
+func FilterItems(items []models.DiffItem, filter models.ResultFilter) []models.DiffItem {
+	matched := make([]models.DiffItem, 0, len(items))
+	paths := pathMatcher(filter)
+	for _, item := range items {
+		if matchFilter(item, filter, paths) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// pathMatcher 将筛选条件中的路径模式编译为匹配器，没有路径模式时返回 nil
+func pathMatcher(filter models.ResultFilter) *compare.ExcludeMatcher {
+	if len(filter.Paths) == 0 {
+		return nil
+	}
+
+	rules := make([]models.ExcludeRule, 0, len(filter.Paths))
+	for _, pattern := range filter.Paths {
+		rules = append(rules, models.ExcludeRule{Pattern: pattern, Type: "glob", Enabled: true})
+	}
+	return compare.NewExcludeMatcher(rules)
+}
+
+// matchFilter 判断差异项是否满足筛选条件，paths 为 pathMatcher 的结果
+func matchFilter(item models.DiffItem, filter models.ResultFilter, paths *compare.ExcludeMatcher) bool {
+	if len(filter.Types) > 0 {
+		matched := false
+		for _, t := range filter.Types {
+			if item.Type == t {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if filter.Query != "" && !strings.Contains(strings.ToLower(item.RelPath), strings.ToLower(filter.Query)) {
+		return false
+	}
+	if paths != nil && !paths.ShouldExclude(item.RelPath, false) {
+		return false
+	}
+	return true
+}
+
+// parseSort 解析排序参数，返回是否降序和排序字段
+func parseSort(sortBy string) (bool, string) {
+	return strings.HasPrefix(sortBy, "-"), strings.TrimPrefix(sortBy, "-")
+}
+
+// sortItems 按排序字段稳定排序
+func sortItems(items []models.DiffItem, sortBy string) error {
+	desc, field := parseSort(sortBy)
+
+	var less func(a, b models.DiffItem) bool
+	switch field {
+	case "", "path":
+		less = func(a, b models.DiffItem) bool { return a.RelPath < b.RelPath }
+	case "type":
+		less = func(a, b models.DiffItem) bool {
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+			return a.RelPath < b.RelPath
+		}
+	case "lines":
+		less = func(a, b models.DiffItem) bool {
+			return a.LinesAdded+a.LinesRemoved < b.LinesAdded+b.LinesRemoved
+		}
+	default:
+		return fmt.Errorf("unsupported sort field: %s", field)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+	return nil
+}
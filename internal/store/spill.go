@@ -0,0 +1,206 @@
+package store
+
+import (
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/tempfile"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// itemOverhead 估算单个差异项除路径字符串以外占用的内存
+const itemOverhead = 128
+
+// spillEntry 写入磁盘的差异项的索引，保留筛选和排序所需的少量字段
+type spillEntry struct {
+	offset int64
+	length int
+	typ    string
+	lines  int
+}
+
+// spillFile 写入临时文件的差异项（每行一个 JSON），按路径升序保存
+type spillFile struct {
+	path    string
+	temp    *tempfile.Manager
+	entries []spillEntry
+}
+
+// estimateSize 估算差异项列表占用的内存
+func estimateSize(items []models.DiffItem) int64 {
+	var size int64
+	for _, item := range items {
+		size += int64(itemOverhead + len(item.RelPath) + len(item.SourcePath) + len(item.OldPath))
+	}
+	return size
+}
+
+// writeSpillFile 将差异项按路径排序后写入临时文件
+func writeSpillFile(items []models.DiffItem, temp *tempfile.Manager) (*spillFile, error) {
+	sorted := make([]models.DiffItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].RelPath < sorted[j].RelPath })
+
+	file, err := temp.CreateFile("discrepancies-result-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spill file: %w", err)
+	}
+	defer file.Close()
+
+	spill := &spillFile{path: file.Name(), temp: temp, entries: make([]spillEntry, 0, len(sorted))}
+	writer := bufio.NewWriter(file)
+	var offset int64
+	for _, item := range sorted {
+		data, err := json.Marshal(item)
+		if err != nil {
+			spill.remove()
+			return nil, err
+		}
+		data = append(data, '\n')
+		if _, err := writer.Write(data); err != nil {
+			spill.remove()
+			return nil, fmt.Errorf("failed to write spill file: %w", err)
+		}
+		spill.entries = append(spill.entries, spillEntry{
+			offset: offset,
+			length: len(data),
+			typ:    item.Type,
+			lines:  item.LinesAdded + item.LinesRemoved,
+		})
+		offset += int64(len(data))
+	}
+	if err := writer.Flush(); err != nil {
+		spill.remove()
+		return nil, fmt.Errorf("failed to write spill file: %w", err)
+	}
+	return spill, nil
+}
+
+// page 按筛选和排序条件读取一页差异项，返回筛选后的总数
+// 仅筛选类型时只使用索引；按路径筛选时需要顺序读取整个文件
+func (s *spillFile) page(offset, limit int, filter models.ResultFilter, sortBy string) ([]models.DiffItem, int, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open spill file: %w", err)
+	}
+	defer file.Close()
+
+	// 筛选：只按类型筛选时使用索引，否则读取每一项判断
+	indices := make([]int, 0, len(s.entries))
+	paths := pathMatcher(filter)
+	if filter.Query == "" && paths == nil {
+		for i, e := range s.entries {
+			if matchFilter(models.DiffItem{Type: e.typ}, filter, nil) {
+				indices = append(indices, i)
+			}
+		}
+	} else {
+		decoder := json.NewDecoder(bufio.NewReader(file))
+		for i := range s.entries {
+			var item models.DiffItem
+			if err := decoder.Decode(&item); err != nil {
+				return nil, 0, fmt.Errorf("failed to read spill file: %w", err)
+			}
+			if matchFilter(item, filter, paths) {
+				indices = append(indices, i)
+			}
+		}
+	}
+
+	// 排序：文件已按路径升序保存，类型与行数使用索引中的字段
+	if err := s.sortIndices(indices, sortBy); err != nil {
+		return nil, 0, err
+	}
+
+	total := len(indices)
+	if offset >= total || limit <= 0 {
+		return []models.DiffItem{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	items := make([]models.DiffItem, 0, end-offset)
+	for _, i := range indices[offset:end] {
+		item, err := s.read(file, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+// sortIndices 按排序字段对索引稳定排序
+func (s *spillFile) sortIndices(indices []int, sortBy string) error {
+	desc, field := parseSort(sortBy)
+
+	var less func(a, b int) bool
+	switch field {
+	case "", "path":
+		less = func(a, b int) bool { return a < b }
+	case "type":
+		less = func(a, b int) bool {
+			if s.entries[a].typ != s.entries[b].typ {
+				return s.entries[a].typ < s.entries[b].typ
+			}
+			return a < b
+		}
+	case "lines":
+		less = func(a, b int) bool { return s.entries[a].lines < s.entries[b].lines }
+	default:
+		return fmt.Errorf("unsupported sort field: %s", field)
+	}
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		if desc {
+			return less(indices[j], indices[i])
+		}
+		return less(indices[i], indices[j])
+	})
+	return nil
+}
+
+// all 读取全部差异项
+func (s *spillFile) all() ([]models.DiffItem, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %w", err)
+	}
+	defer file.Close()
+
+	items := make([]models.DiffItem, 0, len(s.entries))
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for range s.entries {
+		var item models.DiffItem
+		if err := decoder.Decode(&item); err != nil {
+			return nil, fmt.Errorf("failed to read spill file: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// read 读取第 i 个差异项
+func (s *spillFile) read(file *os.File, i int) (models.DiffItem, error) {
+	e := s.entries[i]
+	data := make([]byte, e.length)
+	if _, err := file.ReadAt(data, e.offset); err != nil && err != io.EOF {
+		return models.DiffItem{}, fmt.Errorf("failed to read spill file: %w", err)
+	}
+
+	var item models.DiffItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return models.DiffItem{}, fmt.Errorf("failed to read spill file: %w", err)
+	}
+	return item, nil
+}
+
+// remove 删除临时文件
+func (s *spillFile) remove() {
+	s.temp.Release(s.path)
+}
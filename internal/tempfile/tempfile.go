@@ -0,0 +1,177 @@
+// Package tempfile 管理预览、导出等操作创建的临时文件，操作完成和程序退出时清理
+package tempfile
+
+import (
+	"Discrepancies/internal/models"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Manager 统一管理程序创建的临时文件和目录
+// 所有临时文件放在同一个目录下并记录为使用中，操作完成后调用 Release 删除；
+// Cleanup 删除不在使用中的内容（如上次异常退出遗留的文件），Close 在退出时删除全部内容
+// nil 的 Manager 可以使用，此时在系统临时目录中创建，不做跟踪
+type Manager struct {
+	dir string
+
+	mu    sync.Mutex
+	inUse map[string]bool
+}
+
+// NewManager 创建临时文件管理器，dir 为存放临时文件的目录，不存在时在首次创建临时文件时创建
+func NewManager(dir string) *Manager {
+	return &Manager{dir: dir, inUse: make(map[string]bool)}
+}
+
+// Dir 返回存放临时文件的目录
+func (m *Manager) Dir() string {
+	if m == nil {
+		return os.TempDir()
+	}
+	return m.dir
+}
+
+// CreateFile 创建临时文件并记录为使用中，pattern 同 os.CreateTemp
+func (m *Manager) CreateFile(pattern string) (*os.File, error) {
+	if m == nil {
+		return os.CreateTemp("", pattern)
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	file, err := os.CreateTemp(m.dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	m.track(file.Name())
+	return file, nil
+}
+
+// MkdirTemp 创建临时目录并记录为使用中，pattern 同 os.MkdirTemp
+func (m *Manager) MkdirTemp(pattern string) (string, error) {
+	if m == nil {
+		return os.MkdirTemp("", pattern)
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	dir, err := os.MkdirTemp(m.dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	m.track(dir)
+	return dir, nil
+}
+
+// Release 删除临时文件或目录（含其中的内容）并停止跟踪，路径已不存在时不返回错误
+func (m *Manager) Release(path string) error {
+	if m != nil {
+		m.mu.Lock()
+		delete(m.inUse, path)
+		m.mu.Unlock()
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove temp file: %w", err)
+	}
+	return nil
+}
+
+// Usage 统计临时目录中的文件数和总大小，InUse 为使用中的临时文件和目录数
+func (m *Manager) Usage() (models.TempUsage, error) {
+	usage := models.TempUsage{Dir: m.Dir()}
+	if m == nil {
+		return usage, nil
+	}
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return usage, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+	for _, entry := range entries {
+		files, bytes := du(filepath.Join(m.dir, entry.Name()))
+		usage.Files += files
+		usage.Bytes += bytes
+	}
+
+	m.mu.Lock()
+	usage.InUse = len(m.inUse)
+	m.mu.Unlock()
+	return usage, nil
+}
+
+// Cleanup 删除临时目录中不在使用中的内容，返回删除的文件数和大小；删除失败的项跳过，返回第一个错误
+func (m *Manager) Cleanup() (models.TempUsage, error) {
+	return m.removeAll(false)
+}
+
+// Close 删除临时目录中的全部内容（包括使用中的），在程序退出时调用
+func (m *Manager) Close() error {
+	_, err := m.removeAll(true)
+	return err
+}
+
+// track 记录使用中的临时文件
+func (m *Manager) track(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inUse[path] = true
+}
+
+// removeAll 删除临时目录中的内容，all 为 false 时跳过使用中的
+func (m *Manager) removeAll(all bool) (models.TempUsage, error) {
+	freed := models.TempUsage{Dir: m.Dir()}
+	if m == nil {
+		return freed, nil
+	}
+
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return freed, nil
+		}
+		return freed, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var firstErr error
+	for _, entry := range entries {
+		path := filepath.Join(m.dir, entry.Name())
+		if m.inUse[path] && !all {
+			freed.InUse++
+			continue
+		}
+		files, bytes := du(path)
+		if err := os.RemoveAll(path); err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to remove temp file: %w", err)
+			}
+			continue
+		}
+		delete(m.inUse, path)
+		freed.Files += files
+		freed.Bytes += bytes
+	}
+	return freed, firstErr
+}
+
+// du 统计路径（文件或目录）中的文件数和总大小，无法访问的部分不计入
+func du(path string) (int, int64) {
+	var files int
+	var bytes int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files++
+			bytes += info.Size()
+		}
+		return nil
+	})
+	return files, bytes
+}
@@ -0,0 +1,128 @@
+package tmpl
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 生成内容（导出文件名、报告标题、提交信息等）使用的模板，可在配置中修改
+// 模板有两种写法：
+//   - 简单占位符：{name}、{date}、{date:iso}、{time}、{env:变量名}，引用的变量为空时报错
+//   - 包含 {{ 时按 text/template 解析：{{.name}}、{{date "iso"}}、{{env "BUILD_NUMBER"}}、{{upper .base}}
+
+// 日期格式预设，其他值按 Go 的时间格式（如 2006.01.02）解释
+const (
+	DateFormatChinese = "chinese" // 2006年01月02日（默认）
+	DateFormatISO     = "iso"     // 2006-01-02（ISO 8601）
+	DateFormatCompact = "compact" // 20060102
+)
+
+// dateLayouts 日期格式预设对应的 Go 时间格式
+var dateLayouts = map[string]string{
+	DateFormatChinese: "2006年01月02日",
+	DateFormatISO:     "2006-01-02",
+	DateFormatCompact: "20060102",
+}
+
+// ErrTemplate 模板语法错误，或引用了未知的占位符、为空的变量
+var ErrTemplate = errors.New("invalid template")
+
+// Context 渲染模板使用的数据
+type Context struct {
+	Vars       map[string]string // 变量，简单占位符中以 {name} 引用，text/template 中以 {{.name}} 引用
+	Time       time.Time         // {date}、{time} 使用的时间，为零值时为当前时间
+	DateFormat string            // {date} 的格式，见 DateLayout
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// DateLayout 返回日期格式对应的 Go 时间格式，为空时为中文格式
+func DateLayout(format string) string {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		return dateLayouts[DateFormatChinese]
+	}
+	if layout, ok := dateLayouts[strings.ToLower(format)]; ok {
+		return layout
+	}
+	return format
+}
+
+// Render 渲染模板
+func Render(text string, ctx Context) (string, error) {
+	if ctx.Time.IsZero() {
+		ctx.Time = time.Now()
+	}
+	if strings.Contains(text, "{{") {
+		return renderTemplate(text, ctx)
+	}
+	return renderPlaceholders(text, ctx)
+}
+
+// renderPlaceholders 替换简单占位符
+func renderPlaceholders(text string, ctx Context) (string, error) {
+	var missing []string
+	rendered := placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		key := match[1 : len(match)-1]
+		value, ok := lookup(key, ctx)
+		if !ok || strings.TrimSpace(value) == "" {
+			missing = append(missing, match)
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrTemplate, strings.Join(missing, ", "))
+	}
+	return rendered, nil
+}
+
+// lookup 返回简单占位符的值
+func lookup(key string, ctx Context) (string, bool) {
+	switch {
+	case key == "date":
+		return ctx.Time.Format(DateLayout(ctx.DateFormat)), true
+	case strings.HasPrefix(key, "date:"):
+		return ctx.Time.Format(DateLayout(strings.TrimPrefix(key, "date:"))), true
+	case key == "time":
+		return ctx.Time.Format("150405"), true
+	case strings.HasPrefix(key, "env:"):
+		return os.Getenv(strings.TrimPrefix(key, "env:")), true
+	}
+	value, ok := ctx.Vars[key]
+	return value, ok
+}
+
+// renderTemplate 按 text/template 渲染，引用不存在的变量时报错
+func renderTemplate(text string, ctx Context) (string, error) {
+	funcs := template.FuncMap{
+		"date":    func(format string) string { return ctx.Time.Format(DateLayout(format)) },
+		"env":     os.Getenv,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"trim":    strings.TrimSpace,
+	}
+	t, err := template.New("").Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTemplate, err)
+	}
+
+	data := map[string]string{
+		"date": ctx.Time.Format(DateLayout(ctx.DateFormat)),
+		"time": ctx.Time.Format("150405"),
+	}
+	for k, v := range ctx.Vars {
+		data[k] = v
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTemplate, err)
+	}
+	return sb.String(), nil
+}
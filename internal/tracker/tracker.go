@@ -0,0 +1,136 @@
+package tracker
+
+import (
+	"Discrepancies/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 支持的问题跟踪系统
+const (
+	TypeJira    = "jira"
+	TypeRedmine = "redmine"
+)
+
+// requestTimeout 查询单个工单的超时时间
+const requestTimeout = 10 * time.Second
+
+// ErrNotConfigured 未配置问题跟踪系统
+var ErrNotConfigured = errors.New("issue tracker not configured")
+
+// Normalize 整理用户输入的工单号：去掉空白和 Redmine 工单号前的 #，去重并保持顺序
+func Normalize(ids []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for _, part := range strings.FieldsFunc(id, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			part = strings.TrimPrefix(part, "#")
+			if part != "" && !seen[part] {
+				seen[part] = true
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// Link 返回工单在问题跟踪系统中的网页地址，未配置时返回空字符串
+func Link(cfg models.IssueTrackerConfig, id string) string {
+	base := strings.TrimRight(cfg.BaseURL, "/")
+	if base == "" {
+		return ""
+	}
+	switch cfg.Type {
+	case TypeJira:
+		return base + "/browse/" + url.PathEscape(id)
+	case TypeRedmine:
+		return base + "/issues/" + url.PathEscape(id)
+	}
+	return ""
+}
+
+// Resolve 为工单号生成引用，配置了问题跟踪系统时通过 REST API 查询标题
+// 查询失败的工单只有编号和链接，返回第一个查询错误
+func Resolve(ctx context.Context, cfg models.IssueTrackerConfig, ids []string) ([]models.TicketRef, error) {
+	refs := make([]models.TicketRef, 0, len(ids))
+	var firstErr error
+	for _, id := range Normalize(ids) {
+		ref := models.TicketRef{ID: id, URL: Link(cfg, id)}
+		if cfg.ResolveTitles {
+			title, err := fetchTitle(ctx, cfg, id)
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("failed to resolve ticket %s: %w", id, err)
+			}
+			ref.Title = title
+		}
+		refs = append(refs, ref)
+	}
+	return refs, firstErr
+}
+
+// fetchTitle 查询工单标题
+func fetchTitle(ctx context.Context, cfg models.IssueTrackerConfig, id string) (string, error) {
+	base := strings.TrimRight(cfg.BaseURL, "/")
+	if base == "" {
+		return "", ErrNotConfigured
+	}
+
+	var endpoint string
+	switch cfg.Type {
+	case TypeJira:
+		endpoint = base + "/rest/api/2/issue/" + url.PathEscape(id) + "?fields=summary"
+	case TypeRedmine:
+		endpoint = base + "/issues/" + url.PathEscape(id) + ".json"
+	default:
+		return "", fmt.Errorf("unsupported issue tracker: %s", cfg.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case cfg.Type == TypeRedmine && cfg.Token != "":
+		req.Header.Set("X-Redmine-API-Key", cfg.Token)
+	case cfg.User != "":
+		// Jira Cloud：邮箱 + API token
+		req.SetBasicAuth(cfg.User, cfg.Token)
+	case cfg.Token != "":
+		// Jira Server/Data Center：个人访问令牌
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	var body struct {
+		Fields struct {
+			Summary string `json:"summary"`
+		} `json:"fields"`
+		Issue struct {
+			Subject string `json:"subject"`
+		} `json:"issue"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if cfg.Type == TypeJira {
+		return body.Fields.Summary, nil
+	}
+	return body.Issue.Subject, nil
+}
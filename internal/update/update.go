@@ -0,0 +1,316 @@
+// Package update 检查发布源中的新版本，并可下载安装包暂存到本地
+// 发布源是一个 JSON 文件，描述最新版本及各平台的安装包地址和 SHA-256
+// 发布源和安装包只通过 https 获取；安装包需附带由受信任公钥生成的分离签名（安装包地址加 .sig），校验通过才暂存
+package update
+
+import (
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/signing"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version 当前版本，构建时通过 -ldflags "-X Discrepancies/internal/update.Version=1.2.3" 设置
+// 开发构建为 dev，不提示更新
+var Version = "dev"
+
+// checkTimeout 获取发布源的超时时间
+const checkTimeout = 15 * time.Second
+
+// maxFeedSize 发布源文件的大小上限
+const maxFeedSize = 1 << 20
+
+// ErrNoAsset 发布源中没有当前平台的安装包
+var ErrNoAsset = errors.New("no update package for this platform")
+
+// maxSignatureSize 签名文件的大小上限
+const maxSignatureSize = 4096
+
+// ErrChecksum 下载的安装包与发布源中的 SHA-256 不一致
+var ErrChecksum = errors.New("update package checksum mismatch")
+
+// ErrInsecureURL 发布源或安装包地址（含重定向）不是 https
+var ErrInsecureURL = errors.New("update urls must use https")
+
+// ErrNoTrustedKeys 没有可用于校验安装包签名的公钥，不下载安装包
+var ErrNoTrustedKeys = errors.New("no trusted signing keys for update packages")
+
+// client 下载发布源和安装包的 HTTP 客户端，拒绝重定向到非 https 地址
+var client = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return requireHTTPS(req.URL)
+	},
+}
+
+// Release 发布源描述的最新版本
+type Release struct {
+	Version string           `json:"version"` // 版本号，如 1.4.0
+	Notes   string           `json:"notes"`   // 更新说明
+	URL     string           `json:"url"`     // 发布页面地址
+	Assets  map[string]Asset `json:"assets"`  // 各平台的安装包，键为 GOOS/GOARCH，如 windows/amd64
+}
+
+// Asset 一个平台的安装包
+type Asset struct {
+	URL    string `json:"url"`    // 下载地址
+	SHA256 string `json:"sha256"` // 安装包的 SHA-256（十六进制）
+}
+
+// Check 获取发布源，返回最新版本信息；当前为开发构建或版本号无法解析时不视为有更新
+func Check(ctx context.Context, feedURL string) (*models.UpdateInfo, *Release, error) {
+	release, err := fetchRelease(ctx, feedURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := &models.UpdateInfo{
+		Current: Version,
+		Latest:  release.Version,
+		Notes:   release.Notes,
+		URL:     release.URL,
+	}
+	if cmp, ok := compareVersions(release.Version, Version); ok && cmp > 0 {
+		info.Available = true
+	}
+	return info, release, nil
+}
+
+// fetchRelease 下载并解析发布源
+func fetchRelease(ctx context.Context, feedURL string) (*Release, error) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release feed url: %w", err)
+	}
+	if err := requireHTTPS(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch release feed: %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxFeedSize)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release feed: %w", err)
+	}
+	if strings.TrimSpace(release.Version) == "" {
+		return nil, fmt.Errorf("failed to parse release feed: missing version")
+	}
+	return &release, nil
+}
+
+// Stage 下载当前平台的安装包到 dir/<版本号>/，校验 SHA-256 和 publicKeys（PEM）中任一公钥生成的签名，返回安装包路径
+// 签名保存在安装包旁；已下载且校验一致时直接返回，不重复下载
+func Stage(ctx context.Context, release *Release, dir string, publicKeys []string) (string, error) {
+	if len(publicKeys) == 0 {
+		return "", ErrNoTrustedKeys
+	}
+	asset, ok := release.Assets[runtime.GOOS+"/"+runtime.GOARCH]
+	if !ok || asset.URL == "" {
+		return "", ErrNoAsset
+	}
+	u, err := url.Parse(asset.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid update package url: %w", err)
+	}
+	if err := requireHTTPS(u); err != nil {
+		return "", err
+	}
+	name := path.Base(u.Path)
+	if name == "" || name == "/" || name == "." {
+		name = "Discrepancies-" + release.Version
+	}
+	target := filepath.Join(dir, safeName(release.Version), safeName(name))
+	if sum, err := fileSHA256(target); err == nil && strings.EqualFold(sum, asset.SHA256) &&
+		signing.VerifyFile(target, publicKeys) == nil {
+		return target, nil
+	}
+
+	// 签名与安装包在同一位置，地址为安装包地址加 .sig
+	sigURL := *u
+	sigURL.Path += signing.SignatureExt
+	sigURL.RawPath = ""
+	sigResp, err := download(ctx, sigURL.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to download update signature: %w", err)
+	}
+	sigData, err := io.ReadAll(io.LimitReader(sigResp.Body, maxSignatureSize))
+	sigResp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to download update signature: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	resp, err := download(ctx, asset.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download update: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 先写入临时文件，校验通过后再改名，避免留下不完整的安装包
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download update: %w", err)
+	}
+	if !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), asset.SHA256) {
+		return "", ErrChecksum
+	}
+	if err := signing.VerifySignature(tmp.Name(), sigData, publicKeys); err != nil {
+		return "", fmt.Errorf("failed to verify update signature: %w", err)
+	}
+	if err := os.WriteFile(target+signing.SignatureExt, sigData, 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// download 发起 GET 请求，状态不是 200 时返回错误
+func download(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.New(resp.Status)
+	}
+	return resp, nil
+}
+
+// requireHTTPS 检查地址使用 https
+func requireHTTPS(u *url.URL) error {
+	if !strings.EqualFold(u.Scheme, "https") {
+		return fmt.Errorf("%w: %s", ErrInsecureURL, u.Redacted())
+	}
+	return nil
+}
+
+// safeName 将发布源中的版本号、文件名转换为安全的单级文件名，避免路径穿越
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
+	}
+	return name
+}
+
+// fileSHA256 计算文件的 SHA-256
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// compareVersions 比较两个点分数字版本号（可带 v 前缀，- 之后的预发布标识参与比较：有标识的版本较低）
+// 任一版本号无法解析时 ok 为 false
+func compareVersions(a, b string) (cmp int, ok bool) {
+	pa, preA, okA := parseVersion(a)
+	pb, preB, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x > y {
+				return 1, true
+			}
+			return -1, true
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0, true
+	case preA == "":
+		return 1, true
+	case preB == "":
+		return -1, true
+	case preA > preB:
+		return 1, true
+	}
+	return -1, true
+}
+
+// parseVersion 解析版本号，返回数字部分和预发布标识
+func parseVersion(v string) ([]int, string, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, pre, _ := strings.Cut(v, "-")
+	v, _, _ = strings.Cut(v, "+")
+	if v == "" {
+		return nil, "", false
+	}
+	parts := strings.Split(v, ".")
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, "", false
+		}
+		nums[i] = n
+	}
+	return nums, pre, true
+}
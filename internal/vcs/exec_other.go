@@ -0,0 +1,8 @@
+//go:build !windows
+
+package vcs
+
+import "os/exec"
+
+// hideWindow 仅在 Windows 上需要隐藏控制台窗口
+func hideWindow(cmd *exec.Cmd) {}
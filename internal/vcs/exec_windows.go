@@ -0,0 +1,13 @@
+//go:build windows
+
+package vcs
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideWindow 避免在 GUI 程序中调用命令行工具时弹出控制台窗口
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
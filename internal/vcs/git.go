@@ -0,0 +1,298 @@
+package vcs
+
+import (
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/tmpl"
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runGit 在指定目录执行 git 命令并返回标准输出
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	hideWindow(cmd)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return nil, fmt.Errorf("git %s failed: %w", args[0], err)
+		}
+		return nil, fmt.Errorf("git %s failed: %s", args[0], msg)
+	}
+	return out, nil
+}
+
+// IsGitRepo 判断目录是否位于 git 工作区中
+func IsGitRepo(dir string) bool {
+	out, err := runGit(dir, "rev-parse", "--is-inside-work-tree")
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// Blame 获取文件每一行最后一次修改的提交信息，键为从 1 开始的行号
+func Blame(dir, relPath string) (map[int]models.LineBlame, error) {
+	out, err := runGit(dir, "blame", "--line-porcelain", "--", filepath.FromSlash(relPath))
+	if err != nil {
+		return nil, err
+	}
+	return parseBlamePorcelain(out), nil
+}
+
+// parseBlamePorcelain 解析 git blame --line-porcelain 的输出
+func parseBlamePorcelain(out []byte) map[int]models.LineBlame {
+	result := make(map[int]models.LineBlame)
+
+	var cur models.LineBlame
+	line := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// 行内容，表示一条记录结束
+			if line > 0 {
+				result[line] = cur
+			}
+			cur = models.LineBlame{}
+			line = 0
+		case strings.HasPrefix(text, "author "):
+			cur.Author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-time "):
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64); err == nil {
+				cur.Date = time.Unix(sec, 0).Format("2006-01-02 15:04")
+			}
+		case line == 0:
+			// 记录头：<commit> <原始行号> <最终行号> [<行数>]
+			fields := strings.Fields(text)
+			if len(fields) >= 3 && len(fields[0]) == 40 {
+				cur.Commit = fields[0][:8]
+				line, _ = strconv.Atoi(fields[2])
+			}
+		}
+	}
+
+	return result
+}
+
+// DefaultCommitMessage 默认的导出提交信息模板
+const DefaultCommitMessage = "导出差异文件（基准: {baseline}，共 {count} 个文件）"
+
+// RenderCommitMessage 渲染提交信息模板，支持 {baseline}、{count} 占位符和 tmpl 包的其他写法
+func RenderCommitMessage(text, baseline string, count int) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultCommitMessage
+	}
+	return tmpl.Render(text, tmpl.Context{Vars: map[string]string{
+		"baseline": baseline,
+		"count":    strconv.Itoa(count),
+	}})
+}
+
+// CommitFiles 暂存并提交指定文件，paths 为仓库内文件的绝对路径
+// 文件与 HEAD 相同（没有可提交的内容）时不创建提交，返回 false
+func CommitFiles(dir string, paths []string, message string) (bool, error) {
+	if len(paths) == 0 {
+		return false, nil
+	}
+
+	args := append([]string{"add", "--"}, paths...)
+	if _, err := runGit(dir, args...); err != nil {
+		return false, err
+	}
+
+	// diff --cached --quiet 在没有差异时返回 0
+	args = append([]string{"diff", "--cached", "--quiet", "--"}, paths...)
+	if _, err := runGit(dir, args...); err == nil {
+		return false, nil
+	}
+
+	args = append([]string{"commit", "-m", message, "--"}, paths...)
+	if _, err := runGit(dir, args...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Change 表示 git diff 得到的一个文件变更
+type Change struct {
+	Status       string // "added" | "modified" | "deleted" | "renamed"
+	Path         string // 相对于工作目录的路径（正斜杠）
+	OldPath      string // 重命名前的路径
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// ResolveRef 校验引用（标签、分支或提交）是否存在，返回对应的提交哈希
+func ResolveRef(dir, ref string) (string, error) {
+	out, err := runGit(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("git ref not found: %s", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ShowFile 读取文件在引用中的内容，relPath 相对于 dir
+func ShowFile(dir, ref, relPath string) ([]byte, error) {
+	return runGit(dir, "show", ref+":./"+filepath.ToSlash(relPath))
+}
+
+// TreeSizes 返回引用中各文件的大小，路径相对于 dir（正斜杠）
+func TreeSizes(dir, ref string) (map[string]int64, error) {
+	out, err := runGit(dir, "ls-tree", "-r", "-l", "-z", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]int64)
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// <mode> <type> <object> <size>\t<path>
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 4 {
+			continue
+		}
+		if size, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			sizes[path] = size
+		}
+	}
+	return sizes, nil
+}
+
+// FindTag 查找与基准名称对应的标签
+// 优先完全匹配，其次选择作为名称后缀的最长标签（如 project-v1.0 对应 v1.0）
+func FindTag(dir, name string) (string, error) {
+	out, err := runGit(dir, "tag", "--list")
+	if err != nil {
+		return "", err
+	}
+
+	best := ""
+	for _, tag := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if tag == name {
+			return tag, nil
+		}
+		if strings.HasSuffix(name, tag) && len(tag) > len(best) {
+			best = tag
+		}
+	}
+	return best, nil
+}
+
+// DiffWorkTree 比较引用与当前工作区（含未提交和未跟踪的文件），支持重命名检测
+// 路径相对于 dir，dir 可以是仓库的子目录
+func DiffWorkTree(dir, ref string) ([]Change, error) {
+	out, err := runGit(dir, "diff", "--name-status", "-z", "-M", "--relative", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes := parseNameStatus(out)
+
+	// 行数统计
+	out, err = runGit(dir, "diff", "--numstat", "-z", "-M", "--relative", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	stats := parseNumstat(out)
+	for i := range changes {
+		if stat, ok := stats[changes[i].Path]; ok {
+			changes[i].LinesAdded, changes[i].LinesRemoved = stat[0], stat[1]
+		}
+	}
+
+	// 未跟踪的文件视为新增
+	out, err = runGit(dir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			changes = append(changes, Change{Status: "added", Path: path})
+		}
+	}
+
+	return changes, nil
+}
+
+// parseNameStatus 解析 git diff --name-status -z 的输出
+func parseNameStatus(out []byte) []Change {
+	changes := make([]Change, 0)
+	fields := strings.Split(string(out), "\x00")
+
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" || i+1 >= len(fields) {
+			continue
+		}
+
+		switch status[0] {
+		case 'R', 'C':
+			// 重命名和复制带有旧路径和新路径两个字段
+			if i+2 >= len(fields) {
+				return changes
+			}
+			change := Change{Status: "renamed", Path: fields[i+2], OldPath: fields[i+1]}
+			if status[0] == 'C' {
+				change = Change{Status: "added", Path: fields[i+2]}
+			}
+			changes = append(changes, change)
+			i += 2
+		case 'A':
+			changes = append(changes, Change{Status: "added", Path: fields[i+1]})
+			i++
+		case 'D':
+			changes = append(changes, Change{Status: "deleted", Path: fields[i+1]})
+			i++
+		default:
+			// M（修改）、T（类型变化）等
+			changes = append(changes, Change{Status: "modified", Path: fields[i+1]})
+			i++
+		}
+	}
+
+	return changes
+}
+
+// parseNumstat 解析 git diff --numstat -z 的输出，返回路径到 [新增, 删除] 行数的映射
+// 二进制文件的行数为 "-"，统计为 0
+func parseNumstat(out []byte) map[string][2]int {
+	stats := make(map[string][2]int)
+	fields := strings.Split(string(out), "\x00")
+
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		added, _ := strconv.Atoi(parts[0])
+		removed, _ := strconv.Atoi(parts[1])
+
+		path := parts[2]
+		if path == "" {
+			// 重命名：随后是旧路径和新路径
+			if i+2 >= len(fields) {
+				break
+			}
+			path = fields[i+2]
+			i += 2
+		}
+		stats[path] = [2]int{added, removed}
+	}
+
+	return stats
+}
@@ -0,0 +1,91 @@
+package vcs
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// IsSvnWorkingCopy 判断目录是否位于 SVN 工作副本中（向上查找 .svn 目录）
+func IsSvnWorkingCopy(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(dir, ".svn")); err == nil && info.IsDir() {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// svnStatus 对应 svn status --xml 的输出结构
+type svnStatus struct {
+	Targets []struct {
+		Entries []struct {
+			Path     string `xml:"path,attr"`
+			WcStatus struct {
+				Item string `xml:"item,attr"`
+			} `xml:"wc-status"`
+		} `xml:"entry"`
+	} `xml:"target"`
+}
+
+// UnversionedSet 未纳入版本控制的路径集合，未版本控制目录下的所有文件同样视为未版本控制
+type UnversionedSet map[string]bool
+
+// Contains 判断相对路径是否未纳入版本控制
+func (s UnversionedSet) Contains(relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for {
+		if s[relPath] {
+			return true
+		}
+		i := strings.LastIndex(relPath, "/")
+		if i < 0 {
+			return false
+		}
+		relPath = relPath[:i]
+	}
+}
+
+// SvnUnversioned 通过 svn status 获取工作目录中未纳入版本控制的文件和目录
+func SvnUnversioned(dir string) (UnversionedSet, error) {
+	cmd := exec.Command("svn", "status", "--xml", "--non-interactive")
+	cmd.Dir = dir
+	hideWindow(cmd)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("svn status failed: %s", msg)
+		}
+		return nil, fmt.Errorf("svn status failed: %w", err)
+	}
+
+	var status svnStatus
+	if err := xml.Unmarshal(out, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse svn status: %w", err)
+	}
+
+	set := make(UnversionedSet)
+	for _, target := range status.Targets {
+		for _, entry := range target.Entries {
+			if entry.WcStatus.Item == "unversioned" {
+				set[filepath.ToSlash(entry.Path)] = true
+			}
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/logger"
+)
+
+// fileLogger 将日志带时间戳追加到日志文件，同时输出到控制台
+type fileLogger struct {
+	mu      sync.Mutex
+	file    *os.File
+	console logger.Logger
+}
+
+// newFileLogger 打开（追加）日志文件，打开失败时返回错误
+func newFileLogger(path string) (*fileLogger, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return &fileLogger{file: file, console: logger.NewDefaultLogger()}, nil
+}
+
+// write 写入一行日志，写入失败时忽略（不影响应用运行）
+func (l *fileLogger) write(level, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.file, "%s %s | %s\n", time.Now().Format("2006-01-02 15:04:05.000"), level, message)
+}
+
+func (l *fileLogger) Print(message string) {
+	l.write("INFO ", message)
+	l.console.Print(message)
+}
+
+func (l *fileLogger) Trace(message string) {
+	l.write("TRACE", message)
+	l.console.Trace(message)
+}
+
+func (l *fileLogger) Debug(message string) {
+	l.write("DEBUG", message)
+	l.console.Debug(message)
+}
+
+func (l *fileLogger) Info(message string) {
+	l.write("INFO ", message)
+	l.console.Info(message)
+}
+
+func (l *fileLogger) Warning(message string) {
+	l.write("WARN ", message)
+	l.console.Warning(message)
+}
+
+func (l *fileLogger) Error(message string) {
+	l.write("ERROR", message)
+	l.console.Error(message)
+}
+
+func (l *fileLogger) Fatal(message string) {
+	l.write("FATAL", message)
+	l.console.Fatal(message)
+}
+
+// parseLogLevel 将配置中的日志级别转换为 Wails 日志级别，为空或无效时为 info
+func parseLogLevel(level string) logger.LogLevel {
+	if lvl, err := logger.StringToLogLevel(level); err == nil {
+		return lvl
+	}
+	return logger.INFO
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"Discrepancies/internal/apperr"
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/ipc"
 	"embed"
+	"os"
+	"slices"
 
 	"github.com/wailsapp/wails/v2"
+	"github.com/wailsapp/wails/v2/pkg/logger"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/options/mac"
 	"github.com/wailsapp/wails/v2/pkg/options/windows"
 )
 
@@ -13,8 +20,55 @@ import (
 var assets embed.FS
 
 func main() {
+	// 以管理员身份重新运行的导出任务，不启动界面
+	if len(os.Args) == 3 && os.Args[1] == elevatedExportFlag {
+		os.Exit(runElevatedExport(os.Args[2]))
+	}
+
+	// 安装脚本注册或取消注册 discrepancies:// 协议和 ZIP 右键菜单，不启动界面
+	if len(os.Args) == 2 && (os.Args[1] == registerShellFlag || os.Args[1] == unregisterShellFlag) {
+		os.Exit(runShellFlag(os.Args[1]))
+	}
+
+	// 便携模式：配置保存在可执行文件所在目录，需在读取配置之前设置
+	if slices.Contains(os.Args[1:], config.PortableFlag) {
+		config.SetPortable(true)
+	}
+
+	// 只读模式：不写入配置、日志、临时文件，也不与其他实例通信（转交参数会在可写的实例中打开）
+	if slices.Contains(os.Args[1:], config.ReadOnlyFlag) || config.ReadOnlyConfigured() {
+		config.SetReadOnly(true)
+	}
+
+	// 已有实例在运行时，将参数转交给它后退出，避免打开第二个窗口
+	cwd, _ := os.Getwd()
+	socketPath, socketErr := config.InstanceSocketPath()
+	if config.ReadOnly() {
+		socketErr = config.ErrReadOnly
+	}
+	if socketErr == nil {
+		if err := ipc.Send(socketPath, ipc.Message{Args: os.Args[1:], WorkDir: cwd}); err == nil {
+			return
+		}
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
+	app.launch = parseOpenArgs(os.Args[1:], cwd)
+
+	if socketErr == nil {
+		if server, err := ipc.Listen(socketPath, app.handleSecondInstance); err == nil {
+			defer server.Close()
+		}
+	}
+
+	// 日志写入配置目录下的日志文件（只读模式下只输出到控制台），级别在启动后按配置设置
+	var appLogger logger.Logger
+	if logPath, err := config.LogFilePath(); err == nil && !config.ReadOnly() {
+		if fl, err := newFileLogger(logPath); err == nil {
+			appLogger = fl
+		}
+	}
 
 	// Create application with options
 	err := wails.Run(&options.App{
@@ -26,8 +80,13 @@ func main() {
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
-		BackgroundColour: &options.RGBA{R: 255, G: 255, B: 255, A: 1},
-		OnStartup:        app.startup,
+		BackgroundColour:   &options.RGBA{R: 255, G: 255, B: 255, A: 1},
+		Logger:             appLogger,
+		LogLevel:           logger.INFO,
+		LogLevelProduction: logger.INFO,
+		OnStartup:          app.startup,
+		OnShutdown:         app.shutdown,
+		ErrorFormatter:     apperr.Format,
 		Bind: []interface{}{
 			app,
 		},
@@ -36,6 +95,10 @@ func main() {
 			WindowIsTranslucent:  false,
 			DisableWindowIcon:    false,
 		},
+		// macOS 通过系统事件传入 discrepancies:// 链接，协议在 Info.plist 中声明
+		Mac: &mac.Options{
+			OnUrlOpen: app.handleOpenURL,
+		},
 	})
 
 	if err != nil {
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"Discrepancies/internal/apperr"
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/report"
+	"bytes"
+	"errors"
+	"os"
+	"time"
+)
+
+// TakeSnapshot 记录工作目录中所有文件的 SHA-256 作为快照（保存在配置目录中，覆盖该目录上一次的快照），之后可用 CompareSnapshot 查看此后的变化
+// 遵循排除规则、.gitignore 和扫描层数设置
+func (a *App) TakeSnapshot(workDir string) (models.SnapshotInfo, error) {
+	if config.ReadOnly() {
+		return models.SnapshotInfo{}, apperr.ErrReadOnly.WithMessage("只读模式下不能记录快照")
+	}
+	if workDir == "" {
+		return models.SnapshotInfo{}, apperr.ErrInvalidArgument.WithMessage("请选择工作目录")
+	}
+	if info, err := os.Stat(workDir); err != nil || !info.IsDir() {
+		return models.SnapshotInfo{}, apperr.ErrWorkDirMissing.WithDetail(workDir)
+	}
+	snapshotPath, err := config.SnapshotFilePath(workDir)
+	if err != nil {
+		return models.SnapshotInfo{}, appError(err)
+	}
+
+	if _, err := a.newComparer("", workDir, nil).Snapshot(snapshotPath); err != nil {
+		return models.SnapshotInfo{}, appError(err)
+	}
+	if a.configMgr != nil {
+		a.configMgr.AddRecentWorkDir(workDir)
+	}
+	return a.GetSnapshot(workDir)
+}
+
+// GetSnapshot 返回工作目录最近一次记录的快照，没有快照时 Path 为空
+func (a *App) GetSnapshot(workDir string) (models.SnapshotInfo, error) {
+	snapshotPath, err := config.SnapshotFilePath(workDir)
+	if err != nil {
+		return models.SnapshotInfo{}, appError(err)
+	}
+	data, err := os.ReadFile(snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return models.SnapshotInfo{}, nil
+	}
+	if err != nil {
+		return models.SnapshotInfo{}, appError(err)
+	}
+	info, err := os.Stat(snapshotPath)
+	if err != nil {
+		return models.SnapshotInfo{}, appError(err)
+	}
+	return models.SnapshotInfo{
+		Path:    snapshotPath,
+		Files:   bytes.Count(data, []byte("\n")),
+		TakenAt: info.ModTime().Format(time.RFC3339),
+	}, nil
+}
+
+// CompareSnapshot 以 TakeSnapshot 记录的快照为基准比较工作目录，不需要 ZIP 文件
+// 快照中没有文件内容，修改的文件不能预览差异；subPath 的含义与 CompareWithOptions 相同
+func (a *App) CompareSnapshot(workDir, subPath string, sessionRules []models.ExcludeRule) (*models.CompareResult, error) {
+	if workDir == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("请选择工作目录")
+	}
+	if info, err := os.Stat(workDir); err != nil || !info.IsDir() {
+		return nil, apperr.ErrWorkDirMissing.WithDetail(workDir)
+	}
+	snapshot, err := a.GetSnapshot(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Path == "" {
+		return nil, apperr.ErrNoSnapshot.WithDetail(workDir)
+	}
+
+	start := time.Now()
+	comparer := a.newComparer("", workDir, sessionRules)
+	if err := comparer.SetSubPath(subPath); err != nil {
+		return nil, apperr.ErrInvalidArgument.WithMessage("子目录必须是工作目录下的相对路径").WithDetail(subPath)
+	}
+	result, err := comparer.CompareSnapshot(snapshot.Path)
+	if err != nil {
+		return nil, appError(err)
+	}
+
+	takenAt, _ := time.Parse(time.RFC3339, snapshot.TakenAt)
+	a.loadReviews(snapshot.Path, workDir, result)
+	meta := report.Meta{Baseline: "快照 " + takenAt.Format("2006-01-02 15:04"), WorkDir: workDir}
+	a.setLastResult(result, meta, snapshot.Path)
+	a.emitCompareComplete("snapshot", meta, result, comparer.Stats(), start)
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"Discrepancies/internal/apperr"
+	"Discrepancies/internal/config"
+	"Discrepancies/internal/models"
+	"Discrepancies/internal/update"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// updateCheckDelay 启动后延迟检查更新，避免拖慢启动
+const updateCheckDelay = 10 * time.Second
+
+// GetVersion 返回当前版本
+func (a *App) GetVersion() string {
+	return update.Version
+}
+
+// CheckForUpdates 立即检查发布源中的新版本，有新版本时同时发送 backend:update 事件
+func (a *App) CheckForUpdates() (*models.UpdateInfo, error) {
+	if a.configMgr == nil {
+		return nil, apperr.ErrNotInitialized
+	}
+	if a.configMgr.Get().UpdateFeedURL == "" {
+		return nil, apperr.ErrInvalidArgument.WithMessage("未配置发布源（updateFeedUrl）")
+	}
+	return a.checkUpdate(a.ctx)
+}
+
+// watchUpdates 启动后检查一次更新，未配置发布源时不检查
+func (a *App) watchUpdates(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(updateCheckDelay):
+	}
+	if a.configMgr.Get().UpdateFeedURL == "" {
+		return
+	}
+	if _, err := a.checkUpdate(ctx); err != nil {
+		runtime.LogWarning(ctx, fmt.Sprintf("update check failed: %v", err))
+	}
+}
+
+// checkUpdate 检查更新，有新版本且开启了下载时下载安装包（只读模式下不下载），然后通知前端
+// 安装包的签名用配置中信任的公钥（trustedSigningKeys）校验，未配置时不下载
+func (a *App) checkUpdate(ctx context.Context) (*models.UpdateInfo, error) {
+	cfg := a.configMgr.Get()
+	info, release, err := update.Check(ctx, cfg.UpdateFeedURL)
+	if errors.Is(err, update.ErrInsecureURL) {
+		return nil, apperr.ErrInvalidArgument.WithMessage("发布源地址必须使用 https").Wrap(err)
+	}
+	if err != nil {
+		return nil, apperr.ErrUpdateFailed.Wrap(err)
+	}
+	if !info.Available {
+		return info, nil
+	}
+
+	if cfg.UpdateDownload && !config.ReadOnly() {
+		dir, err := config.UpdatesDir()
+		if err == nil {
+			info.StagedPath, err = update.Stage(ctx, release, dir, cfg.TrustedSigningKeys)
+		}
+		if err != nil {
+			runtime.LogWarning(ctx, fmt.Sprintf("download update %s failed: %v", info.Latest, err))
+		}
+	}
+	runtime.EventsEmit(ctx, "backend:update", info)
+	return info, nil
+}